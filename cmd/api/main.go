@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
@@ -18,15 +19,36 @@ import (
 
 	// Importo los distintos contextos y módulos de la aplicación
 	authCommands "backend-challenge-guinea/internal/contexts/auth/application/commands"
+	authQueries "backend-challenge-guinea/internal/contexts/auth/application/queries"
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
 	authHttp "backend-challenge-guinea/internal/contexts/auth/infrastructure/http"
+	"backend-challenge-guinea/internal/contexts/auth/infrastructure/jwt"
+	authPersistence "backend-challenge-guinea/internal/contexts/auth/infrastructure/persistence"
+	notificationsCommands "backend-challenge-guinea/internal/contexts/notifications/application/commands"
+	notificationsDomain "backend-challenge-guinea/internal/contexts/notifications/domain"
+	notificationsMessaging "backend-challenge-guinea/internal/contexts/notifications/infrastructure/messaging"
+	notificationsPersistence "backend-challenge-guinea/internal/contexts/notifications/infrastructure/persistence"
+	notificationsTemplates "backend-challenge-guinea/internal/contexts/notifications/infrastructure/templates"
+	tenantsCommands "backend-challenge-guinea/internal/contexts/tenants/application/commands"
+	tenantsQueries "backend-challenge-guinea/internal/contexts/tenants/application/queries"
+	tenantsDomain "backend-challenge-guinea/internal/contexts/tenants/domain"
+	tenantsCache "backend-challenge-guinea/internal/contexts/tenants/infrastructure/cache"
+	tenantsExport "backend-challenge-guinea/internal/contexts/tenants/infrastructure/export"
+	tenantsHttp "backend-challenge-guinea/internal/contexts/tenants/infrastructure/http"
+	tenantsPersistence "backend-challenge-guinea/internal/contexts/tenants/infrastructure/persistence"
+	tenantsProvisioning "backend-challenge-guinea/internal/contexts/tenants/infrastructure/provisioning"
 	"backend-challenge-guinea/internal/contexts/users/application/commands"
 	"backend-challenge-guinea/internal/contexts/users/application/queries"
 	usersHttp "backend-challenge-guinea/internal/contexts/users/infrastructure/http"
+	usersLockout "backend-challenge-guinea/internal/contexts/users/infrastructure/lockout"
+	"backend-challenge-guinea/internal/contexts/users/infrastructure/passwords"
 	usersPersistence "backend-challenge-guinea/internal/contexts/users/infrastructure/persistence"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 	"backend-challenge-guinea/internal/shared/infrastructure/bus"
 	"backend-challenge-guinea/internal/shared/infrastructure/config"
 	sharedHttp "backend-challenge-guinea/internal/shared/infrastructure/http"
 	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+	"backend-challenge-guinea/internal/shared/infrastructure/outbox"
 	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
 	"backend-challenge-guinea/internal/shared/logger"
 )
@@ -63,50 +85,278 @@ func main() {
 
 	appLogger.Info("connected to database", nil)
 
-	// Ejecuto las migraciones de la base de datos
-	if err := runMigrations(db, appLogger); err != nil {
-		appLogger.Error("failed to run migrations", map[string]interface{}{
+	// Algoritmo de hash de las passwords nuevas
+	passwordHasher, err := buildPasswordHasher(cfg.Passwords)
+	if err != nil {
+		appLogger.Error("invalid password hashing config", map[string]interface{}{
 			"error": err.Error(),
 		})
-		log.Fatalf("Migrations failed: %v", err)
+		log.Fatalf("Password hashing config failed: %v", err)
 	}
 
-	// Conecto con RabbitMQ para manejar eventos de dominio
-	eventBus, err := bus.NewRabbitMQBus(cfg.RabbitMQ.URL, cfg.RabbitMQ.Exchange, appLogger)
+	// Los eventos de dominio se escriben en el outbox dentro de la misma transaccion,
+	// el relay del consumer se encarga de publicarlos en RabbitMQ
+	txManager := persistence.NewTxManager(db)
+	eventBus := outbox.NewPostgresOutbox(db)
+
+	// Registro de tenants: valida el X-Tenant-Id de todas las rutas con una
+	// cache que refrescan los eventos tenant.*. El router manda a los tenants
+	// con schema dedicado a su propio pool
+	tenantRepository := tenantsPersistence.NewPostgresTenantRepository(db)
+	tenantCache := tenantsCache.NewTenantCache(tenantRepository, cfg.Tenants.CacheTTL)
+	tenantRouter := persistence.NewTenantRouter(cfg.Database, db, tenantCache, cfg.Tenants.DedicatedPoolSize)
+	defer tenantRouter.Close()
+	tenantMiddleware := middleware.TenantMiddleware(tenantCache, tenantRouter)
+	tenantHandler := tenantsCommands.NewTenantCommandHandler(tenantRepository, eventBus, txManager)
+	tenantQueryHandler := tenantsQueries.NewTenantQueryHandler(tenantRepository)
+
+	// Ejecuto las migraciones de la base de datos y de los schemas dedicados,
+	// con el rol de migraciones (DB_MIGRATIONS_USER)
+	migrationsDB, err := persistence.NewPostgresConnection(cfg.Database.ForMigrations())
 	if err != nil {
-		appLogger.Error("failed to connect to rabbitmq", map[string]interface{}{
+		log.Fatalf("Migrations database connection failed: %v", err)
+	}
+	if err := runMigrations(migrationsDB, tenantRepository, tenantRouter, appLogger); err != nil {
+		appLogger.Error("failed to run migrations", map[string]interface{}{
 			"error": err.Error(),
 		})
-		log.Fatalf("RabbitMQ connection failed: %v", err)
+		log.Fatalf("Migrations failed: %v", err)
 	}
-	defer eventBus.Close()
+	migrationsDB.Close()
 
-	appLogger.Info("connected to rabbitmq", nil)
+	// con un superusuario o BYPASSRLS las politicas RLS por tenant no se
+	// aplican; solo se tolera en development
+	bypassed, err := persistence.RowLevelSecurityBypassed(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Row level security check failed: %v", err)
+	}
+	if bypassed {
+		if cfg.Env != "development" {
+			log.Fatalf("Database role %s bypasses row level security, use a role without SUPERUSER or BYPASSRLS", cfg.Database.User)
+		}
+		appLogger.Error("database role bypasses row level security, tenant isolation relies on query filters only", nil)
+	}
 
 	// Inicializo los repositorios del contexto de usuarios
 	userRepository := usersPersistence.NewPostgresUserRepository(db)
 	userReadModel := usersPersistence.NewPostgresUserReadModel(db)
 	idempotencyRepo := usersPersistence.NewPostgresIdempotencyRepository(db)
+	userTokenRepository := usersPersistence.NewPostgresUserTokenRepository(db)
+	passwordPolicyRepository := usersPersistence.NewPostgresPasswordPolicyRepository(db)
+	passwordHistoryRepository := usersPersistence.NewPostgresPasswordHistoryRepository(db)
+	roleRepository := usersPersistence.NewPostgresRoleRepository(db)
+	roleAssignmentRepository := usersPersistence.NewPostgresRoleAssignmentRepository(db)
+	passwordScreener, err := passwords.NewDenylistScreener(cfg.Users.PasswordDenylistPath)
+	if err != nil {
+		appLogger.Error("failed to load password denylist", map[string]interface{}{
+			"error": err.Error(),
+			"path":  cfg.Users.PasswordDenylistPath,
+		})
+		log.Fatalf("Password denylist failed: %v", err)
+	}
+
+	// Los mensajes con token se encolan en la misma transaccion que los genera;
+	// el consumer solo se encarga de entregarlos
+	tokenNotifier := notificationsMessaging.NewTokenNotifier(
+		notificationsCommands.NewEnqueueNotificationCommandHandler(
+			notificationsPersistence.NewPostgresNotificationRepository(db),
+			notificationsPersistence.NewPostgresTemplateRepository(db),
+			notificationsTemplates.NewBuiltinTemplates(),
+			notificationsPersistence.NewPostgresSettingsRepository(db, notificationsDomain.Settings{
+				Locale:      cfg.Notifications.DefaultLocale,
+				FromAddress: cfg.Notifications.FromAddress,
+				FromName:    cfg.Notifications.FromName,
+			}),
+			cfg.Notifications.DefaultLocale,
+			appLogger,
+		),
+		cfg.Notifications.AppURL,
+	)
+
+	// Bloqueo por intentos fallidos: lo comparten el login y el cambio de password
+	lockout := cfg.Auth.Lockout
+	loginGuard := authCommands.NewLoginGuard(
+		authPersistence.NewPostgresLoginAttemptsRepository(db),
+		eventBus,
+		txManager,
+		authDomain.LockoutPolicy{
+			MaxFailures:        lockout.MaxFailures,
+			Window:             lockout.Window,
+			LockoutDuration:    lockout.Duration,
+			MaxLockoutDuration: lockout.MaxDuration,
+		},
+		authDomain.LockoutPolicy{
+			MaxFailures:        lockout.IPMaxFailures,
+			Window:             lockout.Window,
+			LockoutDuration:    lockout.Duration,
+			MaxLockoutDuration: lockout.MaxDuration,
+		},
+	)
 
 	// Handlers de comandos y consultas del contexto de usuarios
 	createUserHandler := commands.NewCreateUserCommandHandler(
 		userRepository,
 		eventBus,
 		idempotencyRepo,
+		txManager,
+		userTokenRepository,
+		tokenNotifier,
+		passwordPolicyRepository,
+		passwordScreener,
+		passwordHasher,
+		cfg.Users.EmailVerificationTTL,
+	)
+	updateUserHandler := commands.NewUpdateUserCommandHandler(userRepository, eventBus, txManager)
+	userLifecycleHandler := commands.NewUserLifecycleCommandHandler(userRepository, roleAssignmentRepository, eventBus, txManager, cfg.Users.PurgeAfter)
+	changeEmailHandler := commands.NewChangeEmailCommandHandler(userRepository, userTokenRepository, eventBus, tokenNotifier, txManager, cfg.Users.EmailChangeTTL)
+	emailVerificationHandler := commands.NewEmailVerificationCommandHandler(
+		userRepository,
+		userTokenRepository,
+		eventBus,
+		tokenNotifier,
+		txManager,
+		cfg.Users.EmailVerificationTTL,
+		cfg.Users.VerificationResendInterval,
+	)
+	passwordHandler := commands.NewPasswordCommandHandler(
+		userRepository,
+		userTokenRepository,
+		passwordPolicyRepository,
+		passwordScreener,
+		passwordHasher,
+		passwordHistoryRepository,
+		usersLockout.NewLoginGuard(loginGuard),
+		eventBus,
+		tokenNotifier,
+		txManager,
+		cfg.Users.PasswordResetTTL,
+		cfg.Users.PasswordResetInterval,
 	)
 	getUserHandler := queries.NewGetUserQueryHandler(userReadModel)
+	listUsersHandler := queries.NewListUsersQueryHandler(userReadModel)
+	searchUsersHandler := queries.NewSearchUsersQueryHandler(userReadModel)
+	roleHandler := commands.NewRoleCommandHandler(userRepository, roleRepository, roleAssignmentRepository, eventBus, txManager)
+	listRolesHandler := queries.NewListRolesQueryHandler(roleRepository)
+	// los roles viven en users pero el chequeo de permisos lo usan todas las rutas
+	permissionChecker := usersHttp.NewRolePermissionChecker(queries.NewCheckPermissionQueryHandler(roleRepository, roleAssignmentRepository))
+
+	// Handlers de autenticación y sesiones
+	sessionRepository := authPersistence.NewPostgresSessionRepository(db)
+	tokenIssuer, jwksHandlers, err := buildTokenIssuer(cfg.Env, cfg.Auth, appLogger)
+	if err != nil {
+		appLogger.Error("failed to configure token issuer", map[string]interface{}{
+			"error": err.Error(),
+		})
+		log.Fatalf("Token issuer setup failed: %v", err)
+	}
+	refreshTokenRepository := authPersistence.NewPostgresRefreshTokenRepository(db)
+	tokenLifetimes := authCommands.TokenLifetimes{
+		AccessToken:  cfg.Auth.AccessTokenTTL,
+		RefreshToken: cfg.Auth.RefreshTokenTTL,
+	}
+	sessionIssuer := authCommands.NewSessionIssuer(sessionRepository, refreshTokenRepository, tokenIssuer, txManager, tokenLifetimes)
+	// feature flags por tenant, los usa tambien el login para exigir MFA
+	featureFlags := middleware.NewFeatureFlags()
+
+	// el secreto TOTP se guarda cifrado; sin clave no hay como leerlo
+	mfaCipher, err := authPersistence.NewSecretCipher(cfg.Auth.MFAEncryptionKey)
+	if err != nil {
+		log.Fatalf("AUTH_MFA_ENCRYPTION_KEY is invalid: %v", err)
+	}
+	mfaEnrollmentRepository := authPersistence.NewPostgresMFAEnrollmentRepository(db, mfaCipher)
+	if err := encryptPlaintextMFASecrets(mfaEnrollmentRepository, tenantRepository, tenantRouter, appLogger); err != nil {
+		log.Fatalf("MFA secrets encryption failed: %v", err)
+	}
+	mfaChallengeRepository := authPersistence.NewPostgresMFAChallengeRepository(db)
+	mfaGate := authCommands.NewMFAGate(mfaEnrollmentRepository, mfaChallengeRepository, featureFlags, cfg.Auth.MFAChallengeTTL)
+	enrollMFAHandler := authCommands.NewEnrollMFACommandHandler(userRepository, mfaEnrollmentRepository, mfaGate, cfg.Auth.MFAIssuer)
+	confirmMFAHandler := authCommands.NewConfirmMFACommandHandler(mfaEnrollmentRepository, txManager)
+	verifyMFAHandler := authCommands.NewVerifyMFACommandHandler(
+		userRepository,
+		mfaEnrollmentRepository,
+		mfaChallengeRepository,
+		mfaGate,
+		sessionIssuer,
+		loginGuard,
+		txManager,
+	)
 
-	// Handler de autenticación
-	authenticateHandler := authCommands.NewAuthenticateCommandHandler(userRepository)
+	authenticateHandler := authCommands.NewAuthenticateCommandHandler(userRepository, passwordHasher, sessionIssuer, loginGuard, mfaGate, featureFlags, appLogger)
+	unlockAccountHandler := authCommands.NewUnlockAccountCommandHandler(loginGuard)
+	refreshTokenHandler := authCommands.NewRefreshTokenCommandHandler(
+		userRepository,
+		sessionRepository,
+		refreshTokenRepository,
+		tokenIssuer,
+		eventBus,
+		txManager,
+		tokenLifetimes,
+	)
+	validateSessionHandler := authQueries.NewValidateSessionQueryHandler(sessionRepository)
+	listSessionsHandler := authQueries.NewListSessionsQueryHandler(sessionRepository)
+	revokeSessionHandler := authCommands.NewRevokeSessionCommandHandler(sessionRepository, refreshTokenRepository, eventBus, txManager)
+	revokeOtherSessionsHandler := authCommands.NewRevokeOtherSessionsCommandHandler(sessionRepository, refreshTokenRepository, eventBus, txManager)
+	authMiddleware := middleware.AuthMiddleware(authHttp.NewSessionTokenResolver(validateSessionHandler))
 
-	// Middlewares de control de features y rate limiting
-	featureFlags := middleware.NewFeatureFlags()
+	// Middleware de rate limiting
 	rateLimiter := middleware.NewRateLimiter(100, time.Minute)
 
+	// Los feature flags y el rate limit propios de cada tenant se aplican en
+	// memoria: se cargan al arrancar y los eventos tenant.* los mantienen
+	tenantSettings := tenantsCache.NewTenantSettings(tenantRepository, featureFlags, rateLimiter)
+	if err := tenantSettings.Load(context.Background()); err != nil {
+		appLogger.Error("failed to load tenant settings", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	tenantEvents, err := subscribeTenantEvents(cfg.RabbitMQ, tenantCache, tenantSettings, tenantRouter, appLogger)
+	if err != nil {
+		// sin RabbitMQ la cache se actualiza igual, solo que al vencer el TTL
+		appLogger.Error("failed to subscribe to tenant events", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else {
+		defer tenantEvents.Close()
+	}
+
+	// Alta y baja de tenants: el alta crea el primer admin con los comandos de
+	// users y la baja exporta y borra los datos del tenant
+	provisionTenantHandler := tenantsCommands.NewProvisionTenantCommandHandler(
+		tenantRepository,
+		tenantRouter,
+		tenantCache,
+		tenantsProvisioning.NewAdminCreator(commands.NewTenantAdminCommandHandler(createUserHandler, roleHandler)),
+		tenantsDomain.Settings{
+			Features:  featureFlags.Defaults(),
+			RateLimit: cfg.Tenants.DefaultRateLimit,
+		},
+		eventBus,
+		txManager,
+	)
+	offboardTenantHandler := tenantsCommands.NewOffboardTenantCommandHandler(
+		tenantRepository,
+		tenantsPersistence.NewPostgresTenantDataRepository(db),
+		tenantsExport.NewFileExportStore(cfg.Tenants.ExportDir),
+		tenantRouter,
+		eventBus,
+		txManager,
+	)
+
 	// Inicializo los controladores HTTP de cada módulo
-	userHandlers := usersHttp.NewUserHandlers(createUserHandler, getUserHandler, featureFlags)
+	userHandlers := usersHttp.NewUserHandlers(createUserHandler, updateUserHandler, userLifecycleHandler, changeEmailHandler, emailVerificationHandler, passwordHandler, getUserHandler, listUsersHandler, searchUsersHandler, featureFlags)
+	roleHandlers := usersHttp.NewRoleHandlers(roleHandler, listRolesHandler)
 	healthHandlers := sharedHttp.NewHealthHandlers(db)
-	authHandlers := authHttp.NewAuthHandlers(authenticateHandler)
+	authHandlers := authHttp.NewAuthHandlers(
+		authenticateHandler,
+		refreshTokenHandler,
+		unlockAccountHandler,
+		revokeSessionHandler,
+		revokeOtherSessionsHandler,
+		listSessionsHandler,
+	)
+	mfaHandlers := authHttp.NewMFAHandlers(enrollMFAHandler, confirmMFAHandler, verifyMFAHandler)
+	tenantHandlers := tenantsHttp.NewTenantHandlers(tenantHandler, provisionTenantHandler, offboardTenantHandler, tenantQueryHandler)
 
 	// Si estamos en producción, desactivo el modo debug de Gin
 	if cfg.Env == "production" {
@@ -116,8 +366,14 @@ func main() {
 	// Creo el router principal y registro las rutas de la API
 	router := gin.Default()
 	healthHandlers.RegisterRoutes(router)
-	userHandlers.RegisterRoutes(router, rateLimiter)
-	authHandlers.RegisterRoutes(router)
+	userHandlers.RegisterRoutes(router, tenantMiddleware, rateLimiter, authMiddleware, permissionChecker)
+	roleHandlers.RegisterRoutes(router, tenantMiddleware, authMiddleware, permissionChecker)
+	authHandlers.RegisterRoutes(router, tenantMiddleware, authMiddleware, permissionChecker)
+	mfaHandlers.RegisterRoutes(router, tenantMiddleware, authMiddleware)
+	tenantHandlers.RegisterRoutes(router, middleware.AdminKeyMiddleware(cfg.Tenants.AdminAPIKey))
+	if jwksHandlers != nil {
+		jwksHandlers.RegisterRoutes(router)
+	}
 
 	// Configuro el servidor HTTP
 	srv := &http.Server{
@@ -160,7 +416,7 @@ func main() {
 }
 
 // Función auxiliar que ejecuta las migraciones de base de datos
-func runMigrations(db *sql.DB, logger logger.Logger) error {
+func runMigrations(db *sql.DB, tenants tenantsDomain.TenantRepository, router *persistence.TenantRouter, logger logger.Logger) error {
 	logger.Info("running database migrations...", nil)
 
 	// Configuro el driver de migraciones para PostgreSQL
@@ -184,6 +440,159 @@ func runMigrations(db *sql.DB, logger logger.Logger) error {
 		return fmt.Errorf("could not run migrations: %w", err)
 	}
 
+	// los tenants con schema dedicado tienen sus propias migraciones
+	all, err := tenants.FindAll(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not list tenants: %w", err)
+	}
+	for _, tenant := range all {
+		if !tenant.HasDedicatedSchema() {
+			continue
+		}
+		// los dados de baja ya no tienen datos; si el offboarding no llego a
+		// borrar el schema se borra aca
+		if tenant.Status() == tenantsDomain.StatusOffboarded {
+			if err := router.DropSchema(context.Background(), tenant.ID()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := router.MigrateSchema(context.Background(), tenant.ID()); err != nil {
+			return err
+		}
+	}
+
 	logger.Info("migrations completed successfully", nil)
 	return nil
 }
+
+// Cifra los secretos TOTP que quedaron en claro de antes de que se cifraran,
+// en public y en cada schema dedicado
+func encryptPlaintextMFASecrets(repo *authPersistence.PostgresMFAEnrollmentRepository, tenants tenantsDomain.TenantRepository, router *persistence.TenantRouter, logger logger.Logger) error {
+	ctx := context.Background()
+	encrypted, err := repo.EncryptPlaintextSecrets(ctx)
+	if err != nil {
+		return err
+	}
+
+	all, err := tenants.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("could not list tenants: %w", err)
+	}
+	for _, tenant := range all {
+		if !tenant.HasDedicatedSchema() || tenant.Status() == tenantsDomain.StatusOffboarded {
+			continue
+		}
+		tenantCtx, err := router.Scope(ctx, tenant.ID(), true)
+		if err != nil {
+			return err
+		}
+		count, err := repo.EncryptPlaintextSecrets(tenantCtx)
+		if err != nil {
+			return err
+		}
+		encrypted += count
+	}
+
+	if encrypted > 0 {
+		logger.Info("encrypted plaintext mfa secrets", map[string]interface{}{
+			"count": encrypted,
+		})
+	}
+	return nil
+}
+
+// Cada instancia de la API escucha los eventos tenant.* en su propia cola para
+// mantener al dia su cache y los settings de los tenants, y cerrar el pool de
+// los que se dan de baja
+func subscribeTenantEvents(cfg config.RabbitMQConfig, cache *tenantsCache.TenantCache, settings *tenantsCache.TenantSettings, router *persistence.TenantRouter, logger logger.Logger) (*bus.RabbitMQBus, error) {
+	eventBus, err := bus.NewRabbitMQBus(cfg.URL, cfg.Exchange, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := eventBus.SubscribeBroadcast("tenant.*", cache.HandleEvent); err != nil {
+		eventBus.Close()
+		return nil, err
+	}
+	if err := eventBus.SubscribeBroadcast("tenant.*", settings.HandleEvent); err != nil {
+		eventBus.Close()
+		return nil, err
+	}
+	releasePool := func(ctx context.Context, event interface{}) error {
+		if payload, ok := event.(map[string]interface{}); ok {
+			if tenantID, _ := payload["aggregate_id"].(string); tenantID != "" {
+				router.Release(tenantID)
+			}
+		}
+		return nil
+	}
+	if err := eventBus.SubscribeBroadcast(tenantsDomain.TenantOffboardedEventType, releasePool); err != nil {
+		eventBus.Close()
+		return nil, err
+	}
+
+	if err := eventBus.Start(context.Background()); err != nil {
+		eventBus.Close()
+		return nil, err
+	}
+
+	return eventBus, nil
+}
+
+// Arma el hasher de passwords con lo que viene de la config. El paralelismo
+// de argon2id es un uint8: un valor fuera de rango se rechaza en vez de truncarse
+func buildPasswordHasher(cfg config.PasswordsConfig) (*vo.PasswordHasher, error) {
+	if cfg.HashAlgorithm == vo.AlgorithmArgon2id && (cfg.Argon2Parallelism < 1 || cfg.Argon2Parallelism > math.MaxUint8) {
+		return nil, fmt.Errorf("ARGON2_PARALLELISM must be between 1 and %d", math.MaxUint8)
+	}
+
+	hashing := vo.DefaultHashingParams()
+	hashing.Algorithm = cfg.HashAlgorithm
+	hashing.BcryptCost = cfg.BcryptCost
+	hashing.Argon2.Memory = cfg.Argon2Memory
+	hashing.Argon2.Iterations = cfg.Argon2Iterations
+	hashing.Argon2.Parallelism = uint8(cfg.Argon2Parallelism)
+	return vo.NewPasswordHasher(hashing)
+}
+
+// Arma el emisor de access tokens segun AUTH_TOKEN_FORMAT. Con jwt también
+// devuelve los handlers del JWKS
+func buildTokenIssuer(env string, cfg config.AuthConfig, logger logger.Logger) (authDomain.TokenIssuer, *authHttp.JWKSHandlers, error) {
+	if cfg.TokenFormat != "jwt" {
+		return authDomain.OpaqueTokenIssuer{}, nil, nil
+	}
+
+	var (
+		keys *jwt.KeySet
+		err  error
+	)
+
+	switch {
+	case cfg.JWTKeysDir != "":
+		keys, err = jwt.LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+	case env != "development":
+		// una clave en memoria invalida los tokens en cada reinicio y cada
+		// instancia firmaria con la suya
+		return nil, nil, fmt.Errorf("AUTH_JWT_KEYS_DIR is required with AUTH_TOKEN_FORMAT=jwt")
+	default:
+		// en development, sin directorio de claves genero una en memoria: los tokens no sobreviven un reinicio
+		logger.Warn("AUTH_JWT_KEYS_DIR not set, using an ephemeral signing key", nil)
+		var key jwt.Key
+		key, err = jwt.GenerateKey(cfg.JWTSigningKeyID, cfg.JWTAlgorithm)
+		if err == nil {
+			keys, err = jwt.NewKeySet(key.ID, key)
+		}
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not load jwt keys: %w", err)
+	}
+
+	logger.Info("issuing jwt access tokens", map[string]interface{}{
+		"signing_key_id": keys.SigningKey().ID,
+		"algorithm":      keys.SigningKey().Algorithm,
+		"keys":           len(keys.Keys()),
+	})
+
+	return jwt.NewIssuer(keys, cfg.JWTIssuer), authHttp.NewJWKSHandlers(keys), nil
+}
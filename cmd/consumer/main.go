@@ -3,16 +3,32 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	authCommands "backend-challenge-guinea/internal/contexts/auth/application/commands"
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+	authPersistence "backend-challenge-guinea/internal/contexts/auth/infrastructure/persistence"
+	notificationsCommands "backend-challenge-guinea/internal/contexts/notifications/application/commands"
+	notificationsDomain "backend-challenge-guinea/internal/contexts/notifications/domain"
+	notificationsMessaging "backend-challenge-guinea/internal/contexts/notifications/infrastructure/messaging"
+	notificationsPersistence "backend-challenge-guinea/internal/contexts/notifications/infrastructure/persistence"
+	notificationsSender "backend-challenge-guinea/internal/contexts/notifications/infrastructure/sender"
+	notificationsTemplates "backend-challenge-guinea/internal/contexts/notifications/infrastructure/templates"
+	tenantsDomain "backend-challenge-guinea/internal/contexts/tenants/domain"
+	tenantsCache "backend-challenge-guinea/internal/contexts/tenants/infrastructure/cache"
+	tenantsPersistence "backend-challenge-guinea/internal/contexts/tenants/infrastructure/persistence"
+	usersCommands "backend-challenge-guinea/internal/contexts/users/application/commands"
 	"backend-challenge-guinea/internal/contexts/users/application/projections"
 	"backend-challenge-guinea/internal/contexts/users/domain"
 	usersPersistence "backend-challenge-guinea/internal/contexts/users/infrastructure/persistence"
 	"backend-challenge-guinea/internal/shared/infrastructure/bus"
 	"backend-challenge-guinea/internal/shared/infrastructure/config"
+	"backend-challenge-guinea/internal/shared/infrastructure/outbox"
 	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
 	"backend-challenge-guinea/internal/shared/logger"
 )
@@ -46,6 +62,19 @@ func main() {
 
 	appLogger.Info("connected to database", nil)
 
+	// igual que la API: sin RLS los handlers solo quedan aislados por los
+	// filtros de las queries
+	bypassed, err := persistence.RowLevelSecurityBypassed(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Row level security check failed: %v", err)
+	}
+	if bypassed {
+		if cfg.Env != "development" {
+			log.Fatalf("Database role %s bypasses row level security, use a role without SUPERUSER or BYPASSRLS", cfg.Database.User)
+		}
+		appLogger.Error("database role bypasses row level security, tenant isolation relies on query filters only", nil)
+	}
+
 	// 4. Conectar a RabbitMQ
 	eventBus, err := bus.NewRabbitMQBus(cfg.RabbitMQ.URL, cfg.RabbitMQ.Exchange, appLogger)
 	if err != nil {
@@ -58,41 +87,190 @@ func main() {
 
 	appLogger.Info("connected to rabbitmq", nil)
 
+	// Reintentos con backoff exponencial, después del último intento el mensaje va a la DLQ
+	eventBus.SetDefaultRetryPolicy(bus.RetryPolicy{
+		MaxAttempts:    cfg.RabbitMQ.Retry.MaxAttempts,
+		InitialBackoff: cfg.RabbitMQ.Retry.InitialBackoff,
+		MaxBackoff:     cfg.RabbitMQ.Retry.MaxBackoff,
+		Multiplier:     2,
+	})
+
 	// 5. Inicializar repositorios
 	userReadModelRepo := usersPersistence.NewPostgresUserReadModel(db)
 
-	// 6. Inicializar projector
+	// los handlers corren contra el schema de su tenant. Aca la cache de
+	// tenants se refresca solo por TTL: el schema de un tenant no cambia
+	tenantRepository := tenantsPersistence.NewPostgresTenantRepository(db)
+	tenantRouter := persistence.NewTenantRouter(
+		cfg.Database,
+		db,
+		tenantsCache.NewTenantCache(tenantRepository, cfg.Tenants.CacheTTL),
+		cfg.Tenants.DedicatedPoolSize,
+	)
+	defer tenantRouter.Close()
+
+	// 6. Inicializar projector y handlers que reaccionan a eventos. Lo que
+	// publican va al outbox, igual que en la API
 	userProjector := projections.NewUserProjector(userReadModelRepo, appLogger)
+	txManager := persistence.NewTxManager(db)
+	eventOutbox := outbox.NewPostgresOutbox(db)
+	revokeUserSessionsHandler := authCommands.NewRevokeUserSessionsCommandHandler(
+		authPersistence.NewPostgresSessionRepository(db),
+		authPersistence.NewPostgresRefreshTokenRepository(db),
+		eventOutbox,
+		txManager,
+	)
+	purgeDeletedUsersHandler := usersCommands.NewPurgeDeletedUsersCommandHandler(
+		usersPersistence.NewPostgresUserRepository(db),
+		eventOutbox,
+		txManager,
+		cfg.Users.PurgeAfter,
+		cfg.Users.PurgeBatchSize,
+	)
+
+	// notificaciones: los eventos dejan el mensaje renderizado en la tabla y
+	// runDelivery lo manda con reintentos
+	notificationRepo := notificationsPersistence.NewPostgresNotificationRepository(db)
+	notificationSettings := notificationsPersistence.NewPostgresSettingsRepository(db, notificationsDomain.Settings{
+		Locale:      cfg.Notifications.DefaultLocale,
+		FromAddress: cfg.Notifications.FromAddress,
+		FromName:    cfg.Notifications.FromName,
+	})
+	enqueueNotificationHandler := notificationsCommands.NewEnqueueNotificationCommandHandler(
+		notificationRepo,
+		notificationsPersistence.NewPostgresTemplateRepository(db),
+		notificationsTemplates.NewBuiltinTemplates(),
+		notificationSettings,
+		cfg.Notifications.DefaultLocale,
+		appLogger,
+	)
+	notificationSender, err := newNotificationSender(cfg.Notifications, appLogger)
+	if err != nil {
+		log.Fatalf("Notifications configuration failed: %v", err)
+	}
+	deliverNotificationsHandler := notificationsCommands.NewDeliverNotificationsCommandHandler(
+		notificationRepo,
+		notificationSettings,
+		notificationSender,
+		txManager,
+		bus.RetryPolicy{
+			MaxAttempts:    cfg.Notifications.MaxAttempts,
+			InitialBackoff: cfg.Notifications.InitialBackoff,
+			MaxBackoff:     cfg.Notifications.MaxBackoff,
+			Multiplier:     2,
+		},
+		cfg.Notifications.BatchSize,
+		appLogger,
+	)
+
+	// 7. Suscribir el projector a los eventos de usuarios. Los handlers corren
+	// con el tenant del evento en el context para que las politicas RLS apliquen
+	// y las queries vayan a su schema
+	subscriber := tenantScopedSubscriber{eventBus: eventBus, router: tenantRouter}
+	err = subscriber.Subscribe(domain.UserCreatedEventType, func(ctx context.Context, event interface{}) error {
+		var userCreatedEvent domain.UserCreatedEvent
+		if err := decodeEvent(event, &userCreatedEvent, appLogger); err != nil {
+			return err
+		}
+
+		// Proyectar usando el evento tipado
+		return userProjector.ProjectUserCreated(ctx, userCreatedEvent)
+	})
+	if err == nil {
+		err = subscriber.Subscribe(domain.UserUpdatedEventType, func(ctx context.Context, event interface{}) error {
+			var userUpdatedEvent domain.UserUpdatedEvent
+			if err := decodeEvent(event, &userUpdatedEvent, appLogger); err != nil {
+				return err
+			}
+
+			return userProjector.ProjectUserUpdated(ctx, userUpdatedEvent)
+		})
+	}
+	if err == nil {
+		err = subscriber.Subscribe(domain.UserEmailChangedEventType, func(ctx context.Context, event interface{}) error {
+			var emailChangedEvent domain.UserEmailChangedEvent
+			if err := decodeEvent(event, &emailChangedEvent, appLogger); err != nil {
+				return err
+			}
 
-	// 7. Suscribir el projector al evento UserCreated
-	err = eventBus.Subscribe(domain.UserCreatedEventType, func(ctx context.Context, event interface{}) error {
-		// Convertir el evento de map a UserCreatedEvent
-		eventMap, ok := event.(map[string]interface{})
-		if !ok {
-			appLogger.Error("invalid event format", nil)
-			return nil
+			return userProjector.ProjectUserEmailChanged(ctx, emailChangedEvent)
+		})
+	}
+	if err == nil {
+		err = subscriber.Subscribe(domain.UserEmailVerifiedEventType, func(ctx context.Context, event interface{}) error {
+			var emailVerifiedEvent domain.UserEmailVerifiedEvent
+			if err := decodeEvent(event, &emailVerifiedEvent, appLogger); err != nil {
+				return err
+			}
+
+			return userProjector.ProjectUserEmailVerified(ctx, emailVerifiedEvent)
+		})
+	}
+
+	// cambios de estado del ciclo de vida, todos con el mismo payload
+	for _, eventType := range []string{
+		domain.UserDeactivatedEventType,
+		domain.UserReactivatedEventType,
+		domain.UserDeletedEventType,
+		domain.UserRestoredEventType,
+		domain.UserPurgedEventType,
+	} {
+		if err != nil {
+			break
 		}
+		err = subscriber.Subscribe(eventType, func(ctx context.Context, event interface{}) error {
+			var statusEvent domain.UserStatusChangedEvent
+			if err := decodeEvent(event, &statusEvent, appLogger); err != nil {
+				return err
+			}
+
+			return userProjector.ProjectUserStatusChanged(ctx, statusEvent)
+		})
+	}
 
-		// Serializar y deserializar para obtener el struct correcto
-		eventBytes, err := json.Marshal(eventMap)
+	// un usuario suspendido o borrado pierde todas sus sesiones
+	for _, eventType := range []string{domain.UserDeactivatedEventType, domain.UserDeletedEventType} {
 		if err != nil {
-			appLogger.Error("failed to marshal event", map[string]interface{}{
-				"error": err.Error(),
+			break
+		}
+		err = subscriber.Subscribe(eventType, func(ctx context.Context, event interface{}) error {
+			var statusEvent domain.UserStatusChangedEvent
+			if err := decodeEvent(event, &statusEvent, appLogger); err != nil {
+				return err
+			}
+
+			_, err := revokeUserSessionsHandler.Handle(ctx, authCommands.RevokeUserSessionsCommand{
+				UserID:        statusEvent.UserID,
+				TenantID:      statusEvent.TenantID(),
+				CorrelationID: statusEvent.CorrelationID(),
+				Reason:        authDomain.RevokeReasonUserDisabled,
 			})
 			return err
-		}
+		})
+	}
 
-		var userCreatedEvent domain.UserCreatedEvent
-		if err := json.Unmarshal(eventBytes, &userCreatedEvent); err != nil {
-			appLogger.Error("failed to unmarshal to UserCreatedEvent", map[string]interface{}{
-				"error": err.Error(),
+	// cambiar o resetear la password cierra las demas sesiones
+	if err == nil {
+		err = subscriber.Subscribe(domain.UserPasswordChangedEventType, func(ctx context.Context, event interface{}) error {
+			var passwordEvent domain.UserPasswordChangedEvent
+			if err := decodeEvent(event, &passwordEvent, appLogger); err != nil {
+				return err
+			}
+
+			_, err := revokeUserSessionsHandler.Handle(ctx, authCommands.RevokeUserSessionsCommand{
+				UserID:        passwordEvent.UserID,
+				TenantID:      passwordEvent.TenantID(),
+				KeepSessionID: passwordEvent.KeepSessionID,
+				CorrelationID: passwordEvent.CorrelationID(),
+				Reason:        authDomain.RevokeReasonPasswordChanged,
 			})
 			return err
-		}
+		})
+	}
 
-		// Proyectar usando el evento tipado
-		return userProjector.ProjectUserCreated(ctx, userCreatedEvent)
-	})
+	if err == nil {
+		err = notificationsMessaging.NewEventSubscriber(enqueueNotificationHandler).Register(subscriber)
+	}
 
 	if err != nil {
 		appLogger.Error("failed to subscribe to events", map[string]interface{}{
@@ -102,7 +280,9 @@ func main() {
 	}
 
 	// 8. Iniciar el consumo de mensajes
-	ctx := context.Background()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	if err := eventBus.Start(ctx); err != nil {
 		appLogger.Error("failed to start event bus", map[string]interface{}{
 			"error": err.Error(),
@@ -112,14 +292,43 @@ func main() {
 
 	appLogger.Info("consumer started, waiting for events...", nil)
 
-	// 9. Esperar señal de terminación
+	// 9. Relay del outbox: publica en RabbitMQ los eventos que la API dejo en la tabla outbox
+	outboxRelay := outbox.NewRelay(
+		eventOutbox,
+		txManager,
+		eventBus,
+		appLogger,
+		cfg.Outbox.PollInterval,
+		cfg.Outbox.BatchSize,
+		cfg.Outbox.MaxAttempts,
+	)
+	go outboxRelay.Run(ctx)
+
+	appLogger.Info("outbox relay started", map[string]interface{}{
+		"poll_interval": cfg.Outbox.PollInterval.String(),
+		"batch_size":    cfg.Outbox.BatchSize,
+	})
+
+	// 10. Purga de usuarios borrados cuya ventana de restauracion vencio
+	go runPurge(ctx, purgeDeletedUsersHandler, tenantRepository, tenantRouter, cfg.Users.PurgeInterval, appLogger)
+
+	// 11. Envio de notificaciones pendientes
+	go runDelivery(ctx, deliverNotificationsHandler, cfg.Notifications.Interval, appLogger)
+
+	appLogger.Info("notification delivery started", map[string]interface{}{
+		"sender":   cfg.Notifications.Sender,
+		"interval": cfg.Notifications.Interval.String(),
+	})
+
+	// 12. Esperar señal de terminación
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	appLogger.Info("shutting down consumer...", nil)
+	cancel()
 
-	// 10. Cerrar conexiones
+	// 13. Cerrar conexiones
 	if err := eventBus.Close(); err != nil {
 		appLogger.Error("error closing event bus", map[string]interface{}{
 			"error": err.Error(),
@@ -127,4 +336,155 @@ func main() {
 	}
 
 	appLogger.Info("consumer stopped", nil)
-}
\ No newline at end of file
+}
+
+// decodeEvent convierte el map que entrega el bus al struct tipado del evento
+func decodeEvent(event interface{}, target interface{}, appLogger logger.Logger) error {
+	eventMap, ok := event.(map[string]interface{})
+	if !ok {
+		appLogger.Error("invalid event format", nil)
+		return fmt.Errorf("invalid event format %T", event)
+	}
+
+	// Serializar y deserializar para obtener el struct correcto
+	eventBytes, err := json.Marshal(eventMap)
+	if err != nil {
+		appLogger.Error("failed to marshal event", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	if err := json.Unmarshal(eventBytes, target); err != nil {
+		appLogger.Error("failed to unmarshal event", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// tenantScopedSubscriber envuelve los handlers para que corran con el
+// tenant_id del evento (y la conexion a su schema) en el context
+type tenantScopedSubscriber struct {
+	eventBus *bus.RabbitMQBus
+	router   *persistence.TenantRouter
+}
+
+func (s tenantScopedSubscriber) Subscribe(eventType string, handler bus.EventHandler) error {
+	return s.eventBus.Subscribe(eventType, func(ctx context.Context, event interface{}) error {
+		if eventMap, ok := event.(map[string]interface{}); ok {
+			if tenantID, _ := eventMap["tenant_id"].(string); tenantID != "" {
+				routed, err := s.router.Route(ctx, tenantID)
+				if err != nil {
+					return err
+				}
+				ctx = routed
+			}
+		}
+		return handler(ctx, event)
+	})
+}
+
+func runPurge(ctx context.Context, handler *usersCommands.PurgeDeletedUsersCommandHandler, tenants tenantsDomain.TenantRepository, router *persistence.TenantRouter, interval time.Duration, appLogger logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scopes, err := purgeScopes(ctx, tenants, router)
+			if err != nil {
+				appLogger.Error("failed to purge deleted users", map[string]interface{}{
+					"error": err.Error(),
+				})
+				continue
+			}
+
+			for _, scope := range scopes {
+				purged, err := handler.Handle(scope)
+				if err != nil {
+					appLogger.Error("failed to purge deleted users", map[string]interface{}{
+						"error":     err.Error(),
+						"tenant_id": persistence.TenantFromContext(scope),
+					})
+					continue
+				}
+				if purged > 0 {
+					appLogger.Info("deleted users purged", map[string]interface{}{
+						"count":     purged,
+						"tenant_id": persistence.TenantFromContext(scope),
+					})
+				}
+			}
+		}
+	}
+}
+
+// purgeScopes arma un context por cada lugar donde hay usuarios: las tablas
+// compartidas (todos sus tenants de una) y el schema de cada tenant dedicado
+func purgeScopes(ctx context.Context, tenants tenantsDomain.TenantRepository, router *persistence.TenantRouter) ([]context.Context, error) {
+	ctx = persistence.WithAllTenants(ctx)
+	scopes := []context.Context{ctx}
+
+	all, err := tenants.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tenant := range all {
+		if !tenant.HasDedicatedSchema() {
+			continue
+		}
+		scope, err := router.Route(ctx, tenant.ID())
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+func runDelivery(ctx context.Context, handler *notificationsCommands.DeliverNotificationsCommandHandler, interval time.Duration, appLogger logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sent, err := handler.Handle(ctx)
+			if err != nil {
+				appLogger.Error("failed to deliver notifications", map[string]interface{}{
+					"error": err.Error(),
+				})
+				continue
+			}
+			if sent > 0 {
+				appLogger.Info("notifications delivered", map[string]interface{}{
+					"count": sent,
+				})
+			}
+		}
+	}
+}
+
+func newNotificationSender(cfg config.NotificationsConfig, appLogger logger.Logger) (notificationsDomain.Sender, error) {
+	switch cfg.Sender {
+	case "smtp":
+		return notificationsSender.NewSMTPSender(notificationsSender.SMTPConfig{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+		}), nil
+	case "log":
+		return notificationsSender.NewFileSender(cfg.OutputDir, appLogger), nil
+	default:
+		return nil, fmt.Errorf("unknown notifications sender %q, use log or smtp", cfg.Sender)
+	}
+}
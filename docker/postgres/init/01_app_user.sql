@@ -0,0 +1,5 @@
+-- corre una sola vez, al crear el volumen. POSTGRES_USER (backend_admin) es el
+-- superusuario que corre las migraciones; la app se conecta con backend_user,
+-- que no saltea RLS. Los permisos de backend_app los da la migracion 000025
+CREATE ROLE backend_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+CREATE ROLE backend_user LOGIN NOSUPERUSER NOBYPASSRLS NOCREATEDB NOCREATEROLE PASSWORD 'backend_pass' IN ROLE backend_app;
@@ -1,50 +1,137 @@
 package commands
 
 import (
-	"time"
 	"context"
+	"errors"
+
 	"backend-challenge-guinea/internal/contexts/auth/domain"
 	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 )
 
 type AuthenticateCommand struct {
-	Email    string
-	Password string
-	TenantID string
+	Email         string
+	Password      string
+	TenantID      string
+	UserAgent     string
+	IPAddress     string
+	CorrelationID string
 }
 
+// AuthenticateResponse trae la sesion, o solo el challenge si falta el segundo factor
 type AuthenticateResponse struct {
-	Token     string `json:"token"`
-	UserID    string `json:"user_id"`
-	ExpiresAt string `json:"expires_at"`
+	Token                 string   `json:"token,omitempty"`
+	UserID                string   `json:"user_id"`
+	ExpiresAt             string   `json:"expires_at,omitempty"`
+	RefreshToken          string   `json:"refresh_token,omitempty"`
+	RefreshTokenExpiresAt string   `json:"refresh_token_expires_at,omitempty"`
+	MFARequired           bool     `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool     `json:"mfa_enrollment_required,omitempty"`
+	ChallengeToken        string   `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt    string   `json:"challenge_expires_at,omitempty"`
+	RecoveryCodes         []string `json:"recovery_codes,omitempty"`
 }
 
 type AuthenticateCommandHandler struct {
 	userRepository userDomain.UserRepository 
+	hasher         *vo.PasswordHasher
+	sessionIssuer  *SessionIssuer
+	loginGuard     *LoginGuard
+	mfaGate        *MFAGate
+	featureFlags   FeatureFlags
+	log            Logger
 }
 
-func NewAuthenticateCommandHandler(userRepo userDomain.UserRepository) *AuthenticateCommandHandler {
+func NewAuthenticateCommandHandler(userRepo userDomain.UserRepository, hasher *vo.PasswordHasher, sessionIssuer *SessionIssuer, loginGuard *LoginGuard, mfaGate *MFAGate, featureFlags FeatureFlags, log Logger) *AuthenticateCommandHandler {
 	return &AuthenticateCommandHandler{
 		userRepository: userRepo,
+		hasher:         hasher,
+		sessionIssuer:  sessionIssuer,
+		loginGuard:     loginGuard,
+		mfaGate:        mfaGate,
+		featureFlags:   featureFlags,
+		log:            log,
 	}
 }
 
 func (h *AuthenticateCommandHandler) Handle(ctx context.Context, cmd AuthenticateCommand) (*AuthenticateResponse, error) {
 
+	if err := h.loginGuard.Check(ctx, cmd.TenantID, cmd.Email, cmd.IPAddress); err != nil {
+		return nil, err
+	}
+
 	user, err := h.userRepository.FindByEmail(ctx, cmd.Email, cmd.TenantID)
 	if err != nil {
+		if errors.Is(err, userDomain.ErrUserNotFound) {
+			return nil, h.failed(ctx, cmd, "")
+		}
 		return nil, domain.ErrInvalidCredentials 
 	}
 
 	if !user.Password().Compare(cmd.Password) {
-		return nil, domain.ErrInvalidCredentials
+		return nil, h.failed(ctx, cmd, user.ID())
+	}
+
+	// solo se informa despues de validar la password, para no filtrar el estado de la cuenta
+	if !user.IsActive() {
+		return nil, domain.ErrAccountDisabled
+	}
+	if !user.IsEmailVerified() && h.featureFlags.IsEnabled(cmd.TenantID, featureEmailVerificationRequired) {
+		return nil, domain.ErrEmailNotVerified
+	}
+
+	if err := h.loginGuard.RecordSuccess(ctx, cmd.TenantID, cmd.Email); err != nil {
+		return nil, err
 	}
 
-	session := domain.NewSession(user.ID(), cmd.TenantID, 24*time.Hour)
+	// el re-hash es opcional: si falla (la base, o otro login que ya lo
+	// cambio) el login sigue con el hash que ya se valido
+	if err := h.upgradePasswordHash(ctx, user, cmd.Password); err != nil {
+		h.log.Error("failed to upgrade password hash", map[string]interface{}{
+			"user_id":   user.ID(),
+			"tenant_id": cmd.TenantID,
+			"error":     err.Error(),
+		})
+	}
 
-	return &AuthenticateResponse{
-		Token:     session.Token(),
-		UserID:    session.UserID(),
-		ExpiresAt: session.ExpiresAt().Format(time.RFC3339),
-	}, nil
-}
\ No newline at end of file
+	challenge, err := h.mfaGate.Begin(ctx, user.ID(), cmd.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return challenge, nil
+	}
+
+	return h.sessionIssuer.Start(ctx, user.ID(), cmd.TenantID, domain.ClientInfo{
+		UserAgent: cmd.UserAgent,
+		IPAddress: cmd.IPAddress,
+	})
+}
+
+
+// upgradePasswordHash aprovecha que tenemos la password en claro para pasar
+// hashes viejos (otro algoritmo o parametros) a los configurados
+func (h *AuthenticateCommandHandler) upgradePasswordHash(ctx context.Context, user *userDomain.User, plainPassword string) error {
+	if !h.hasher.NeedsRehash(user.Password()) {
+		return nil
+	}
+
+	password, err := h.hasher.Hash(plainPassword)
+	if err != nil {
+		return err
+	}
+
+	return h.userRepository.UpdatePasswordHash(ctx, user.ID(), user.TenantID(), user.Password().Hash(), password.Hash())
+}
+
+// failed registra el intento fallido y devuelve el error para el cliente
+func (h *AuthenticateCommandHandler) failed(ctx context.Context, cmd AuthenticateCommand, userID string) error {
+	if err := h.loginGuard.RecordFailure(ctx, cmd.TenantID, cmd.Email, cmd.IPAddress, userID, cmd.CorrelationID); err != nil {
+		return err
+	}
+	return domain.ErrInvalidCredentials
+}
+
+type Logger interface {
+	Error(msg string, fields map[string]interface{})
+}
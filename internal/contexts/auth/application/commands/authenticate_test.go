@@ -2,10 +2,13 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 
 	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
 	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
@@ -42,16 +45,145 @@ func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email, tenantID
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id, tenantID, currentHash, newHash string) error {
+	args := m.Called(ctx, id, tenantID, currentHash, newHash)
+	return args.Error(0)
+}
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, session *authDomain.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*authDomain.Session, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindByID(ctx context.Context, id, tenantID string) (*authDomain.Session, error) {
+	args := m.Called(ctx, id, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindActiveByUser(ctx context.Context, userID, tenantID string) ([]*authDomain.Session, error) {
+	args := m.Called(ctx, userID, tenantID)
+	return args.Get(0).([]*authDomain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindUnrevokedByUser(ctx context.Context, userID, tenantID string) ([]*authDomain.Session, error) {
+	args := m.Called(ctx, userID, tenantID)
+	return args.Get(0).([]*authDomain.Session), args.Error(1)
+}
+
+type MockRefreshTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockRefreshTokenRepository) Save(ctx context.Context, token *authDomain.RefreshToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*authDomain.RefreshToken, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.RefreshToken), args.Error(1)
+}
+
+func (m *MockRefreshTokenRepository) RevokeBySession(ctx context.Context, sessionID, tenantID string) error {
+	args := m.Called(ctx, sessionID, tenantID)
+	return args.Error(0)
+}
+
+type MockLoginAttemptsRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptsRepository) Find(ctx context.Context, tenantID, subject string) (*authDomain.LoginAttempts, error) {
+	args := m.Called(ctx, tenantID, subject)
+	if fn, ok := args.Get(0).(func(ctx context.Context, tenantID, subject string) *authDomain.LoginAttempts); ok {
+		return fn(ctx, tenantID, subject), args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.LoginAttempts), args.Error(1)
+}
+
+func (m *MockLoginAttemptsRepository) Save(ctx context.Context, attempts *authDomain.LoginAttempts) error {
+	args := m.Called(ctx, attempts)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsRepository) Delete(ctx context.Context, tenantID, subject string) error {
+	args := m.Called(ctx, tenantID, subject)
+	return args.Error(0)
+}
+
+var testLockoutPolicy = authDomain.LockoutPolicy{
+	MaxFailures:     3,
+	Window:          15 * time.Minute,
+	LockoutDuration: 5 * time.Minute,
+}
+
+// repositorio de intentos sin fallos previos que acepta cualquier escritura
+func newCleanAttemptsRepository() *MockLoginAttemptsRepository {
+	repo := new(MockLoginAttemptsRepository)
+	repo.On("Find", mock.Anything, mock.Anything, mock.Anything).Return(func(ctx context.Context, tenantID, subject string) *authDomain.LoginAttempts {
+		return authDomain.NewLoginAttempts(tenantID, subject)
+	}, nil)
+	repo.On("Save", mock.Anything, mock.Anything).Return(nil)
+	repo.On("Delete", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return repo
+}
+
+func newTestLoginGuard(attempts *MockLoginAttemptsRepository, eventBus *MockEventBus) *LoginGuard {
+	return NewLoginGuard(attempts, eventBus, &MockTransactionManager{}, testLockoutPolicy, testLockoutPolicy)
+}
+
+func newTestSessionIssuer(sessions *MockSessionRepository, refreshTokens *MockRefreshTokenRepository) *SessionIssuer {
+	return NewSessionIssuer(sessions, refreshTokens, authDomain.OpaqueTokenIssuer{}, &MockTransactionManager{}, DefaultTokenLifetimes())
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Error(msg string, fields map[string]interface{}) {}
+
+// recordingLogger guarda los mensajes de error
+type recordingLogger struct {
+	errors []string
+}
+
+func (l *recordingLogger) Error(msg string, fields map[string]interface{}) {
+	l.errors = append(l.errors, msg)
+}
+
 func TestAuthenticateCommandHandler_Success(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
-	handler := NewAuthenticateCommandHandler(mockRepo)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, mockRefreshTokens), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
 
 	email, _ := vo.NewEmail("test@example.com")
 	password, _ := vo.NewPassword("SecurePass123!")
 	user, _ := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
 
 	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
 
 	cmd := AuthenticateCommand{
 		Email:    "test@example.com",
@@ -65,13 +197,83 @@ func TestAuthenticateCommandHandler_Success(t *testing.T) {
 	assert.NotNil(t, response)
 	assert.NotEmpty(t, response.Token)
 	assert.Equal(t, user.ID(), response.UserID)
+	assert.NotEmpty(t, response.RefreshToken)
+	mockRepo.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+	mockRefreshTokens.AssertExpectations(t)
+
+	// lo que se persiste es el hash, nunca el token en claro
+	saved := mockSessions.Calls[0].Arguments.Get(1).(*authDomain.Session)
+	assert.Equal(t, authDomain.HashToken(response.Token), saved.TokenHash())
+}
+
+// un usuario con hash bcrypt pasa a argon2id al loguearse, sin tocar el resto del usuario
+func TestAuthenticateCommandHandler_RehashesOutdatedPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, mockRefreshTokens), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
+
+	legacyHash, _ := bcrypt.GenerateFromPassword([]byte("SecurePass123!"), vo.BcryptCost)
+	email, _ := vo.NewEmail("test@example.com")
+	user, _ := userDomain.NewUser("Test User", email, vo.FromHash(string(legacyHash)), "tenant-1", nil)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockRepo.On("UpdatePasswordHash", ctx, user.ID(), "tenant-1", string(legacyHash), mock.AnythingOfType("string")).Return(nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	_, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+
+	upgraded := vo.FromHash(mockRepo.Calls[1].Arguments.String(4))
+	assert.Equal(t, vo.AlgorithmArgon2id, upgraded.Algorithm())
+	assert.True(t, upgraded.Compare("SecurePass123!"))
+}
+
+// si el re-hash falla (la base, o otro login que ya lo cambio) el login sigue
+func TestAuthenticateCommandHandler_RehashFailureDoesNotFailLogin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	log := &recordingLogger{}
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, mockRefreshTokens), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, log)
+
+	legacyHash, _ := bcrypt.GenerateFromPassword([]byte("SecurePass123!"), vo.BcryptCost)
+	email, _ := vo.NewEmail("test@example.com")
+	user, _ := userDomain.NewUser("Test User", email, vo.FromHash(string(legacyHash)), "tenant-1", nil)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockRepo.On("UpdatePasswordHash", ctx, user.ID(), "tenant-1", string(legacyHash), mock.AnythingOfType("string")).Return(errors.New("connection reset"))
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	response, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.Equal(t, []string{"failed to upgrade password hash"}, log.errors)
+	mockSessions.AssertExpectations(t)
 }
 
 func TestAuthenticateCommandHandler_InvalidCredentials(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
-	handler := NewAuthenticateCommandHandler(mockRepo)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, mockRefreshTokens), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
 
 	email, _ := vo.NewEmail("test@example.com")
 	password, _ := vo.NewPassword("SecurePass123!")
@@ -91,12 +293,15 @@ func TestAuthenticateCommandHandler_InvalidCredentials(t *testing.T) {
 	assert.Equal(t, authDomain.ErrInvalidCredentials, err)
 	assert.Nil(t, response)
 	mockRepo.AssertExpectations(t)
+	mockSessions.AssertNotCalled(t, "Save")
 }
 
 func TestAuthenticateCommandHandler_UserNotFound(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockUserRepository)
-	handler := NewAuthenticateCommandHandler(mockRepo)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, mockRefreshTokens), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
 
 	mockRepo.On("FindByEmail", ctx, "nonexistent@example.com", "tenant-1").Return(nil, userDomain.ErrUserNotFound)
 
@@ -112,4 +317,98 @@ func TestAuthenticateCommandHandler_UserNotFound(t *testing.T) {
 	assert.Equal(t, authDomain.ErrInvalidCredentials, err)
 	assert.Nil(t, response)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+// al llegar al umbral la cuenta queda bloqueada, se publica user.locked y
+// el siguiente intento ni siquiera busca al usuario
+func TestAuthenticateCommandHandler_LocksAfterMaxFailures(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	mockEventBus := new(MockEventBus)
+	mockAttempts := new(MockLoginAttemptsRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, new(MockRefreshTokenRepository)), newTestLoginGuard(mockAttempts, mockEventBus), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
+
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
+
+	emailAttempts := authDomain.ReconstituteLoginAttempts("tenant-1", authDomain.EmailSubject("test@example.com"), 2, time.Now().UTC(), nil)
+	ipAttempts := authDomain.NewLoginAttempts("tenant-1", authDomain.IPSubject("10.0.0.1"))
+
+	mockAttempts.On("Find", ctx, "tenant-1", emailAttempts.Subject()).Return(emailAttempts, nil)
+	mockAttempts.On("Find", ctx, "tenant-1", ipAttempts.Subject()).Return(ipAttempts, nil)
+	mockAttempts.On("Save", ctx, mock.AnythingOfType("*domain.LoginAttempts")).Return(nil)
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserLockedEvent")).Return(nil)
+
+	cmd := AuthenticateCommand{
+		Email:     "test@example.com",
+		Password:  "WrongPassword123!",
+		TenantID:  "tenant-1",
+		IPAddress: "10.0.0.1",
+	}
+
+	_, err := handler.Handle(ctx, cmd)
+	assert.Equal(t, authDomain.ErrInvalidCredentials, err)
+	mockEventBus.AssertNumberOfCalls(t, "Publish", 1)
+
+	cmd.Password = "SecurePass123!"
+	response, err := handler.Handle(ctx, cmd)
+
+	assert.Equal(t, authDomain.ErrAccountLocked, err)
+	assert.Nil(t, response)
+	mockRepo.AssertNumberOfCalls(t, "FindByEmail", 1)
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+// un usuario suspendido no puede loguearse aunque la password sea correcta
+func TestAuthenticateCommandHandler_SuspendedUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, new(MockRefreshTokenRepository)), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), stubFeatureFlags{}, nopLogger{})
+
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
+	_ = user.Deactivate()
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+
+	response, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	assert.Equal(t, authDomain.ErrAccountDisabled, err)
+	assert.Nil(t, response)
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+// con el flag activo en el tenant, un email sin verificar no puede loguearse
+func TestAuthenticateCommandHandler_EmailNotVerified(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	flags := stubFeatureFlags{featureEmailVerificationRequired: true}
+	handler := NewAuthenticateCommandHandler(mockRepo, vo.DefaultPasswordHasher(), newTestSessionIssuer(mockSessions, new(MockRefreshTokenRepository)), newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)), newTestMFAGate(newNotEnrolledRepository(), nil, false), flags, nopLogger{})
+
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+
+	response, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	assert.Equal(t, authDomain.ErrEmailNotVerified, err)
+	assert.Nil(t, response)
+	mockSessions.AssertNotCalled(t, "Save")
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+// ConfirmMFACommand activa el MFA de un usuario logueado con el primer codigo
+type ConfirmMFACommand struct {
+	UserID   string
+	TenantID string
+	Code     string
+}
+
+type ConfirmMFAResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type ConfirmMFACommandHandler struct {
+	enrollmentRepository domain.MFAEnrollmentRepository
+	txManager            TransactionManager
+}
+
+func NewConfirmMFACommandHandler(enrollmentRepo domain.MFAEnrollmentRepository, txManager TransactionManager) *ConfirmMFACommandHandler {
+	return &ConfirmMFACommandHandler{
+		enrollmentRepository: enrollmentRepo,
+		txManager:            txManager,
+	}
+}
+
+func (h *ConfirmMFACommandHandler) Handle(ctx context.Context, cmd ConfirmMFACommand) (*ConfirmMFAResponse, error) {
+	var codes []string
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		enrollment, err := h.enrollmentRepository.FindByUser(ctx, cmd.UserID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		codes, err = enrollment.Confirm(cmd.Code, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		return h.enrollmentRepository.Save(ctx, enrollment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfirmMFAResponse{RecoveryCodes: codes}, nil
+}
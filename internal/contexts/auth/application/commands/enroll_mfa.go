@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// EnrollMFACommand genera el secreto TOTP. Lo puede pedir un usuario logueado
+// (UserID) o uno que esta en medio del login de un tenant que exige MFA (ChallengeToken)
+type EnrollMFACommand struct {
+	UserID         string
+	ChallengeToken string
+	TenantID       string
+}
+
+type EnrollMFAResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type EnrollMFACommandHandler struct {
+	userRepository       userDomain.UserRepository
+	enrollmentRepository domain.MFAEnrollmentRepository
+	mfaGate              *MFAGate
+	issuerName           string
+}
+
+func NewEnrollMFACommandHandler(
+	userRepo userDomain.UserRepository,
+	enrollmentRepo domain.MFAEnrollmentRepository,
+	mfaGate *MFAGate,
+	issuerName string,
+) *EnrollMFACommandHandler {
+	return &EnrollMFACommandHandler{
+		userRepository:       userRepo,
+		enrollmentRepository: enrollmentRepo,
+		mfaGate:              mfaGate,
+		issuerName:           issuerName,
+	}
+}
+
+func (h *EnrollMFACommandHandler) Handle(ctx context.Context, cmd EnrollMFACommand) (*EnrollMFAResponse, error) {
+	userID := cmd.UserID
+
+	if cmd.ChallengeToken != "" {
+		challenge, err := h.mfaGate.findChallenge(ctx, cmd.ChallengeToken, cmd.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if !challenge.EnrollmentRequired() {
+			return nil, domain.ErrMFAAlreadyEnabled
+		}
+		userID = challenge.UserID()
+	}
+
+	existing, err := h.enrollmentRepository.FindByUser(ctx, userID, cmd.TenantID)
+	if err == nil && existing.IsConfirmed() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, err
+	}
+
+	user, err := h.userRepository.FindByID(ctx, userID, cmd.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	// un enrolamiento sin confirmar se pisa con un secreto nuevo
+	enrollment, err := domain.NewMFAEnrollment(userID, cmd.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.enrollmentRepository.Save(ctx, enrollment); err != nil {
+		return nil, err
+	}
+
+	return &EnrollMFAResponse{
+		Secret:     enrollment.Secret(),
+		OTPAuthURI: domain.TOTPURI(h.issuerName, user.Email().Value(), enrollment.Secret()),
+	}, nil
+}
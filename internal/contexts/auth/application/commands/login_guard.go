@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+// LoginGuard lleva la cuenta de logins fallidos por (tenant, email) y por IP y
+// bloquea temporalmente cuando se pasa el umbral
+type LoginGuard struct {
+	attemptsRepository domain.LoginAttemptsRepository
+	eventBus           EventBus
+	txManager          TransactionManager
+	emailPolicy        domain.LockoutPolicy
+	ipPolicy           domain.LockoutPolicy
+}
+
+func NewLoginGuard(
+	attemptsRepo domain.LoginAttemptsRepository,
+	eventBus EventBus,
+	txManager TransactionManager,
+	emailPolicy domain.LockoutPolicy,
+	ipPolicy domain.LockoutPolicy,
+) *LoginGuard {
+	return &LoginGuard{
+		attemptsRepository: attemptsRepo,
+		eventBus:           eventBus,
+		txManager:          txManager,
+		emailPolicy:        emailPolicy,
+		ipPolicy:           ipPolicy,
+	}
+}
+
+// Check devuelve ErrAccountLocked si el email o la IP estan bloqueados
+func (g *LoginGuard) Check(ctx context.Context, tenantID, email, ip string) error {
+	now := time.Now().UTC()
+
+	for _, subject := range subjects(email, ip) {
+		attempts, err := g.attemptsRepository.Find(ctx, tenantID, subject)
+		if err != nil {
+			return err
+		}
+		if attempts.IsLocked(now) {
+			return domain.ErrAccountLocked
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure registra un login fallido. userID viene vacio si el email no
+// existe, en ese caso se cuenta igual pero no hay evento user.locked
+func (g *LoginGuard) RecordFailure(ctx context.Context, tenantID, email, ip, userID, correlationID string) error {
+	now := time.Now().UTC()
+
+	return g.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		emailAttempts, err := g.attemptsRepository.Find(ctx, tenantID, domain.EmailSubject(email))
+		if err != nil {
+			return err
+		}
+
+		lockedNow := emailAttempts.RegisterFailure(g.emailPolicy, now)
+		if err := g.attemptsRepository.Save(ctx, emailAttempts); err != nil {
+			return err
+		}
+
+		if ip != "" {
+			ipAttempts, err := g.attemptsRepository.Find(ctx, tenantID, domain.IPSubject(ip))
+			if err != nil {
+				return err
+			}
+			ipAttempts.RegisterFailure(g.ipPolicy, now)
+			if err := g.attemptsRepository.Save(ctx, ipAttempts); err != nil {
+				return err
+			}
+		}
+
+		if !lockedNow || userID == "" {
+			return nil
+		}
+
+		return g.eventBus.Publish(ctx, domain.NewUserLockedEvent(
+			userID,
+			email,
+			tenantID,
+			correlationID,
+			emailAttempts.Failures(),
+			*emailAttempts.LockedUntil(),
+		))
+	})
+}
+
+// RecordSuccess limpia los fallos del email. Los de la IP se dejan vencer solos
+// para que un login bueno no habilite seguir probando otras cuentas
+func (g *LoginGuard) RecordSuccess(ctx context.Context, tenantID, email string) error {
+	return g.attemptsRepository.Delete(ctx, tenantID, domain.EmailSubject(email))
+}
+
+// Unlock saca el bloqueo de un email (lo usa el comando de admin)
+func (g *LoginGuard) Unlock(ctx context.Context, tenantID, email string) error {
+	return g.attemptsRepository.Delete(ctx, tenantID, domain.EmailSubject(email))
+}
+
+func subjects(email, ip string) []string {
+	result := []string{domain.EmailSubject(email)}
+	if ip != "" {
+		result = append(result, domain.IPSubject(ip))
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+// mismos nombres que en middleware, no importamos gin desde application
+const (
+	featureMFARequired               = "mfa_required"
+	featureEmailVerificationRequired = "email_verification_required"
+)
+
+type FeatureFlags interface {
+	IsEnabled(tenantID, feature string) bool
+}
+
+// MFAGate decide si despues de la password hace falta el segundo factor y,
+// en ese caso, abre el challenge que el cliente canjea en /mfa/verify
+type MFAGate struct {
+	enrollmentRepository domain.MFAEnrollmentRepository
+	challengeRepository  domain.MFAChallengeRepository
+	featureFlags         FeatureFlags
+	challengeTTL         time.Duration
+}
+
+func NewMFAGate(
+	enrollmentRepo domain.MFAEnrollmentRepository,
+	challengeRepo domain.MFAChallengeRepository,
+	featureFlags FeatureFlags,
+	challengeTTL time.Duration,
+) *MFAGate {
+	return &MFAGate{
+		enrollmentRepository: enrollmentRepo,
+		challengeRepository:  challengeRepo,
+		featureFlags:         featureFlags,
+		challengeTTL:         challengeTTL,
+	}
+}
+
+// Begin devuelve nil si el usuario no necesita MFA. Si el tenant lo exige y el
+// usuario todavia no lo configuro, el challenge sirve tambien para enrolarse
+func (g *MFAGate) Begin(ctx context.Context, userID, tenantID string) (*AuthenticateResponse, error) {
+	confirmed := false
+
+	enrollment, err := g.enrollmentRepository.FindByUser(ctx, userID, tenantID)
+	switch {
+	case err == nil:
+		confirmed = enrollment.IsConfirmed()
+	case !errors.Is(err, domain.ErrMFANotEnrolled):
+		return nil, err
+	}
+
+	if !confirmed && !g.featureFlags.IsEnabled(tenantID, featureMFARequired) {
+		return nil, nil
+	}
+
+	challenge := domain.NewMFAChallenge(userID, tenantID, !confirmed, g.challengeTTL)
+	if err := g.challengeRepository.Save(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &AuthenticateResponse{
+		UserID:                userID,
+		MFARequired:           true,
+		MFAEnrollmentRequired: !confirmed,
+		ChallengeToken:        challenge.Token(),
+		ChallengeExpiresAt:    challenge.ExpiresAt().Format(time.RFC3339),
+	}, nil
+}
+
+// findChallenge resuelve un challenge vigente del tenant
+func (g *MFAGate) findChallenge(ctx context.Context, token, tenantID string) (*domain.MFAChallenge, error) {
+	challenge, err := g.challengeRepository.FindByTokenHash(ctx, domain.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if challenge.TenantID() != tenantID || !challenge.IsUsable(time.Now().UTC()) {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+
+	return challenge, nil
+}
@@ -0,0 +1,275 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+type MockMFAEnrollmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFAEnrollmentRepository) Save(ctx context.Context, enrollment *authDomain.MFAEnrollment) error {
+	args := m.Called(ctx, enrollment)
+	return args.Error(0)
+}
+
+func (m *MockMFAEnrollmentRepository) FindByUser(ctx context.Context, userID, tenantID string) (*authDomain.MFAEnrollment, error) {
+	args := m.Called(ctx, userID, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.MFAEnrollment), args.Error(1)
+}
+
+type MockMFAChallengeRepository struct {
+	mock.Mock
+}
+
+func (m *MockMFAChallengeRepository) Save(ctx context.Context, challenge *authDomain.MFAChallenge) error {
+	args := m.Called(ctx, challenge)
+	return args.Error(0)
+}
+
+func (m *MockMFAChallengeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*authDomain.MFAChallenge, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*authDomain.MFAChallenge), args.Error(1)
+}
+
+type stubFeatureFlags map[string]bool
+
+func (f stubFeatureFlags) IsEnabled(tenantID, feature string) bool {
+	return f[feature]
+}
+
+func newNotEnrolledRepository() *MockMFAEnrollmentRepository {
+	repo := new(MockMFAEnrollmentRepository)
+	repo.On("FindByUser", mock.Anything, mock.Anything, mock.Anything).Return(nil, authDomain.ErrMFANotEnrolled)
+	return repo
+}
+
+func newTestMFAGate(enrollments *MockMFAEnrollmentRepository, challenges *MockMFAChallengeRepository, required bool) *MFAGate {
+	if challenges == nil {
+		challenges = new(MockMFAChallengeRepository)
+	}
+	return NewMFAGate(enrollments, challenges, stubFeatureFlags{featureMFARequired: required}, 5*time.Minute)
+}
+
+func newTestUser(t *testing.T) *userDomain.User {
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, err := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
+	require.NoError(t, err)
+	return user
+}
+
+// enrolamiento ya confirmado con un codigo consumido hace un minuto
+func newConfirmedEnrollment(t *testing.T, userID string) (*authDomain.MFAEnrollment, []string) {
+	enrollment, err := authDomain.NewMFAEnrollment(userID, "tenant-1")
+	require.NoError(t, err)
+
+	code, err := authDomain.TOTPCode(enrollment.Secret(), time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	recoveryCodes, err := enrollment.Confirm(code, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	return enrollment, recoveryCodes
+}
+
+func TestAuthenticateCommandHandler_MFAEnrolledReturnsChallenge(t *testing.T) {
+	ctx := context.Background()
+	user := newTestUser(t)
+	enrollment, _ := newConfirmedEnrollment(t, user.ID())
+
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	mockEnrollments := new(MockMFAEnrollmentRepository)
+	mockChallenges := new(MockMFAChallengeRepository)
+	handler := NewAuthenticateCommandHandler(
+		mockRepo,
+		vo.DefaultPasswordHasher(),
+		newTestSessionIssuer(mockSessions, new(MockRefreshTokenRepository)),
+		newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)),
+		newTestMFAGate(mockEnrollments, mockChallenges, false),
+		stubFeatureFlags{},
+		nopLogger{},
+	)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockEnrollments.On("FindByUser", ctx, user.ID(), "tenant-1").Return(enrollment, nil)
+	mockChallenges.On("Save", ctx, mock.AnythingOfType("*domain.MFAChallenge")).Return(nil)
+
+	response, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.MFARequired)
+	assert.False(t, response.MFAEnrollmentRequired)
+	assert.NotEmpty(t, response.ChallengeToken)
+	assert.Empty(t, response.Token)
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+func TestAuthenticateCommandHandler_TenantRequiresMFA(t *testing.T) {
+	ctx := context.Background()
+	user := newTestUser(t)
+
+	mockRepo := new(MockUserRepository)
+	mockSessions := new(MockSessionRepository)
+	mockChallenges := new(MockMFAChallengeRepository)
+	handler := NewAuthenticateCommandHandler(
+		mockRepo,
+		vo.DefaultPasswordHasher(),
+		newTestSessionIssuer(mockSessions, new(MockRefreshTokenRepository)),
+		newTestLoginGuard(newCleanAttemptsRepository(), new(MockEventBus)),
+		newTestMFAGate(newNotEnrolledRepository(), mockChallenges, true),
+		stubFeatureFlags{},
+		nopLogger{},
+	)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockChallenges.On("Save", ctx, mock.AnythingOfType("*domain.MFAChallenge")).Return(nil)
+
+	response, err := handler.Handle(ctx, AuthenticateCommand{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+		TenantID: "tenant-1",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.MFARequired)
+	assert.True(t, response.MFAEnrollmentRequired)
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+func newTestVerifyHandler(users *MockUserRepository, enrollments *MockMFAEnrollmentRepository, challenges *MockMFAChallengeRepository, sessions *MockSessionRepository, refreshTokens *MockRefreshTokenRepository, attempts *MockLoginAttemptsRepository) *VerifyMFACommandHandler {
+	return NewVerifyMFACommandHandler(
+		users,
+		enrollments,
+		challenges,
+		newTestMFAGate(enrollments, challenges, false),
+		newTestSessionIssuer(sessions, refreshTokens),
+		newTestLoginGuard(attempts, new(MockEventBus)),
+		&MockTransactionManager{},
+	)
+}
+
+func TestVerifyMFACommandHandler_ValidCodeIssuesSession(t *testing.T) {
+	ctx := context.Background()
+	user := newTestUser(t)
+	enrollment, _ := newConfirmedEnrollment(t, user.ID())
+	challenge := authDomain.NewMFAChallenge(user.ID(), "tenant-1", false, 5*time.Minute)
+
+	mockRepo := new(MockUserRepository)
+	mockEnrollments := new(MockMFAEnrollmentRepository)
+	mockChallenges := new(MockMFAChallengeRepository)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := newTestVerifyHandler(mockRepo, mockEnrollments, mockChallenges, mockSessions, mockRefreshTokens, newCleanAttemptsRepository())
+
+	mockChallenges.On("FindByTokenHash", ctx, challenge.TokenHash()).Return(challenge, nil)
+	mockChallenges.On("Save", ctx, challenge).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockEnrollments.On("FindByUser", ctx, user.ID(), "tenant-1").Return(enrollment, nil)
+	mockEnrollments.On("Save", ctx, enrollment).Return(nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	code, err := authDomain.TOTPCode(enrollment.Secret(), time.Now())
+	require.NoError(t, err)
+
+	response, err := handler.Handle(ctx, VerifyMFACommand{
+		ChallengeToken: challenge.Token(),
+		Code:           code,
+		TenantID:       "tenant-1",
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.NotNil(t, challenge.ConsumedAt())
+
+	// el challenge no se puede volver a usar
+	_, err = handler.Handle(ctx, VerifyMFACommand{
+		ChallengeToken: challenge.Token(),
+		Code:           code,
+		TenantID:       "tenant-1",
+	})
+	assert.ErrorIs(t, err, authDomain.ErrInvalidMFAChallenge)
+}
+
+func TestVerifyMFACommandHandler_InvalidCodeCountsAttempt(t *testing.T) {
+	ctx := context.Background()
+	user := newTestUser(t)
+	enrollment, _ := newConfirmedEnrollment(t, user.ID())
+	challenge := authDomain.NewMFAChallenge(user.ID(), "tenant-1", false, 5*time.Minute)
+
+	mockRepo := new(MockUserRepository)
+	mockEnrollments := new(MockMFAEnrollmentRepository)
+	mockChallenges := new(MockMFAChallengeRepository)
+	mockSessions := new(MockSessionRepository)
+	attempts := newCleanAttemptsRepository()
+	handler := newTestVerifyHandler(mockRepo, mockEnrollments, mockChallenges, mockSessions, new(MockRefreshTokenRepository), attempts)
+
+	mockChallenges.On("FindByTokenHash", ctx, challenge.TokenHash()).Return(challenge, nil)
+	mockChallenges.On("Save", ctx, challenge).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockEnrollments.On("FindByUser", ctx, user.ID(), "tenant-1").Return(enrollment, nil)
+
+	_, err := handler.Handle(ctx, VerifyMFACommand{
+		ChallengeToken: challenge.Token(),
+		Code:           "000000",
+		TenantID:       "tenant-1",
+	})
+
+	assert.ErrorIs(t, err, authDomain.ErrInvalidMFACode)
+	assert.Equal(t, 1, challenge.Attempts())
+	attempts.AssertCalled(t, "Save", mock.Anything, mock.AnythingOfType("*domain.LoginAttempts"))
+	mockSessions.AssertNotCalled(t, "Save")
+}
+
+func TestVerifyMFACommandHandler_RecoveryCode(t *testing.T) {
+	ctx := context.Background()
+	user := newTestUser(t)
+	enrollment, recoveryCodes := newConfirmedEnrollment(t, user.ID())
+	challenge := authDomain.NewMFAChallenge(user.ID(), "tenant-1", false, 5*time.Minute)
+
+	mockRepo := new(MockUserRepository)
+	mockEnrollments := new(MockMFAEnrollmentRepository)
+	mockChallenges := new(MockMFAChallengeRepository)
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := newTestVerifyHandler(mockRepo, mockEnrollments, mockChallenges, mockSessions, mockRefreshTokens, newCleanAttemptsRepository())
+
+	mockChallenges.On("FindByTokenHash", ctx, challenge.TokenHash()).Return(challenge, nil)
+	mockChallenges.On("Save", ctx, challenge).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockEnrollments.On("FindByUser", ctx, user.ID(), "tenant-1").Return(enrollment, nil)
+	mockEnrollments.On("Save", ctx, enrollment).Return(nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+
+	response, err := handler.Handle(ctx, VerifyMFACommand{
+		ChallengeToken: challenge.Token(),
+		RecoveryCode:   recoveryCodes[0],
+		TenantID:       "tenant-1",
+	})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+	assert.Len(t, enrollment.RecoveryCodeHashes(), len(recoveryCodes)-1)
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type RefreshTokenCommand struct {
+	RefreshToken  string
+	TenantID      string
+	CorrelationID string
+}
+
+type RefreshTokenCommandHandler struct {
+	userRepository         userDomain.UserRepository
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	tokenIssuer            domain.TokenIssuer
+	eventBus               EventBus
+	txManager              TransactionManager
+	lifetimes              TokenLifetimes
+}
+
+func NewRefreshTokenCommandHandler(
+	userRepo userDomain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	refreshTokenRepo domain.RefreshTokenRepository,
+	tokenIssuer domain.TokenIssuer,
+	eventBus EventBus,
+	txManager TransactionManager,
+	lifetimes TokenLifetimes,
+) *RefreshTokenCommandHandler {
+	return &RefreshTokenCommandHandler{
+		userRepository:         userRepo,
+		sessionRepository:      sessionRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		tokenIssuer:            tokenIssuer,
+		eventBus:               eventBus,
+		txManager:              txManager,
+		lifetimes:              lifetimes,
+	}
+}
+
+// Handle cambia un refresh token por un access token nuevo y el siguiente
+// refresh token de la familia. Si el token ya se habia usado se revoca la sesion
+// completa y se devuelve ErrRefreshTokenReused. Un usuario que ya no esta
+// activo no renueva aunque su sesion siga abierta
+func (h *RefreshTokenCommandHandler) Handle(ctx context.Context, cmd RefreshTokenCommand) (*AuthenticateResponse, error) {
+	if cmd.RefreshToken == "" {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+
+	var (
+		response *AuthenticateResponse
+		reused   bool
+	)
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		current, err := h.refreshTokenRepository.FindByTokenHash(ctx, domain.HashToken(cmd.RefreshToken))
+		if err != nil {
+			return err
+		}
+
+		if current.TenantID() != cmd.TenantID {
+			return domain.ErrInvalidRefreshToken
+		}
+
+		session, err := h.sessionRepository.FindByID(ctx, current.SessionID(), current.TenantID())
+		if err != nil {
+			if errors.Is(err, domain.ErrSessionNotFound) {
+				return domain.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		next := domain.NewRefreshToken(session.ID(), session.UserID(), session.TenantID(), h.lifetimes.RefreshToken)
+
+		if err := current.Rotate(next); err != nil {
+			if errors.Is(err, domain.ErrRefreshTokenReused) {
+				// la revocacion se tiene que commitear aunque la request falle
+				reused = true
+				return h.revokeFamily(ctx, session, cmd.CorrelationID)
+			}
+			return err
+		}
+
+		// la revocacion de sesiones al desactivar o borrar corre en el consumer:
+		// hasta que llegue, el estado del usuario es lo que corta el refresh
+		user, err := h.userRepository.FindByID(ctx, session.UserID(), session.TenantID())
+		if err != nil {
+			if errors.Is(err, userDomain.ErrUserNotFound) {
+				return domain.ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if !user.IsActive() {
+			return domain.ErrInvalidRefreshToken
+		}
+
+		if err := session.Renew(h.lifetimes.AccessToken, h.tokenIssuer); err != nil {
+			if errors.Is(err, domain.ErrSessionRevoked) {
+				return domain.ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		if err := h.refreshTokenRepository.Save(ctx, current); err != nil {
+			return err
+		}
+		if err := h.refreshTokenRepository.Save(ctx, next); err != nil {
+			return err
+		}
+		if err := h.sessionRepository.Save(ctx, session); err != nil {
+			return err
+		}
+
+		response = newAuthenticateResponse(session, next)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	return response, nil
+}
+
+// revokeFamily invalida todos los refresh tokens de la sesion y la sesion misma
+func (h *RefreshTokenCommandHandler) revokeFamily(ctx context.Context, session *domain.Session, correlationID string) error {
+	if session.IsRevoked() {
+		return h.refreshTokenRepository.RevokeBySession(ctx, session.ID(), session.TenantID())
+	}
+
+	return revokeSession(ctx, h.sessionRepository, h.refreshTokenRepository, h.eventBus, session, correlationID, domain.RevokeReasonTokenReuse)
+}
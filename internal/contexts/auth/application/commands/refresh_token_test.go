@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+func newTestRefreshHandler(sessions *MockSessionRepository, refreshTokens *MockRefreshTokenRepository, eventBus *MockEventBus) *RefreshTokenCommandHandler {
+	return newTestRefreshHandlerWithUser(newActiveUser(), sessions, refreshTokens, eventBus)
+}
+
+func newTestRefreshHandlerWithUser(user *userDomain.User, sessions *MockSessionRepository, refreshTokens *MockRefreshTokenRepository, eventBus *MockEventBus) *RefreshTokenCommandHandler {
+	users := new(MockUserRepository)
+	users.On("FindByID", mock.Anything, "user-1", "tenant-1").Return(user, nil)
+	return NewRefreshTokenCommandHandler(users, sessions, refreshTokens, authDomain.OpaqueTokenIssuer{}, eventBus, &MockTransactionManager{}, DefaultTokenLifetimes())
+}
+
+func newActiveUser() *userDomain.User {
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := userDomain.NewUser("Test User", email, password, "tenant-1", nil)
+	return user
+}
+
+func TestRefreshTokenCommandHandler_Rotates(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := newTestRefreshHandler(mockSessions, mockRefreshTokens, mockEventBus)
+
+	session := authDomain.NewSession("user-1", "tenant-1", time.Minute, authDomain.ClientInfo{})
+	oldAccessToken := session.Token()
+	current := authDomain.NewRefreshToken(session.ID(), "user-1", "tenant-1", time.Hour)
+
+	mockRefreshTokens.On("FindByTokenHash", ctx, current.TokenHash()).Return(current, nil)
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+	mockRefreshTokens.On("Save", ctx, mock.AnythingOfType("*domain.RefreshToken")).Return(nil)
+	mockSessions.On("Save", ctx, session).Return(nil)
+
+	response, err := handler.Handle(ctx, RefreshTokenCommand{RefreshToken: current.Token(), TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, current.Token(), response.RefreshToken)
+	assert.NotEqual(t, oldAccessToken, response.Token)
+	assert.True(t, current.IsUsed())
+	assert.NotEmpty(t, current.ReplacedBy())
+	mockRefreshTokens.AssertNumberOfCalls(t, "Save", 2)
+	mockEventBus.AssertNotCalled(t, "Publish")
+}
+
+// presentar un refresh token ya usado revoca la familia y la sesion
+func TestRefreshTokenCommandHandler_ReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := newTestRefreshHandler(mockSessions, mockRefreshTokens, mockEventBus)
+
+	session := authDomain.NewSession("user-1", "tenant-1", time.Minute, authDomain.ClientInfo{})
+	usedAt := time.Now().Add(-time.Minute)
+	used := authDomain.ReconstituteRefreshToken("rt-1", session.ID(), "user-1", "tenant-1", authDomain.HashToken("stolen"), time.Now().Add(time.Hour), time.Now().Add(-time.Hour), &usedAt, "rt-2", nil)
+
+	mockRefreshTokens.On("FindByTokenHash", ctx, authDomain.HashToken("stolen")).Return(used, nil)
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, session.ID(), "tenant-1").Return(nil)
+	mockSessions.On("Save", ctx, session).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.SessionRevokedEvent")).Return(nil)
+
+	response, err := handler.Handle(ctx, RefreshTokenCommand{RefreshToken: "stolen", TenantID: "tenant-1"})
+
+	assert.Equal(t, authDomain.ErrRefreshTokenReused, err)
+	assert.Nil(t, response)
+	assert.True(t, session.IsRevoked())
+	mockRefreshTokens.AssertExpectations(t)
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(authDomain.SessionRevokedEvent)
+	assert.Equal(t, authDomain.RevokeReasonTokenReuse, event.Reason)
+}
+
+func TestRefreshTokenCommandHandler_RevokedSession(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := newTestRefreshHandler(mockSessions, mockRefreshTokens, new(MockEventBus))
+
+	session := authDomain.NewSession("user-1", "tenant-1", time.Minute, authDomain.ClientInfo{})
+	_ = session.Revoke()
+	current := authDomain.NewRefreshToken(session.ID(), "user-1", "tenant-1", time.Hour)
+
+	mockRefreshTokens.On("FindByTokenHash", ctx, current.TokenHash()).Return(current, nil)
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+
+	response, err := handler.Handle(ctx, RefreshTokenCommand{RefreshToken: current.Token(), TenantID: "tenant-1"})
+
+	assert.Equal(t, authDomain.ErrInvalidRefreshToken, err)
+	assert.Nil(t, response)
+	mockRefreshTokens.AssertNotCalled(t, "Save")
+}
+
+func TestRefreshTokenCommandHandler_OtherTenant(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := newTestRefreshHandler(mockSessions, mockRefreshTokens, new(MockEventBus))
+
+	current := authDomain.NewRefreshToken("session-1", "user-1", "tenant-1", time.Hour)
+	mockRefreshTokens.On("FindByTokenHash", ctx, current.TokenHash()).Return(current, nil)
+
+	_, err := handler.Handle(ctx, RefreshTokenCommand{RefreshToken: current.Token(), TenantID: "tenant-2"})
+
+	assert.Equal(t, authDomain.ErrInvalidRefreshToken, err)
+	mockSessions.AssertNotCalled(t, "FindByID")
+}
+
+// una sesion abierta no alcanza: el usuario desactivado no renueva
+func TestRefreshTokenCommandHandler_InactiveUser(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+
+	user := newActiveUser()
+	assert.NoError(t, user.Deactivate())
+	handler := newTestRefreshHandlerWithUser(user, mockSessions, mockRefreshTokens, new(MockEventBus))
+
+	session := authDomain.NewSession("user-1", "tenant-1", time.Minute, authDomain.ClientInfo{})
+	current := authDomain.NewRefreshToken(session.ID(), "user-1", "tenant-1", time.Hour)
+
+	mockRefreshTokens.On("FindByTokenHash", ctx, current.TokenHash()).Return(current, nil)
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+
+	response, err := handler.Handle(ctx, RefreshTokenCommand{RefreshToken: current.Token(), TenantID: "tenant-1"})
+
+	assert.Equal(t, authDomain.ErrInvalidRefreshToken, err)
+	assert.Nil(t, response)
+	mockRefreshTokens.AssertNotCalled(t, "Save")
+	mockSessions.AssertNotCalled(t, "Save")
+}
@@ -0,0 +1,185 @@
+package commands
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+// RevokeSessionCommand revoca una sesion del usuario. Se usa para logout
+// (la sesion actual) y para cerrar un dispositivo puntual
+type RevokeSessionCommand struct {
+	SessionID     string
+	UserID        string
+	TenantID      string
+	CorrelationID string
+	Reason        string
+}
+
+type RevokeSessionCommandHandler struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	eventBus               EventBus
+	txManager              TransactionManager
+}
+
+func NewRevokeSessionCommandHandler(sessionRepo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository, eventBus EventBus, txManager TransactionManager) *RevokeSessionCommandHandler {
+	return &RevokeSessionCommandHandler{
+		sessionRepository:      sessionRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		eventBus:               eventBus,
+		txManager:              txManager,
+	}
+}
+
+func (h *RevokeSessionCommandHandler) Handle(ctx context.Context, cmd RevokeSessionCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		session, err := h.sessionRepository.FindByID(ctx, cmd.SessionID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		// un usuario no puede revocar sesiones de otro, respondemos como si no existiera
+		if session.UserID() != cmd.UserID {
+			return domain.ErrSessionNotFound
+		}
+
+		return revokeSession(ctx, h.sessionRepository, h.refreshTokenRepository, h.eventBus, session, cmd.CorrelationID, cmd.Reason)
+	})
+}
+
+// RevokeOtherSessionsCommand cierra todas las sesiones del usuario menos la actual
+type RevokeOtherSessionsCommand struct {
+	UserID           string
+	TenantID         string
+	CurrentSessionID string
+	CorrelationID    string
+}
+
+type RevokeOtherSessionsCommandHandler struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	eventBus               EventBus
+	txManager              TransactionManager
+}
+
+func NewRevokeOtherSessionsCommandHandler(sessionRepo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository, eventBus EventBus, txManager TransactionManager) *RevokeOtherSessionsCommandHandler {
+	return &RevokeOtherSessionsCommandHandler{
+		sessionRepository:      sessionRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		eventBus:               eventBus,
+		txManager:              txManager,
+	}
+}
+
+// Handle devuelve cuantas sesiones se revocaron
+func (h *RevokeOtherSessionsCommandHandler) Handle(ctx context.Context, cmd RevokeOtherSessionsCommand) (int, error) {
+	revoked := 0
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		revoked, err = revokeUserSessions(ctx, h.sessionRepository, h.refreshTokenRepository, h.eventBus, cmd.UserID, cmd.TenantID, cmd.CurrentSessionID, cmd.CorrelationID, domain.RevokeReasonOtherLogout)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// RevokeUserSessionsCommand cierra todas las sesiones del usuario salvo
+// KeepSessionID. Lo dispara el consumer cuando el usuario se suspende, se
+// borra o cambia la password
+type RevokeUserSessionsCommand struct {
+	UserID        string
+	TenantID      string
+	KeepSessionID string
+	CorrelationID string
+	Reason        string
+}
+
+type RevokeUserSessionsCommandHandler struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	eventBus               EventBus
+	txManager              TransactionManager
+}
+
+func NewRevokeUserSessionsCommandHandler(sessionRepo domain.SessionRepository, refreshTokenRepo domain.RefreshTokenRepository, eventBus EventBus, txManager TransactionManager) *RevokeUserSessionsCommandHandler {
+	return &RevokeUserSessionsCommandHandler{
+		sessionRepository:      sessionRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		eventBus:               eventBus,
+		txManager:              txManager,
+	}
+}
+
+func (h *RevokeUserSessionsCommandHandler) Handle(ctx context.Context, cmd RevokeUserSessionsCommand) (int, error) {
+	revoked := 0
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		revoked, err = revokeUserSessions(ctx, h.sessionRepository, h.refreshTokenRepository, h.eventBus, cmd.UserID, cmd.TenantID, cmd.KeepSessionID, cmd.CorrelationID, cmd.Reason)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// revokeUserSessions revoca las sesiones del usuario salvo keepSessionID. Van
+// tambien las de access token vencido, que todavia se pueden renovar
+func revokeUserSessions(ctx context.Context, repo domain.SessionRepository, refreshTokens domain.RefreshTokenRepository, eventBus EventBus, userID, tenantID, keepSessionID, correlationID, reason string) (int, error) {
+	sessions, err := repo.FindUnrevokedByUser(ctx, userID, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID() == keepSessionID {
+			continue
+		}
+		if err := revokeSession(ctx, repo, refreshTokens, eventBus, session, correlationID, reason); err != nil {
+			return 0, err
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// revokeSession revoca la sesion y su familia de refresh tokens en la misma
+// transaccion
+func revokeSession(ctx context.Context, repo domain.SessionRepository, refreshTokens domain.RefreshTokenRepository, eventBus EventBus, session *domain.Session, correlationID, reason string) error {
+	if err := session.Revoke(); err != nil {
+		return err
+	}
+
+	if err := repo.Save(ctx, session); err != nil {
+		return err
+	}
+
+	if err := refreshTokens.RevokeBySession(ctx, session.ID(), session.TenantID()); err != nil {
+		return err
+	}
+
+	return eventBus.Publish(ctx, domain.NewSessionRevokedEvent(
+		session.ID(),
+		session.UserID(),
+		session.TenantID(),
+		correlationID,
+		reason,
+	))
+}
+
+type EventBus interface {
+	Publish(ctx context.Context, event interface{}) error
+}
+
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Publish(ctx context.Context, event interface{}) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+type MockTransactionManager struct{}
+
+func (m *MockTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestRevokeSessionCommandHandler_Success(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockEventBus := new(MockEventBus)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewRevokeSessionCommandHandler(mockSessions, mockRefreshTokens, mockEventBus, &MockTransactionManager{})
+
+	session := authDomain.NewSession("user-1", "tenant-1", time.Hour, authDomain.ClientInfo{})
+
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+	mockSessions.On("Save", ctx, session).Return(nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, session.ID(), "tenant-1").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.SessionRevokedEvent")).Return(nil)
+
+	err := handler.Handle(ctx, RevokeSessionCommand{
+		SessionID: session.ID(),
+		UserID:    "user-1",
+		TenantID:  "tenant-1",
+		Reason:    authDomain.RevokeReasonLogout,
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, session.IsRevoked())
+	mockSessions.AssertExpectations(t)
+	mockRefreshTokens.AssertExpectations(t)
+	mockEventBus.AssertExpectations(t)
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(authDomain.SessionRevokedEvent)
+	assert.Equal(t, session.ID(), event.SessionID)
+	assert.Equal(t, authDomain.RevokeReasonLogout, event.Reason)
+}
+
+// la sesion de otro usuario se trata como inexistente
+func TestRevokeSessionCommandHandler_OtherUsersSession(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewRevokeSessionCommandHandler(mockSessions, new(MockRefreshTokenRepository), mockEventBus, &MockTransactionManager{})
+
+	session := authDomain.NewSession("user-2", "tenant-1", time.Hour, authDomain.ClientInfo{})
+	mockSessions.On("FindByID", ctx, session.ID(), "tenant-1").Return(session, nil)
+
+	err := handler.Handle(ctx, RevokeSessionCommand{
+		SessionID: session.ID(),
+		UserID:    "user-1",
+		TenantID:  "tenant-1",
+	})
+
+	assert.Equal(t, authDomain.ErrSessionNotFound, err)
+	assert.False(t, session.IsRevoked())
+	mockSessions.AssertNotCalled(t, "Save")
+	mockEventBus.AssertNotCalled(t, "Publish")
+}
+
+func TestRevokeOtherSessionsCommandHandler_KeepsCurrent(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockEventBus := new(MockEventBus)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewRevokeOtherSessionsCommandHandler(mockSessions, mockRefreshTokens, mockEventBus, &MockTransactionManager{})
+
+	current := authDomain.NewSession("user-1", "tenant-1", time.Hour, authDomain.ClientInfo{})
+	other1 := authDomain.NewSession("user-1", "tenant-1", time.Hour, authDomain.ClientInfo{})
+	// con el access token vencido se puede renovar igual: tambien se cierra
+	idle := authDomain.NewSession("user-1", "tenant-1", -time.Hour, authDomain.ClientInfo{})
+
+	mockSessions.On("FindUnrevokedByUser", ctx, "user-1", "tenant-1").Return([]*authDomain.Session{current, other1, idle}, nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, other1.ID(), "tenant-1").Return(nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, idle.ID(), "tenant-1").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.SessionRevokedEvent")).Return(nil)
+
+	revoked, err := handler.Handle(ctx, RevokeOtherSessionsCommand{
+		UserID:           "user-1",
+		TenantID:         "tenant-1",
+		CurrentSessionID: current.ID(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	assert.False(t, current.IsRevoked())
+	assert.True(t, other1.IsRevoked())
+	assert.True(t, idle.IsRevoked())
+	mockRefreshTokens.AssertExpectations(t)
+	mockEventBus.AssertNumberOfCalls(t, "Publish", 2)
+}
+
+// el reset de password no deja ninguna sesion: tampoco las de access token
+// vencido, que se podrian renovar con su refresh token
+func TestRevokeUserSessionsCommandHandler_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	mockSessions := new(MockSessionRepository)
+	mockEventBus := new(MockEventBus)
+	mockRefreshTokens := new(MockRefreshTokenRepository)
+	handler := NewRevokeUserSessionsCommandHandler(mockSessions, mockRefreshTokens, mockEventBus, &MockTransactionManager{})
+
+	active := authDomain.NewSession("user-1", "tenant-1", time.Hour, authDomain.ClientInfo{})
+	idle := authDomain.NewSession("user-1", "tenant-1", -time.Hour, authDomain.ClientInfo{})
+
+	mockSessions.On("FindUnrevokedByUser", ctx, "user-1", "tenant-1").Return([]*authDomain.Session{active, idle}, nil)
+	mockSessions.On("Save", ctx, mock.AnythingOfType("*domain.Session")).Return(nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, active.ID(), "tenant-1").Return(nil)
+	mockRefreshTokens.On("RevokeBySession", ctx, idle.ID(), "tenant-1").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.SessionRevokedEvent")).Return(nil)
+
+	revoked, err := handler.Handle(ctx, RevokeUserSessionsCommand{
+		UserID:   "user-1",
+		TenantID: "tenant-1",
+		Reason:   authDomain.RevokeReasonPasswordChanged,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+	assert.True(t, active.IsRevoked())
+	assert.True(t, idle.IsRevoked())
+	mockRefreshTokens.AssertExpectations(t)
+}
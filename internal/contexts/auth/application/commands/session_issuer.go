@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+// TokenLifetimes: el access token dura poco, el refresh token define cuanto
+// puede vivir la sesion sin volver a loguearse
+type TokenLifetimes struct {
+	AccessToken  time.Duration
+	RefreshToken time.Duration
+}
+
+func DefaultTokenLifetimes() TokenLifetimes {
+	return TokenLifetimes{
+		AccessToken:  15 * time.Minute,
+		RefreshToken: 30 * 24 * time.Hour,
+	}
+}
+
+// SessionIssuer abre sesiones nuevas (access + refresh token). Lo usan el login
+// y cualquier otro flujo que termine autenticando al usuario
+type SessionIssuer struct {
+	sessionRepository      domain.SessionRepository
+	refreshTokenRepository domain.RefreshTokenRepository
+	tokenIssuer            domain.TokenIssuer
+	txManager              TransactionManager
+	lifetimes              TokenLifetimes
+}
+
+func NewSessionIssuer(
+	sessionRepo domain.SessionRepository,
+	refreshTokenRepo domain.RefreshTokenRepository,
+	tokenIssuer domain.TokenIssuer,
+	txManager TransactionManager,
+	lifetimes TokenLifetimes,
+) *SessionIssuer {
+	return &SessionIssuer{
+		sessionRepository:      sessionRepo,
+		refreshTokenRepository: refreshTokenRepo,
+		tokenIssuer:            tokenIssuer,
+		txManager:              txManager,
+		lifetimes:              lifetimes,
+	}
+}
+
+func (i *SessionIssuer) Start(ctx context.Context, userID, tenantID string, client domain.ClientInfo) (*AuthenticateResponse, error) {
+	session := domain.NewSession(userID, tenantID, i.lifetimes.AccessToken, client)
+
+	if err := session.IssueToken(i.tokenIssuer); err != nil {
+		return nil, err
+	}
+
+	refreshToken := domain.NewRefreshToken(session.ID(), userID, tenantID, i.lifetimes.RefreshToken)
+
+	err := i.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := i.sessionRepository.Save(ctx, session); err != nil {
+			return err
+		}
+		return i.refreshTokenRepository.Save(ctx, refreshToken)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newAuthenticateResponse(session, refreshToken), nil
+}
+
+func newAuthenticateResponse(session *domain.Session, refreshToken *domain.RefreshToken) *AuthenticateResponse {
+	return &AuthenticateResponse{
+		Token:                 session.Token(),
+		UserID:                session.UserID(),
+		ExpiresAt:             session.ExpiresAt().Format(time.RFC3339),
+		RefreshToken:          refreshToken.Token(),
+		RefreshTokenExpiresAt: refreshToken.ExpiresAt().Format(time.RFC3339),
+	}
+}
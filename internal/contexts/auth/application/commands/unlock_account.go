@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+)
+
+// UnlockAccountCommand lo usa un admin para desbloquear un email antes de que venza el bloqueo
+type UnlockAccountCommand struct {
+	Email    string
+	TenantID string
+}
+
+type UnlockAccountCommandHandler struct {
+	loginGuard *LoginGuard
+}
+
+func NewUnlockAccountCommandHandler(loginGuard *LoginGuard) *UnlockAccountCommandHandler {
+	return &UnlockAccountCommandHandler{
+		loginGuard: loginGuard,
+	}
+}
+
+func (h *UnlockAccountCommandHandler) Handle(ctx context.Context, cmd UnlockAccountCommand) error {
+	return h.loginGuard.Unlock(ctx, cmd.TenantID, cmd.Email)
+}
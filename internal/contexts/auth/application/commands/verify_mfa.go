@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// VerifyMFACommand es el segundo paso del login: canjea el challenge mas un
+// codigo TOTP (o un recovery code) por una sesion
+type VerifyMFACommand struct {
+	ChallengeToken string
+	Code           string
+	RecoveryCode   string
+	TenantID       string
+	UserAgent      string
+	IPAddress      string
+	CorrelationID  string
+}
+
+type VerifyMFACommandHandler struct {
+	userRepository       userDomain.UserRepository
+	enrollmentRepository domain.MFAEnrollmentRepository
+	challengeRepository  domain.MFAChallengeRepository
+	mfaGate              *MFAGate
+	sessionIssuer        *SessionIssuer
+	loginGuard           *LoginGuard
+	txManager            TransactionManager
+}
+
+func NewVerifyMFACommandHandler(
+	userRepo userDomain.UserRepository,
+	enrollmentRepo domain.MFAEnrollmentRepository,
+	challengeRepo domain.MFAChallengeRepository,
+	mfaGate *MFAGate,
+	sessionIssuer *SessionIssuer,
+	loginGuard *LoginGuard,
+	txManager TransactionManager,
+) *VerifyMFACommandHandler {
+	return &VerifyMFACommandHandler{
+		userRepository:       userRepo,
+		enrollmentRepository: enrollmentRepo,
+		challengeRepository:  challengeRepo,
+		mfaGate:              mfaGate,
+		sessionIssuer:        sessionIssuer,
+		loginGuard:           loginGuard,
+		txManager:            txManager,
+	}
+}
+
+func (h *VerifyMFACommandHandler) Handle(ctx context.Context, cmd VerifyMFACommand) (*AuthenticateResponse, error) {
+	var (
+		response  *AuthenticateResponse
+		user      *userDomain.User
+		verifyErr error
+	)
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		challenge, err := h.mfaGate.findChallenge(ctx, cmd.ChallengeToken, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		user, err = h.userRepository.FindByID(ctx, challenge.UserID(), cmd.TenantID)
+		if err != nil {
+			return err
+		}
+		if !user.IsActive() {
+			return domain.ErrAccountDisabled
+		}
+
+		// los codigos erroneos cuentan para el bloqueo igual que las passwords
+		if err := h.loginGuard.Check(ctx, cmd.TenantID, user.Email().Value(), cmd.IPAddress); err != nil {
+			return err
+		}
+
+		enrollment, err := h.enrollmentRepository.FindByUser(ctx, challenge.UserID(), cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		var recoveryCodes []string
+
+		switch {
+		case !enrollment.IsConfirmed():
+			recoveryCodes, verifyErr = enrollment.Confirm(cmd.Code, now)
+		case cmd.RecoveryCode != "":
+			verifyErr = enrollment.UseRecoveryCode(cmd.RecoveryCode)
+		default:
+			verifyErr = enrollment.VerifyCode(cmd.Code, now)
+		}
+
+		if verifyErr != nil {
+			// el intento fallido se guarda, por eso no se devuelve el error aca
+			challenge.RegisterFailedAttempt()
+			return h.challengeRepository.Save(ctx, challenge)
+		}
+
+		challenge.Consume(now)
+		if err := h.challengeRepository.Save(ctx, challenge); err != nil {
+			return err
+		}
+		if err := h.enrollmentRepository.Save(ctx, enrollment); err != nil {
+			return err
+		}
+
+		response, err = h.sessionIssuer.Start(ctx, challenge.UserID(), cmd.TenantID, domain.ClientInfo{
+			UserAgent: cmd.UserAgent,
+			IPAddress: cmd.IPAddress,
+		})
+		if err != nil {
+			return err
+		}
+
+		response.RecoveryCodes = recoveryCodes
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrMFANotEnrolled) {
+			return nil, domain.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+
+	if verifyErr != nil {
+		if err := h.loginGuard.RecordFailure(ctx, cmd.TenantID, user.Email().Value(), cmd.IPAddress, user.ID(), cmd.CorrelationID); err != nil {
+			return nil, err
+		}
+		return nil, verifyErr
+	}
+
+	return response, nil
+}
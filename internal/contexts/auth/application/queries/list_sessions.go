@@ -0,0 +1,45 @@
+package queries
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+type ListSessionsQuery struct {
+	UserID           string
+	TenantID         string
+	CurrentSessionID string
+}
+
+type ListSessionsQueryHandler struct {
+	sessionRepository domain.SessionRepository
+}
+
+func NewListSessionsQueryHandler(sessionRepo domain.SessionRepository) *ListSessionsQueryHandler {
+	return &ListSessionsQueryHandler{
+		sessionRepository: sessionRepo,
+	}
+}
+
+// Handle lista las sesiones activas del usuario marcando la de la request actual
+func (h *ListSessionsQueryHandler) Handle(ctx context.Context, query ListSessionsQuery) ([]domain.SessionView, error) {
+	sessions, err := h.sessionRepository.FindActiveByUser(ctx, query.UserID, query.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]domain.SessionView, 0, len(sessions))
+	for _, session := range sessions {
+		views = append(views, domain.SessionView{
+			ID:        session.ID(),
+			UserAgent: session.Client().UserAgent,
+			IPAddress: session.Client().IPAddress,
+			CreatedAt: session.CreatedAt(),
+			ExpiresAt: session.ExpiresAt(),
+			Current:   session.ID() == query.CurrentSessionID,
+		})
+	}
+
+	return views, nil
+}
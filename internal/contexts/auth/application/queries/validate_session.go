@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+type ValidateSessionQuery struct {
+	Token string
+}
+
+type ValidateSessionQueryHandler struct {
+	sessionRepository domain.SessionRepository
+}
+
+func NewValidateSessionQueryHandler(sessionRepo domain.SessionRepository) *ValidateSessionQueryHandler {
+	return &ValidateSessionQueryHandler{
+		sessionRepository: sessionRepo,
+	}
+}
+
+// Handle devuelve la sesion activa que corresponde al token
+func (h *ValidateSessionQueryHandler) Handle(ctx context.Context, query ValidateSessionQuery) (*domain.Session, error) {
+	if query.Token == "" {
+		return nil, domain.ErrSessionNotFound
+	}
+
+	session, err := h.sessionRepository.FindByTokenHash(ctx, domain.HashToken(query.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	if session.IsRevoked() {
+		return nil, domain.ErrSessionRevoked
+	}
+
+	if session.IsExpired() {
+		return nil, domain.ErrSessionExpired
+	}
+
+	return session, nil
+}
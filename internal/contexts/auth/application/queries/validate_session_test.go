@@ -0,0 +1,108 @@
+package queries
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindByID(ctx context.Context, id, tenantID string) (*domain.Session, error) {
+	args := m.Called(ctx, id, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindActiveByUser(ctx context.Context, userID, tenantID string) ([]*domain.Session, error) {
+	args := m.Called(ctx, userID, tenantID)
+	return args.Get(0).([]*domain.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindUnrevokedByUser(ctx context.Context, userID, tenantID string) ([]*domain.Session, error) {
+	args := m.Called(ctx, userID, tenantID)
+	return args.Get(0).([]*domain.Session), args.Error(1)
+}
+
+func TestValidateSessionQueryHandler_Success(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionRepository)
+	handler := NewValidateSessionQueryHandler(mockRepo)
+
+	session := domain.NewSession("user-1", "tenant-1", time.Hour, domain.ClientInfo{})
+	mockRepo.On("FindByTokenHash", ctx, domain.HashToken(session.Token())).Return(session, nil)
+
+	result, err := handler.Handle(ctx, ValidateSessionQuery{Token: session.Token()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", result.UserID())
+	assert.Equal(t, "tenant-1", result.TenantID())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestValidateSessionQueryHandler_Expired(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionRepository)
+	handler := NewValidateSessionQueryHandler(mockRepo)
+
+	expired := domain.ReconstituteSession("session-1", "user-1", "tenant-1", domain.HashToken("token"), domain.ClientInfo{}, time.Now().Add(-time.Minute), time.Now().Add(-time.Hour), nil)
+	mockRepo.On("FindByTokenHash", ctx, domain.HashToken("token")).Return(expired, nil)
+
+	result, err := handler.Handle(ctx, ValidateSessionQuery{Token: "token"})
+
+	assert.Equal(t, domain.ErrSessionExpired, err)
+	assert.Nil(t, result)
+}
+
+func TestValidateSessionQueryHandler_Revoked(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionRepository)
+	handler := NewValidateSessionQueryHandler(mockRepo)
+
+	session := domain.NewSession("user-1", "tenant-1", time.Hour, domain.ClientInfo{})
+	_ = session.Revoke()
+	mockRepo.On("FindByTokenHash", ctx, domain.HashToken(session.Token())).Return(session, nil)
+
+	result, err := handler.Handle(ctx, ValidateSessionQuery{Token: session.Token()})
+
+	assert.Equal(t, domain.ErrSessionRevoked, err)
+	assert.Nil(t, result)
+}
+
+func TestValidateSessionQueryHandler_NotFound(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionRepository)
+	handler := NewValidateSessionQueryHandler(mockRepo)
+
+	mockRepo.On("FindByTokenHash", ctx, domain.HashToken("unknown")).Return(nil, domain.ErrSessionNotFound)
+
+	result, err := handler.Handle(ctx, ValidateSessionQuery{Token: "unknown"})
+
+	assert.Equal(t, domain.ErrSessionNotFound, err)
+	assert.Nil(t, result)
+
+	_, err = handler.Handle(ctx, ValidateSessionQuery{Token: ""})
+	assert.Equal(t, domain.ErrSessionNotFound, err)
+}
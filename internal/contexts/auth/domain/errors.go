@@ -6,4 +6,18 @@ var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrSessionExpired     = errors.New("session expired")
 	ErrSessionNotFound    = errors.New("session not found")
-)
\ No newline at end of file
+	ErrSessionRevoked     = errors.New("session revoked")
+
+	ErrAccountLocked = errors.New("account temporarily locked")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+
+	ErrAccountDisabled  = errors.New("account disabled")
+	ErrEmailNotVerified = errors.New("email not verified")
+
+	ErrMFANotEnrolled      = errors.New("mfa not enrolled")
+	ErrMFAAlreadyEnabled   = errors.New("mfa already enabled")
+	ErrInvalidMFACode      = errors.New("invalid mfa code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+)
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"time"
+
+	shared "backend-challenge-guinea/internal/shared/domain"
+)
+
+const (
+	SessionRevokedEventType = "session.revoked"
+	UserLockedEventType     = "user.locked"
+)
+
+const (
+	RevokeReasonLogout          = "logout"
+	RevokeReasonUserRevoked     = "user_revoked"
+	RevokeReasonOtherLogout     = "revoke_others"
+	RevokeReasonTokenReuse      = "refresh_token_reuse"
+	RevokeReasonUserDisabled    = "user_disabled"
+	RevokeReasonPasswordChanged = "password_changed"
+)
+
+type SessionRevokedEvent struct {
+	shared.BaseEvent
+	SessionID string `json:"session_id"`
+	UserID    string `json:"user_id"`
+	Reason    string `json:"reason"`
+}
+
+func NewSessionRevokedEvent(sessionID, userID, tenantID, correlationID, reason string) SessionRevokedEvent {
+	return SessionRevokedEvent{
+		BaseEvent: shared.NewBaseEvent(SessionRevokedEventType, sessionID, tenantID, correlationID),
+		SessionID: sessionID,
+		UserID:    userID,
+		Reason:    reason,
+	}
+}
+
+// UserLockedEvent se publica cuando una cuenta queda bloqueada por logins fallidos
+type UserLockedEvent struct {
+	shared.BaseEvent
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Failures    int       `json:"failures"`
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+func NewUserLockedEvent(userID, email, tenantID, correlationID string, failures int, lockedUntil time.Time) UserLockedEvent {
+	return UserLockedEvent{
+		BaseEvent:   shared.NewBaseEvent(UserLockedEventType, userID, tenantID, correlationID),
+		UserID:      userID,
+		Email:       email,
+		Failures:    failures,
+		LockedUntil: lockedUntil,
+	}
+}
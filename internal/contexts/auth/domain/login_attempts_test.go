@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var policy = LockoutPolicy{
+	MaxFailures:        3,
+	Window:             15 * time.Minute,
+	LockoutDuration:    time.Minute,
+	MaxLockoutDuration: 10 * time.Minute,
+}
+
+func TestLoginAttempts_LocksAtThreshold(t *testing.T) {
+	now := time.Now().UTC()
+	attempts := NewLoginAttempts("tenant-1", EmailSubject("Test@Example.com"))
+
+	assert.False(t, attempts.RegisterFailure(policy, now))
+	assert.False(t, attempts.RegisterFailure(policy, now))
+	assert.True(t, attempts.RegisterFailure(policy, now))
+
+	assert.True(t, attempts.IsLocked(now))
+	assert.False(t, attempts.IsLocked(now.Add(time.Minute+time.Second)))
+	assert.Equal(t, "email:test@example.com", attempts.Subject())
+}
+
+// cada fallo despues del umbral duplica el bloqueo hasta el maximo
+func TestLoginAttempts_ProgressiveLockout(t *testing.T) {
+	now := time.Now().UTC()
+	attempts := ReconstituteLoginAttempts("tenant-1", "ip:10.0.0.1", 3, now, nil)
+
+	attempts.RegisterFailure(policy, now)
+	assert.Equal(t, now.Add(2*time.Minute), *attempts.LockedUntil())
+
+	attempts.RegisterFailure(policy, now)
+	assert.Equal(t, now.Add(4*time.Minute), *attempts.LockedUntil())
+
+	for i := 0; i < 5; i++ {
+		attempts.RegisterFailure(policy, now)
+	}
+	assert.Equal(t, now.Add(10*time.Minute), *attempts.LockedUntil())
+}
+
+func TestLoginAttempts_WindowResetsCounter(t *testing.T) {
+	now := time.Now().UTC()
+	attempts := ReconstituteLoginAttempts("tenant-1", "email:a@b.com", 2, now.Add(-time.Hour), nil)
+
+	locked := attempts.RegisterFailure(policy, now)
+
+	assert.False(t, locked)
+	assert.Equal(t, 1, attempts.Failures())
+}
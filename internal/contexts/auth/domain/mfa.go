@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MFAEnrollment es el segundo factor TOTP de un usuario. Hasta que se confirma
+// con un primer codigo valido no se pide en el login
+type MFAEnrollment struct {
+	userID             string
+	tenantID           string
+	secret             string
+	confirmedAt        *time.Time
+	lastUsedStep       int64
+	recoveryCodeHashes []string
+	createdAt          time.Time
+}
+
+func NewMFAEnrollment(userID, tenantID string) (*MFAEnrollment, error) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{
+		userID:    userID,
+		tenantID:  tenantID,
+		secret:    secret,
+		createdAt: time.Now().UTC(),
+	}, nil
+}
+
+func ReconstituteMFAEnrollment(userID, tenantID, secret string, confirmedAt *time.Time, lastUsedStep int64, recoveryCodeHashes []string, createdAt time.Time) *MFAEnrollment {
+	return &MFAEnrollment{
+		userID:             userID,
+		tenantID:           tenantID,
+		secret:             secret,
+		confirmedAt:        confirmedAt,
+		lastUsedStep:       lastUsedStep,
+		recoveryCodeHashes: recoveryCodeHashes,
+		createdAt:          createdAt,
+	}
+}
+
+func (e *MFAEnrollment) IsConfirmed() bool {
+	return e.confirmedAt != nil
+}
+
+// Confirm activa el factor con el primer codigo y devuelve los recovery codes en claro
+func (e *MFAEnrollment) Confirm(code string, now time.Time) ([]string, error) {
+	if e.IsConfirmed() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	if err := e.VerifyCode(code, now); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	e.confirmedAt = &now
+	e.recoveryCodeHashes = hashes
+	return codes, nil
+}
+
+// VerifyCode valida un codigo TOTP. Un codigo ya usado (mismo paso o anterior) se rechaza
+func (e *MFAEnrollment) VerifyCode(code string, now time.Time) error {
+	step, ok := matchTOTP(e.secret, code, now)
+	if !ok || step <= e.lastUsedStep {
+		return ErrInvalidMFACode
+	}
+
+	e.lastUsedStep = step
+	return nil
+}
+
+// UseRecoveryCode consume un recovery code, cada uno sirve una sola vez
+func (e *MFAEnrollment) UseRecoveryCode(code string) error {
+	hash := hashRecoveryCode(code)
+	for i, stored := range e.recoveryCodeHashes {
+		if stored == hash {
+			e.recoveryCodeHashes = append(e.recoveryCodeHashes[:i], e.recoveryCodeHashes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrInvalidMFACode
+}
+
+func (e *MFAEnrollment) UserID() string               { return e.userID }
+func (e *MFAEnrollment) TenantID() string             { return e.tenantID }
+func (e *MFAEnrollment) Secret() string               { return e.secret }
+func (e *MFAEnrollment) ConfirmedAt() *time.Time      { return e.confirmedAt }
+func (e *MFAEnrollment) LastUsedStep() int64          { return e.lastUsedStep }
+func (e *MFAEnrollment) RecoveryCodeHashes() []string { return e.recoveryCodeHashes }
+func (e *MFAEnrollment) CreatedAt() time.Time         { return e.createdAt }
+
+const maxMFAChallengeAttempts = 5
+
+// MFAChallenge es el paso intermedio del login: la password ya se valido y
+// falta el codigo. El token es de un solo uso y dura pocos minutos
+type MFAChallenge struct {
+	id                 string
+	userID             string
+	tenantID           string
+	token              string
+	tokenHash          string
+	enrollmentRequired bool
+	attempts           int
+	expiresAt          time.Time
+	createdAt          time.Time
+	consumedAt         *time.Time
+}
+
+func NewMFAChallenge(userID, tenantID string, enrollmentRequired bool, duration time.Duration) *MFAChallenge {
+	now := time.Now().UTC()
+	token := generateToken()
+	return &MFAChallenge{
+		id:                 uuid.New().String(),
+		userID:             userID,
+		tenantID:           tenantID,
+		token:              token,
+		tokenHash:          HashToken(token),
+		enrollmentRequired: enrollmentRequired,
+		expiresAt:          now.Add(duration),
+		createdAt:          now,
+	}
+}
+
+func ReconstituteMFAChallenge(id, userID, tenantID, tokenHash string, enrollmentRequired bool, attempts int, expiresAt, createdAt time.Time, consumedAt *time.Time) *MFAChallenge {
+	return &MFAChallenge{
+		id:                 id,
+		userID:             userID,
+		tenantID:           tenantID,
+		tokenHash:          tokenHash,
+		enrollmentRequired: enrollmentRequired,
+		attempts:           attempts,
+		expiresAt:          expiresAt,
+		createdAt:          createdAt,
+		consumedAt:         consumedAt,
+	}
+}
+
+// IsUsable: no vencido, no consumido y sin demasiados codigos erroneos
+func (c *MFAChallenge) IsUsable(now time.Time) bool {
+	return c.consumedAt == nil && now.Before(c.expiresAt) && c.attempts < maxMFAChallengeAttempts
+}
+
+func (c *MFAChallenge) RegisterFailedAttempt() {
+	c.attempts++
+}
+
+func (c *MFAChallenge) Consume(now time.Time) {
+	c.consumedAt = &now
+}
+
+func (c *MFAChallenge) ID() string               { return c.id }
+func (c *MFAChallenge) UserID() string           { return c.userID }
+func (c *MFAChallenge) TenantID() string         { return c.tenantID }
+func (c *MFAChallenge) Token() string            { return c.token }
+func (c *MFAChallenge) TokenHash() string        { return c.tokenHash }
+func (c *MFAChallenge) EnrollmentRequired() bool { return c.enrollmentRequired }
+func (c *MFAChallenge) Attempts() int            { return c.attempts }
+func (c *MFAChallenge) ExpiresAt() time.Time     { return c.expiresAt }
+func (c *MFAChallenge) CreatedAt() time.Time     { return c.createdAt }
+func (c *MFAChallenge) ConsumedAt() *time.Time   { return c.consumedAt }
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vectores del apendice B de la RFC 6238 (SHA1), recortados a 6 digitos
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	secret := base32NoPadding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(secret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code)
+	}
+}
+
+func TestMFAEnrollment_ConfirmAndReplay(t *testing.T) {
+	enrollment, err := NewMFAEnrollment("user-1", "tenant-1")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	code, err := TOTPCode(enrollment.Secret(), now)
+	require.NoError(t, err)
+
+	_, err = enrollment.Confirm("000000", now)
+	assert.ErrorIs(t, err, ErrInvalidMFACode)
+	assert.False(t, enrollment.IsConfirmed())
+
+	recoveryCodes, err := enrollment.Confirm(code, now)
+	require.NoError(t, err)
+	assert.True(t, enrollment.IsConfirmed())
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+
+	// el mismo codigo no sirve dos veces
+	assert.ErrorIs(t, enrollment.VerifyCode(code, now), ErrInvalidMFACode)
+
+	next, err := TOTPCode(enrollment.Secret(), now.Add(totpPeriod*time.Second))
+	require.NoError(t, err)
+	assert.NoError(t, enrollment.VerifyCode(next, now.Add(totpPeriod*time.Second)))
+}
+
+func TestMFAEnrollment_RecoveryCodesAreSingleUse(t *testing.T) {
+	enrollment, err := NewMFAEnrollment("user-1", "tenant-1")
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	code, _ := TOTPCode(enrollment.Secret(), now)
+	recoveryCodes, err := enrollment.Confirm(code, now)
+	require.NoError(t, err)
+
+	assert.NoError(t, enrollment.UseRecoveryCode(recoveryCodes[0]))
+	assert.ErrorIs(t, enrollment.UseRecoveryCode(recoveryCodes[0]), ErrInvalidMFACode)
+	// se aceptan sin guion y en mayusculas
+	assert.NoError(t, enrollment.UseRecoveryCode(strings.ToUpper(strings.ReplaceAll(recoveryCodes[1], "-", ""))))
+}
+
+func TestMFAChallenge_IsUsable(t *testing.T) {
+	challenge := NewMFAChallenge("user-1", "tenant-1", false, 5*time.Minute)
+	now := time.Now().UTC()
+
+	assert.True(t, challenge.IsUsable(now))
+	assert.False(t, challenge.IsUsable(now.Add(6*time.Minute)))
+
+	for i := 0; i < maxMFAChallengeAttempts; i++ {
+		challenge.RegisterFailedAttempt()
+	}
+	assert.False(t, challenge.IsUsable(now))
+}
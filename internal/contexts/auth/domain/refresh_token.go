@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken es de un solo uso. Todos los refresh tokens de una sesion forman
+// una familia: cada refresh marca el actual como usado y emite el siguiente.
+// Si aparece uno ya usado alguien lo robo y se revoca la familia entera
+type RefreshToken struct {
+	id         string
+	sessionID  string
+	userID     string
+	tenantID   string
+	token      string
+	tokenHash  string
+	expiresAt  time.Time
+	createdAt  time.Time
+	usedAt     *time.Time
+	replacedBy string
+	revokedAt  *time.Time
+}
+
+func NewRefreshToken(sessionID, userID, tenantID string, duration time.Duration) *RefreshToken {
+	now := time.Now().UTC()
+	token := generateToken()
+	return &RefreshToken{
+		id:        uuid.New().String(),
+		sessionID: sessionID,
+		userID:    userID,
+		tenantID:  tenantID,
+		token:     token,
+		tokenHash: HashToken(token),
+		expiresAt: now.Add(duration),
+		createdAt: now,
+	}
+}
+
+func ReconstituteRefreshToken(id, sessionID, userID, tenantID, tokenHash string, expiresAt, createdAt time.Time, usedAt *time.Time, replacedBy string, revokedAt *time.Time) *RefreshToken {
+	return &RefreshToken{
+		id:         id,
+		sessionID:  sessionID,
+		userID:     userID,
+		tenantID:   tenantID,
+		tokenHash:  tokenHash,
+		expiresAt:  expiresAt,
+		createdAt:  createdAt,
+		usedAt:     usedAt,
+		replacedBy: replacedBy,
+		revokedAt:  revokedAt,
+	}
+}
+
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().UTC().After(t.expiresAt)
+}
+
+func (t *RefreshToken) IsUsed() bool {
+	return t.usedAt != nil
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.revokedAt != nil
+}
+
+// Rotate marca el token como usado y lo encadena con el siguiente de la familia
+func (t *RefreshToken) Rotate(next *RefreshToken) error {
+	if t.IsUsed() {
+		return ErrRefreshTokenReused
+	}
+	if t.IsRevoked() || t.IsExpired() {
+		return ErrInvalidRefreshToken
+	}
+
+	now := time.Now().UTC()
+	t.usedAt = &now
+	t.replacedBy = next.id
+	return nil
+}
+
+func (t *RefreshToken) ID() string            { return t.id }
+func (t *RefreshToken) SessionID() string     { return t.sessionID }
+func (t *RefreshToken) UserID() string        { return t.userID }
+func (t *RefreshToken) TenantID() string      { return t.tenantID }
+func (t *RefreshToken) Token() string         { return t.token }
+func (t *RefreshToken) TokenHash() string     { return t.tokenHash }
+func (t *RefreshToken) ExpiresAt() time.Time  { return t.expiresAt }
+func (t *RefreshToken) CreatedAt() time.Time  { return t.createdAt }
+func (t *RefreshToken) UsedAt() *time.Time    { return t.usedAt }
+func (t *RefreshToken) ReplacedBy() string    { return t.replacedBy }
+func (t *RefreshToken) RevokedAt() *time.Time { return t.revokedAt }
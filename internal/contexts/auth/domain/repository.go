@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type SessionRepository interface {
+	Save(ctx context.Context, session *Session) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*Session, error)
+	FindByID(ctx context.Context, id, tenantID string) (*Session, error)
+	FindActiveByUser(ctx context.Context, userID, tenantID string) ([]*Session, error)
+	// FindUnrevokedByUser incluye las que vencio el access token: se pueden
+	// renovar con un refresh token, asi que cerrarlas tambien hay que cerrarlas
+	FindUnrevokedByUser(ctx context.Context, userID, tenantID string) ([]*Session, error)
+}
+
+type RefreshTokenRepository interface {
+	Save(ctx context.Context, token *RefreshToken) error
+	// FindByTokenHash bloquea la fila hasta el fin de la transaccion para que dos
+	// refresh concurrentes con el mismo token no roten los dos
+	FindByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+	RevokeBySession(ctx context.Context, sessionID, tenantID string) error
+}
+
+type LoginAttemptsRepository interface {
+	// Find devuelve un contador vacio si el sujeto no tiene fallos registrados
+	Find(ctx context.Context, tenantID, subject string) (*LoginAttempts, error)
+	Save(ctx context.Context, attempts *LoginAttempts) error
+	Delete(ctx context.Context, tenantID, subject string) error
+}
+
+type MFAEnrollmentRepository interface {
+	Save(ctx context.Context, enrollment *MFAEnrollment) error
+	FindByUser(ctx context.Context, userID, tenantID string) (*MFAEnrollment, error)
+}
+
+type MFAChallengeRepository interface {
+	Save(ctx context.Context, challenge *MFAChallenge) error
+	FindByTokenHash(ctx context.Context, tokenHash string) (*MFAChallenge, error)
+}
+
+// SessionView es lo que se muestra en el listado de sesiones del usuario
+type SessionView struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent,omitempty"`
+	IPAddress string    `json:"ip_address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"`
+}
@@ -1,42 +1,138 @@
 package domain
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// ClientInfo identifica el dispositivo desde el que se abrio la sesion
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// maxUserAgentLength es el largo de sessions.user_agent; el header lo manda el
+// cliente y puede ser mas largo
+const maxUserAgentLength = 512
+
 type Session struct {
 	id        string
 	userID    string
 	tenantID  string
 	token     string
+	tokenHash string
+	client    ClientInfo
 	expiresAt time.Time
 	createdAt time.Time
+	revokedAt *time.Time
 }
 
-func NewSession(userID, tenantID string, duration time.Duration) *Session {
+func NewSession(userID, tenantID string, duration time.Duration, client ClientInfo) *Session {
 	now := time.Now().UTC()
+	token := generateToken()
+	if runes := []rune(client.UserAgent); len(runes) > maxUserAgentLength {
+		client.UserAgent = string(runes[:maxUserAgentLength])
+	}
 	return &Session{
 		id:        uuid.New().String(),
 		userID:    userID,
 		tenantID:  tenantID,
-		token:     generateToken(), 
+		token:     token,
+		tokenHash: HashToken(token),
+		client:    client,
 		expiresAt: now.Add(duration),
 		createdAt: now,
 	}
 }
 
+// ReconstituteSession arma la sesion desde la base. El token en claro no se
+// guarda, solo su hash
+func ReconstituteSession(id, userID, tenantID, tokenHash string, client ClientInfo, expiresAt, createdAt time.Time, revokedAt *time.Time) *Session {
+	return &Session{
+		id:        id,
+		userID:    userID,
+		tenantID:  tenantID,
+		tokenHash: tokenHash,
+		client:    client,
+		expiresAt: expiresAt,
+		createdAt: createdAt,
+		revokedAt: revokedAt,
+	}
+}
+
 func (s *Session) IsExpired() bool {
 	return time.Now().UTC().After(s.expiresAt)
 }
 
-func (s *Session) ID() string           { return s.id }
-func (s *Session) UserID() string       { return s.userID }
-func (s *Session) TenantID() string     { return s.tenantID }
-func (s *Session) Token() string        { return s.token }
-func (s *Session) ExpiresAt() time.Time { return s.expiresAt }
+func (s *Session) IsRevoked() bool {
+	return s.revokedAt != nil
+}
+
+// IsActive: ni vencida ni revocada
+func (s *Session) IsActive() bool {
+	return !s.IsExpired() && !s.IsRevoked()
+}
+
+// Revoke invalida la sesion. Revocar dos veces no cambia la fecha original
+func (s *Session) Revoke() error {
+	if s.IsRevoked() {
+		return ErrSessionRevoked
+	}
+	now := time.Now().UTC()
+	s.revokedAt = &now
+	return nil
+}
+
+func (s *Session) ID() string            { return s.id }
+func (s *Session) UserID() string        { return s.userID }
+func (s *Session) TenantID() string      { return s.tenantID }
+func (s *Session) Token() string         { return s.token }
+func (s *Session) TokenHash() string     { return s.tokenHash }
+func (s *Session) Client() ClientInfo    { return s.client }
+func (s *Session) ExpiresAt() time.Time  { return s.expiresAt }
+func (s *Session) CreatedAt() time.Time  { return s.createdAt }
+func (s *Session) RevokedAt() *time.Time { return s.revokedAt }
+
+// Renew extiende la sesion con un access token nuevo, el anterior deja de servir
+func (s *Session) Renew(duration time.Duration, issuer TokenIssuer) error {
+	if s.IsRevoked() {
+		return ErrSessionRevoked
+	}
+
+	s.expiresAt = time.Now().UTC().Add(duration)
+	return s.IssueToken(issuer)
+}
+
+// IssueToken reemplaza el token opaco por uno emitido por issuer (por ejemplo un
+// JWT firmado). iat es el momento de emision, no el del login: al renovar la
+// sesion el token es nuevo
+func (s *Session) IssueToken(issuer TokenIssuer) error {
+	token, err := issuer.Issue(AccessClaims{
+		SessionID: s.id,
+		UserID:    s.userID,
+		TenantID:  s.tenantID,
+		IssuedAt:  time.Now().UTC(),
+		ExpiresAt: s.expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.token = token
+	s.tokenHash = HashToken(token)
+	return nil
+}
+
+// HashToken es lo que se persiste y se usa para buscar la sesion, asi un dump
+// de la tabla no sirve para autenticarse
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
 func generateToken() string {
 	return uuid.New().String()
-}
\ No newline at end of file
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSession_TruncatesUserAgent(t *testing.T) {
+	// cuenta caracteres, como el VARCHAR de la columna, sin cortar uno al medio
+	userAgent := strings.Repeat("ñ", maxUserAgentLength+10)
+
+	session := NewSession("user-1", "tenant-1", time.Hour, ClientInfo{UserAgent: userAgent, IPAddress: "10.0.0.1"})
+
+	assert.Equal(t, strings.Repeat("ñ", maxUserAgentLength), session.Client().UserAgent)
+	assert.Equal(t, "10.0.0.1", session.Client().IPAddress)
+}
+
+func TestNewSession_KeepsShortUserAgent(t *testing.T) {
+	session := NewSession("user-1", "tenant-1", time.Hour, ClientInfo{UserAgent: "curl/8.0"})
+
+	assert.Equal(t, "curl/8.0", session.Client().UserAgent)
+}
+
+// capturingIssuer guarda los claims con los que se emitio el ultimo token
+type capturingIssuer struct {
+	claims AccessClaims
+}
+
+func (i *capturingIssuer) Issue(claims AccessClaims) (string, error) {
+	i.claims = claims
+	return "token", nil
+}
+
+// al renovar una sesion vieja el token nuevo lleva iat de ahora, no del login
+func TestSession_RenewIssuesTokenWithCurrentIssuedAt(t *testing.T) {
+	loggedInAt := time.Now().UTC().Add(-72 * time.Hour)
+	session := ReconstituteSession("session-1", "user-1", "tenant-1", "hash", ClientInfo{}, time.Now().UTC().Add(-time.Hour), loggedInAt, nil)
+	issuer := &capturingIssuer{}
+
+	assert.NoError(t, session.Renew(15*time.Minute, issuer))
+
+	assert.WithinDuration(t, time.Now(), issuer.claims.IssuedAt, time.Second)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), issuer.claims.ExpiresAt, time.Second)
+	assert.Equal(t, loggedInAt, session.CreatedAt())
+}
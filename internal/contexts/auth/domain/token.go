@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// AccessClaims es lo que viaja dentro de un access token autocontenido
+type AccessClaims struct {
+	SessionID string
+	UserID    string
+	TenantID  string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// TokenIssuer genera el access token de una sesion
+type TokenIssuer interface {
+	Issue(claims AccessClaims) (string, error)
+}
+
+// OpaqueTokenIssuer emite tokens random sin informacion adentro. Solo se
+// pueden validar contra la tabla de sesiones
+type OpaqueTokenIssuer struct{}
+
+func (OpaqueTokenIssuer) Issue(claims AccessClaims) (string, error) {
+	return generateToken(), nil
+}
@@ -1,21 +1,45 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	
 	"backend-challenge-guinea/internal/contexts/auth/application/commands"
+	"backend-challenge-guinea/internal/contexts/auth/application/queries"
+	"backend-challenge-guinea/internal/contexts/auth/domain"
 	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
 )
 
+// permissionAccountsUnlock es el permiso que define el rol en el contexto de users
+const permissionAccountsUnlock = "accounts:unlock"
+
 type AuthHandlers struct {
-	authenticateHandler *commands.AuthenticateCommandHandler
+	authenticateHandler        *commands.AuthenticateCommandHandler
+	refreshTokenHandler        *commands.RefreshTokenCommandHandler
+	unlockAccountHandler       *commands.UnlockAccountCommandHandler
+	revokeSessionHandler       *commands.RevokeSessionCommandHandler
+	revokeOtherSessionsHandler *commands.RevokeOtherSessionsCommandHandler
+	listSessionsHandler        *queries.ListSessionsQueryHandler
 }
 
-func NewAuthHandlers(authenticateHandler *commands.AuthenticateCommandHandler) *AuthHandlers {
+func NewAuthHandlers(
+	authenticateHandler *commands.AuthenticateCommandHandler,
+	refreshTokenHandler *commands.RefreshTokenCommandHandler,
+	unlockAccountHandler *commands.UnlockAccountCommandHandler,
+	revokeSessionHandler *commands.RevokeSessionCommandHandler,
+	revokeOtherSessionsHandler *commands.RevokeOtherSessionsCommandHandler,
+	listSessionsHandler *queries.ListSessionsQueryHandler,
+) *AuthHandlers {
 	return &AuthHandlers{
-		authenticateHandler: authenticateHandler,
+		authenticateHandler:        authenticateHandler,
+		refreshTokenHandler:        refreshTokenHandler,
+		unlockAccountHandler:       unlockAccountHandler,
+		revokeSessionHandler:       revokeSessionHandler,
+		revokeOtherSessionsHandler: revokeOtherSessionsHandler,
+		listSessionsHandler:        listSessionsHandler,
 	}
 }
 
@@ -37,13 +61,35 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	tenantID := middleware.GetTenantID(c)
 
 	cmd := commands.AuthenticateCommand{
-		Email:    req.Email,
-		Password: req.Password,
-		TenantID: tenantID,
+		Email:         req.Email,
+		Password:      req.Password,
+		TenantID:      tenantID,
+		UserAgent:     c.Request.UserAgent(),
+		IPAddress:     c.ClientIP(),
+		CorrelationID: middleware.GetCorrelationID(c),
 	}
 
 	response, err := h.authenticateHandler.Handle(c.Request.Context(), cmd)
 	if err != nil {
+		if errors.Is(err, domain.ErrAccountLocked) {
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "too many failed login attempts, try again later",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrAccountDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "account disabled",
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrEmailNotVerified) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "email not verified",
+			})
+			return
+		}
+
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error": "invalid credentials",
 		})
@@ -53,10 +99,185 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-func (h *AuthHandlers) RegisterRoutes(router *gin.Engine) {
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// rota el refresh token y devuelve un access token nuevo
+func (h *AuthHandlers) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.RefreshTokenCommand{
+		RefreshToken:  req.RefreshToken,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	response, err := h.refreshTokenHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRefreshToken) || errors.Is(err, domain.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid refresh token",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// cierra la sesion del token con el que vino la request
+func (h *AuthHandlers) Logout(c *gin.Context) {
+	cmd := commands.RevokeSessionCommand{
+		SessionID:     middleware.GetSessionID(c),
+		UserID:        middleware.GetUserID(c),
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+		Reason:        domain.RevokeReasonLogout,
+	}
+
+	if err := h.revokeSessionHandler.Handle(c.Request.Context(), cmd); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandlers) ListSessions(c *gin.Context) {
+	query := queries.ListSessionsQuery{
+		UserID:           middleware.GetUserID(c),
+		TenantID:         middleware.GetTenantID(c),
+		CurrentSessionID: middleware.GetSessionID(c),
+	}
+
+	sessions, err := h.listSessionsHandler.Handle(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": sessions,
+	})
+}
+
+func (h *AuthHandlers) RevokeSession(c *gin.Context) {
+	// el id es un UUID en la base: otra cosa no puede ser una sesion
+	sessionID := c.Param("id")
+	if _, err := uuid.Parse(sessionID); err != nil {
+		respondSessionError(c, domain.ErrSessionNotFound)
+		return
+	}
+
+	cmd := commands.RevokeSessionCommand{
+		SessionID:     sessionID,
+		UserID:        middleware.GetUserID(c),
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+		Reason:        domain.RevokeReasonUserRevoked,
+	}
+
+	if err := h.revokeSessionHandler.Handle(c.Request.Context(), cmd); err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AuthHandlers) RevokeOtherSessions(c *gin.Context) {
+	cmd := commands.RevokeOtherSessionsCommand{
+		UserID:           middleware.GetUserID(c),
+		TenantID:         middleware.GetTenantID(c),
+		CurrentSessionID: middleware.GetSessionID(c),
+		CorrelationID:    middleware.GetCorrelationID(c),
+	}
+
+	revoked, err := h.revokeOtherSessionsHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revoked": revoked,
+	})
+}
+
+type UnlockAccountRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// desbloquea un email bloqueado por logins fallidos
+func (h *AuthHandlers) UnlockAccount(c *gin.Context) {
+	var req UnlockAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.UnlockAccountCommand{
+		Email:    req.Email,
+		TenantID: middleware.GetTenantID(c),
+	}
+
+	if err := h.unlockAccountHandler.Handle(c.Request.Context(), cmd); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "session not found",
+		})
+	case errors.Is(err, domain.ErrSessionRevoked):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "session already revoked",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+// las sesiones son siempre las propias; desbloquear otra cuenta pide accounts:unlock
+func (h *AuthHandlers) RegisterRoutes(router *gin.Engine, tenantMiddleware gin.HandlerFunc, authMiddleware gin.HandlerFunc, permissions middleware.PermissionChecker) {
 	auth := router.Group("/api/v1/auth")
 	
-	auth.Use(middleware.TenantMiddleware())
+	auth.Use(tenantMiddleware)
+	auth.Use(middleware.CorrelationIDMiddleware())
 	
 	auth.POST("/login", h.Login)
-}
\ No newline at end of file
+	auth.POST("/refresh", h.Refresh)
+
+	// rutas que necesitan una sesion valida
+	auth.POST("/logout", authMiddleware, h.Logout)
+	auth.GET("/sessions", authMiddleware, h.ListSessions)
+	auth.DELETE("/sessions/:id", authMiddleware, h.RevokeSession)
+	auth.POST("/sessions/revoke-others", authMiddleware, h.RevokeOtherSessions)
+	auth.POST("/unlock", authMiddleware, middleware.RequirePermission(permissions, permissionAccountsUnlock), h.UnlockAccount)
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"backend-challenge-guinea/internal/contexts/auth/infrastructure/jwt"
+)
+
+// JWKSHandlers expone las claves publicas para que otros servicios validen
+// los access tokens sin llamarnos
+type JWKSHandlers struct {
+	keys *jwt.KeySet
+}
+
+func NewJWKSHandlers(keys *jwt.KeySet) *JWKSHandlers {
+	return &JWKSHandlers{keys: keys}
+}
+
+func (h *JWKSHandlers) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
+
+func (h *JWKSHandlers) RegisterRoutes(router *gin.Engine) {
+	router.GET("/.well-known/jwks.json", h.JWKS)
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"backend-challenge-guinea/internal/contexts/auth/application/commands"
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+type MFAHandlers struct {
+	enrollHandler  *commands.EnrollMFACommandHandler
+	confirmHandler *commands.ConfirmMFACommandHandler
+	verifyHandler  *commands.VerifyMFACommandHandler
+}
+
+func NewMFAHandlers(
+	enrollHandler *commands.EnrollMFACommandHandler,
+	confirmHandler *commands.ConfirmMFACommandHandler,
+	verifyHandler *commands.VerifyMFACommandHandler,
+) *MFAHandlers {
+	return &MFAHandlers{
+		enrollHandler:  enrollHandler,
+		confirmHandler: confirmHandler,
+		verifyHandler:  verifyHandler,
+	}
+}
+
+// genera el secreto TOTP para el usuario logueado
+func (h *MFAHandlers) Enroll(c *gin.Context) {
+	cmd := commands.EnrollMFACommand{
+		UserID:   middleware.GetUserID(c),
+		TenantID: middleware.GetTenantID(c),
+	}
+
+	h.enroll(c, cmd)
+}
+
+type EnrollWithChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// enrolamiento en medio del login, cuando el tenant exige MFA y el usuario no lo tiene
+func (h *MFAHandlers) EnrollWithChallenge(c *gin.Context) {
+	var req EnrollWithChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.EnrollMFACommand{
+		ChallengeToken: req.ChallengeToken,
+		TenantID:       middleware.GetTenantID(c),
+	}
+
+	h.enroll(c, cmd)
+}
+
+func (h *MFAHandlers) enroll(c *gin.Context, cmd commands.EnrollMFACommand) {
+	response, err := h.enrollHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type ConfirmMFARequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// activa el MFA con el primer codigo, devuelve los recovery codes una sola vez
+func (h *MFAHandlers) Confirm(c *gin.Context) {
+	var req ConfirmMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.ConfirmMFACommand{
+		UserID:   middleware.GetUserID(c),
+		TenantID: middleware.GetTenantID(c),
+		Code:     req.Code,
+	}
+
+	response, err := h.confirmHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+type VerifyMFARequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required_without=RecoveryCode"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// segundo paso del login
+func (h *MFAHandlers) Verify(c *gin.Context) {
+	var req VerifyMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.VerifyMFACommand{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		RecoveryCode:   req.RecoveryCode,
+		TenantID:       middleware.GetTenantID(c),
+		UserAgent:      c.Request.UserAgent(),
+		IPAddress:      c.ClientIP(),
+		CorrelationID:  middleware.GetCorrelationID(c),
+	}
+
+	response, err := h.verifyHandler.Handle(c.Request.Context(), cmd)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func respondMFAError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid mfa code",
+		})
+	case errors.Is(err, domain.ErrInvalidMFAChallenge):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "invalid or expired mfa challenge",
+		})
+	case errors.Is(err, domain.ErrAccountLocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "too many failed login attempts, try again later",
+		})
+	case errors.Is(err, domain.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "account disabled",
+		})
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "mfa already enabled",
+		})
+	case errors.Is(err, domain.ErrMFANotEnrolled):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "mfa not enrolled",
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+func (h *MFAHandlers) RegisterRoutes(router *gin.Engine, tenantMiddleware gin.HandlerFunc, authMiddleware gin.HandlerFunc) {
+	mfa := router.Group("/api/v1/auth/mfa")
+
+	mfa.Use(tenantMiddleware)
+	mfa.Use(middleware.CorrelationIDMiddleware())
+
+	mfa.POST("/verify", h.Verify)
+	mfa.POST("/challenge/enroll", h.EnrollWithChallenge)
+
+	mfa.POST("/enroll", authMiddleware, h.Enroll)
+	mfa.POST("/confirm", authMiddleware, h.Confirm)
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/auth/application/queries"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+// SessionTokenResolver adapta la validacion de sesiones al middleware de auth
+type SessionTokenResolver struct {
+	validateSessionHandler *queries.ValidateSessionQueryHandler
+}
+
+func NewSessionTokenResolver(validateSessionHandler *queries.ValidateSessionQueryHandler) *SessionTokenResolver {
+	return &SessionTokenResolver{
+		validateSessionHandler: validateSessionHandler,
+	}
+}
+
+func (r *SessionTokenResolver) Resolve(ctx context.Context, token string) (*middleware.Principal, error) {
+	session, err := r.validateSessionHandler.Handle(ctx, queries.ValidateSessionQuery{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	return &middleware.Principal{
+		UserID:    session.UserID(),
+		TenantID:  session.TenantID(),
+		SessionID: session.ID(),
+	}, nil
+}
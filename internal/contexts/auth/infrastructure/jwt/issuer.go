@@ -0,0 +1,167 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+var (
+	ErrMalformedToken   = errors.New("malformed token")
+	ErrInvalidSignature = errors.New("invalid token signature")
+	ErrTokenExpired     = errors.New("token expired")
+)
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// Claims son los claims registrados mas los propios (tenant_id, sid)
+type Claims struct {
+	ID        string `json:"jti"`
+	Issuer    string `json:"iss,omitempty"`
+	Subject   string `json:"sub"`
+	TenantID  string `json:"tenant_id"`
+	SessionID string `json:"sid"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Issuer firma access tokens JWT con la clave de firma del keyset.
+// Implementa domain.TokenIssuer
+type Issuer struct {
+	keys   *KeySet
+	issuer string
+}
+
+func NewIssuer(keys *KeySet, issuer string) *Issuer {
+	return &Issuer{
+		keys:   keys,
+		issuer: issuer,
+	}
+}
+
+func (i *Issuer) Issue(claims domain.AccessClaims) (string, error) {
+	key := i.keys.SigningKey()
+
+	headerJSON, err := json.Marshal(header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+
+	claimsJSON, err := json.Marshal(Claims{
+		ID:        uuid.New().String(),
+		Issuer:    i.issuer,
+		Subject:   claims.UserID,
+		TenantID:  claims.TenantID,
+		SessionID: claims.SessionID,
+		IssuedAt:  claims.IssuedAt.Unix(),
+		ExpiresAt: claims.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodeSegment(headerJSON) + "." + encodeSegment(claimsJSON)
+
+	signature, err := sign(key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + encodeSegment(signature), nil
+}
+
+// Verify chequea firma y vencimiento con cualquier clave del keyset
+func (i *Issuer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	key, err := i.keys.Key(h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	// el alg lo define la clave, no el header (evita ataques de alg confusion)
+	if h.Algorithm != key.Algorithm {
+		return nil, ErrInvalidSignature
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if !verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+func sign(key Key, input []byte) ([]byte, error) {
+	if key.signer == nil {
+		return nil, ErrSigningKeyPublic
+	}
+
+	switch key.Algorithm {
+	case AlgorithmRS256:
+		digest := sha256.Sum256(input)
+		return key.signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgorithmEdDSA:
+		return key.signer.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+func verify(key Key, input, signature []byte) bool {
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(public, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(public, input, signature)
+	}
+	return false
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,113 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+)
+
+func newClaims(ttl time.Duration) domain.AccessClaims {
+	now := time.Now().UTC()
+	return domain.AccessClaims{
+		SessionID: "session-1",
+		UserID:    "user-1",
+		TenantID:  "tenant-1",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+}
+
+func TestIssuer_IssueAndVerify(t *testing.T) {
+	for _, algorithm := range []string{AlgorithmRS256, AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			key, err := GenerateKey("key-1", algorithm)
+			require.NoError(t, err)
+			keys, err := NewKeySet("key-1", key)
+			require.NoError(t, err)
+
+			issuer := NewIssuer(keys, "test")
+
+			token, err := issuer.Issue(newClaims(time.Hour))
+			require.NoError(t, err)
+
+			claims, err := issuer.Verify(token)
+			require.NoError(t, err)
+			assert.Equal(t, "user-1", claims.Subject)
+			assert.Equal(t, "tenant-1", claims.TenantID)
+			assert.Equal(t, "session-1", claims.SessionID)
+			assert.Equal(t, "test", claims.Issuer)
+		})
+	}
+}
+
+func TestIssuer_Expired(t *testing.T) {
+	key, _ := GenerateKey("key-1", AlgorithmEdDSA)
+	keys, _ := NewKeySet("key-1", key)
+	issuer := NewIssuer(keys, "test")
+
+	token, err := issuer.Issue(newClaims(-time.Minute))
+	require.NoError(t, err)
+
+	_, err = issuer.Verify(token)
+	assert.Equal(t, ErrTokenExpired, err)
+}
+
+func TestIssuer_TamperedToken(t *testing.T) {
+	key, _ := GenerateKey("key-1", AlgorithmEdDSA)
+	keys, _ := NewKeySet("key-1", key)
+	issuer := NewIssuer(keys, "test")
+
+	token, _ := issuer.Issue(newClaims(time.Hour))
+	other, _ := issuer.Issue(domain.AccessClaims{UserID: "admin", TenantID: "tenant-1", ExpiresAt: time.Now().Add(time.Hour)})
+
+	// payload de otro token con la firma del primero
+	parts := strings.Split(token, ".")
+	otherParts := strings.Split(other, ".")
+	forged := parts[0] + "." + otherParts[1] + "." + parts[2]
+
+	_, err := issuer.Verify(forged)
+	assert.Equal(t, ErrInvalidSignature, err)
+}
+
+// los tokens firmados con la clave anterior siguen siendo validos despues de rotar
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, _ := GenerateKey("2025-01", AlgorithmRS256)
+	newKey, _ := GenerateKey("2025-02", AlgorithmEdDSA)
+
+	before, _ := NewKeySet("2025-01", oldKey)
+	oldToken, err := NewIssuer(before, "test").Issue(newClaims(time.Hour))
+	require.NoError(t, err)
+
+	after, err := NewKeySet("2025-02", oldKey, newKey)
+	require.NoError(t, err)
+	issuer := NewIssuer(after, "test")
+
+	_, err = issuer.Verify(oldToken)
+	assert.NoError(t, err)
+
+	newToken, _ := issuer.Issue(newClaims(time.Hour))
+	assert.Contains(t, decodeHeader(t, newToken), `"kid":"2025-02"`)
+
+	jwks := after.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+}
+
+func TestNewKeySet_SigningKeyMissing(t *testing.T) {
+	key, _ := GenerateKey("key-1", AlgorithmEdDSA)
+
+	_, err := NewKeySet("key-2", key)
+	assert.Equal(t, ErrNoSigningKey, err)
+}
+
+func decodeHeader(t *testing.T, token string) string {
+	var h header
+	require.NoError(t, decodeSegment(strings.Split(token, ".")[0], &h))
+	return `"kid":"` + h.KeyID + `"`
+}
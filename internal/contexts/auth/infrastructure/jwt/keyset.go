@@ -0,0 +1,178 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+var (
+	ErrUnknownKey       = errors.New("unknown signing key")
+	ErrUnsupportedKey   = errors.New("unsupported key type")
+	ErrNoSigningKey     = errors.New("signing key not found in keyset")
+	ErrSigningKeyPublic = errors.New("signing key has no private part")
+)
+
+// Key es una clave del keyset. Las claves solo de verificacion no tienen signer
+type Key struct {
+	ID        string
+	Algorithm string
+	Public    crypto.PublicKey
+	signer    crypto.Signer
+}
+
+// KeySet tiene una clave para firmar y todas las que se aceptan al verificar.
+// Para rotar se agrega la clave nueva, se cambia la de firma y la vieja se deja
+// hasta que vencen los tokens que firmo
+type KeySet struct {
+	signingKeyID string
+	keys         map[string]Key
+}
+
+func NewKeySet(signingKeyID string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{
+		signingKeyID: signingKeyID,
+		keys:         make(map[string]Key),
+	}
+	for _, key := range keys {
+		ks.keys[key.ID] = key
+	}
+
+	signing, ok := ks.keys[signingKeyID]
+	if !ok {
+		return nil, ErrNoSigningKey
+	}
+	if signing.signer == nil {
+		return nil, ErrSigningKeyPublic
+	}
+
+	return ks, nil
+}
+
+// LoadKeySet lee todos los .pem de dir. El nombre del archivo (sin extension)
+// es el kid. Una clave privada (PKCS8) sirve para firmar y verificar, una
+// publica (PKIX) solo para verificar
+func LoadKeySet(dir, signingKeyID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key %s: %w", path, err)
+		}
+
+		kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		key, err := ParsePEM(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse key %s: %w", path, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(signingKeyID, keys...)
+}
+
+// ParsePEM arma una Key desde un bloque PEM con una clave RSA o Ed25519
+func ParsePEM(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, errors.New("invalid PEM data")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return Key{}, ErrUnsupportedKey
+		}
+		return newKey(kid, signer.Public(), signer)
+	case "PUBLIC KEY":
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(kid, parsed, nil)
+	}
+
+	return Key{}, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
+
+// GenerateKey crea una clave en memoria. Sirve para desarrollo y tests, en
+// produccion las claves se cargan con LoadKeySet
+func GenerateKey(kid, algorithm string) (Key, error) {
+	switch algorithm {
+	case AlgorithmRS256:
+		private, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(kid, &private.PublicKey, private)
+	case AlgorithmEdDSA:
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return Key{}, err
+		}
+		return newKey(kid, public, private)
+	}
+
+	return Key{}, fmt.Errorf("unsupported algorithm %q", algorithm)
+}
+
+func newKey(kid string, public crypto.PublicKey, signer crypto.Signer) (Key, error) {
+	key := Key{ID: kid, Public: public, signer: signer}
+
+	switch public.(type) {
+	case *rsa.PublicKey:
+		key.Algorithm = AlgorithmRS256
+	case ed25519.PublicKey:
+		key.Algorithm = AlgorithmEdDSA
+	default:
+		return Key{}, ErrUnsupportedKey
+	}
+
+	return key, nil
+}
+
+func (ks *KeySet) SigningKey() Key {
+	return ks.keys[ks.signingKeyID]
+}
+
+func (ks *KeySet) Key(kid string) (Key, error) {
+	key, ok := ks.keys[kid]
+	if !ok {
+		return Key{}, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// Keys devuelve todas las claves de verificacion ordenadas por kid
+func (ks *KeySet) Keys() []Key {
+	keys := make([]Key, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
@@ -0,0 +1,77 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresLoginAttemptsRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresLoginAttemptsRepository(db *sql.DB) *PostgresLoginAttemptsRepository {
+	return &PostgresLoginAttemptsRepository{db: db}
+}
+
+func (r *PostgresLoginAttemptsRepository) Find(ctx context.Context, tenantID, subject string) (*domain.LoginAttempts, error) {
+	query := `
+		SELECT failures, last_failure_at, locked_until
+		FROM login_attempts
+		WHERE tenant_id = $1 AND subject = $2
+		FOR UPDATE
+	`
+
+	var (
+		failures      int
+		lastFailureAt time.Time
+		lockedUntil   *time.Time
+	)
+
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tenantID, subject).Scan(
+		&failures, &lastFailureAt, &lockedUntil,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NewLoginAttempts(tenantID, subject), nil
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteLoginAttempts(tenantID, subject, failures, lastFailureAt.UTC(), lockedUntil), nil
+}
+
+func (r *PostgresLoginAttemptsRepository) Save(ctx context.Context, attempts *domain.LoginAttempts) error {
+	query := `
+		INSERT INTO login_attempts (tenant_id, subject, failures, last_failure_at, locked_until)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, subject) DO UPDATE SET
+			failures = EXCLUDED.failures,
+			last_failure_at = EXCLUDED.last_failure_at,
+			locked_until = EXCLUDED.locked_until
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		attempts.TenantID(),
+		attempts.Subject(),
+		attempts.Failures(),
+		attempts.LastFailureAt(),
+		attempts.LockedUntil(),
+	)
+
+	return err
+}
+
+func (r *PostgresLoginAttemptsRepository) Delete(ctx context.Context, tenantID, subject string) error {
+	query := `DELETE FROM login_attempts WHERE tenant_id = $1 AND subject = $2`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query, tenantID, subject)
+	return err
+}
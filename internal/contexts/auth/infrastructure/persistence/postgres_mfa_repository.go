@@ -0,0 +1,221 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresMFAEnrollmentRepository guarda el secreto TOTP cifrado, atado al
+// tenant y usuario de la fila
+type PostgresMFAEnrollmentRepository struct {
+	db     *sql.DB
+	cipher *SecretCipher
+}
+
+func NewPostgresMFAEnrollmentRepository(db *sql.DB, cipher *SecretCipher) *PostgresMFAEnrollmentRepository {
+	return &PostgresMFAEnrollmentRepository{db: db, cipher: cipher}
+}
+
+func (r *PostgresMFAEnrollmentRepository) Save(ctx context.Context, enrollment *domain.MFAEnrollment) error {
+	query := `
+		INSERT INTO mfa_enrollments (user_id, tenant_id, secret, confirmed_at, last_used_step, recovery_code_hashes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			confirmed_at = EXCLUDED.confirmed_at,
+			last_used_step = EXCLUDED.last_used_step,
+			recovery_code_hashes = EXCLUDED.recovery_code_hashes,
+			created_at = EXCLUDED.created_at
+	`
+
+	hashes := enrollment.RecoveryCodeHashes()
+	if hashes == nil {
+		hashes = []string{}
+	}
+
+	secret, err := r.cipher.Seal(enrollment.Secret(), secretAssociatedData(enrollment.TenantID(), enrollment.UserID()))
+	if err != nil {
+		return err
+	}
+
+	_, err = persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		enrollment.UserID(),
+		enrollment.TenantID(),
+		secret,
+		enrollment.ConfirmedAt(),
+		enrollment.LastUsedStep(),
+		pq.Array(hashes),
+		enrollment.CreatedAt(),
+	)
+
+	return err
+}
+
+// FindByUser bloquea la fila para que dos verificaciones concurrentes no usen el mismo codigo
+func (r *PostgresMFAEnrollmentRepository) FindByUser(ctx context.Context, userID, tenantID string) (*domain.MFAEnrollment, error) {
+	query := `
+		SELECT secret, confirmed_at, last_used_step, recovery_code_hashes, created_at
+		FROM mfa_enrollments
+		WHERE user_id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`
+
+	var (
+		secret       string
+		confirmedAt  *time.Time
+		lastUsedStep int64
+		hashes       []string
+		createdAt    time.Time
+	)
+
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, userID, tenantID).Scan(
+		&secret, &confirmedAt, &lastUsedStep, pq.Array(&hashes), &createdAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	secret, err = r.cipher.Open(secret, secretAssociatedData(tenantID, userID))
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.ReconstituteMFAEnrollment(userID, tenantID, secret, confirmedAt, lastUsedStep, hashes, createdAt.UTC()), nil
+}
+
+// EncryptPlaintextSecrets cifra los secretos guardados en claro antes de que
+// se cifraran. Corre al arrancar la API; devuelve cuantos cifro
+func (r *PostgresMFAEnrollmentRepository) EncryptPlaintextSecrets(ctx context.Context) (int, error) {
+	executor := persistence.GetExecutor(ctx, r.db)
+
+	rows, err := executor.QueryContext(ctx, `
+		SELECT user_id, tenant_id, secret
+		FROM mfa_enrollments
+		WHERE secret NOT LIKE $1 || '%'
+	`, sealedSecretPrefix)
+	if err != nil {
+		return 0, err
+	}
+
+	type plaintextSecret struct{ userID, tenantID, secret string }
+	var pending []plaintextSecret
+	for rows.Next() {
+		var row plaintextSecret
+		if err := rows.Scan(&row.userID, &row.tenantID, &row.secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	encrypted := 0
+	for _, row := range pending {
+		sealed, err := r.cipher.Seal(row.secret, secretAssociatedData(row.tenantID, row.userID))
+		if err != nil {
+			return encrypted, err
+		}
+
+		// si otro proceso la cambio mientras tanto no se pisa
+		result, err := executor.ExecContext(ctx, `
+			UPDATE mfa_enrollments SET secret = $3
+			WHERE tenant_id = $1 AND user_id = $2 AND secret = $4
+		`, row.tenantID, row.userID, sealed, row.secret)
+		if err != nil {
+			return encrypted, err
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			encrypted++
+		}
+	}
+
+	return encrypted, nil
+}
+
+func secretAssociatedData(tenantID, userID string) string {
+	return tenantID + "/" + userID
+}
+
+type PostgresMFAChallengeRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresMFAChallengeRepository(db *sql.DB) *PostgresMFAChallengeRepository {
+	return &PostgresMFAChallengeRepository{db: db}
+}
+
+func (r *PostgresMFAChallengeRepository) Save(ctx context.Context, challenge *domain.MFAChallenge) error {
+	query := `
+		INSERT INTO mfa_challenges (id, user_id, tenant_id, token_hash, enrollment_required, attempts, created_at, expires_at, consumed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			attempts = EXCLUDED.attempts,
+			consumed_at = EXCLUDED.consumed_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		challenge.ID(),
+		challenge.UserID(),
+		challenge.TenantID(),
+		challenge.TokenHash(),
+		challenge.EnrollmentRequired(),
+		challenge.Attempts(),
+		challenge.CreatedAt(),
+		challenge.ExpiresAt(),
+		challenge.ConsumedAt(),
+	)
+
+	return err
+}
+
+func (r *PostgresMFAChallengeRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.MFAChallenge, error) {
+	query := `
+		SELECT id, user_id, tenant_id, token_hash, enrollment_required, attempts, expires_at, created_at, consumed_at
+		FROM mfa_challenges
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	var (
+		id                 string
+		userID             string
+		tenantID           string
+		hash               string
+		enrollmentRequired bool
+		attempts           int
+		expiresAt          time.Time
+		createdAt          time.Time
+		consumedAt         *time.Time
+	)
+
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&id, &userID, &tenantID, &hash, &enrollmentRequired, &attempts, &expiresAt, &createdAt, &consumedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteMFAChallenge(id, userID, tenantID, hash, enrollmentRequired, attempts, expiresAt.UTC(), createdAt.UTC(), consumedAt), nil
+}
@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+func (r *PostgresRefreshTokenRepository) Save(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, session_id, user_id, tenant_id, token_hash, created_at, expires_at, used_at, replaced_by, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, '')::uuid, $10)
+		ON CONFLICT (id) DO UPDATE SET
+			used_at = EXCLUDED.used_at,
+			replaced_by = EXCLUDED.replaced_by,
+			revoked_at = EXCLUDED.revoked_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		token.ID(),
+		token.SessionID(),
+		token.UserID(),
+		token.TenantID(),
+		token.TokenHash(),
+		token.CreatedAt(),
+		token.ExpiresAt(),
+		token.UsedAt(),
+		token.ReplacedBy(),
+		token.RevokedAt(),
+	)
+
+	return err
+}
+
+func (r *PostgresRefreshTokenRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+		SELECT id, session_id, user_id, tenant_id, token_hash, expires_at, created_at, used_at, COALESCE(replaced_by::text, ''), revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+
+	var (
+		id         string
+		sessionID  string
+		userID     string
+		tenantID   string
+		hash       string
+		expiresAt  time.Time
+		createdAt  time.Time
+		usedAt     *time.Time
+		replacedBy string
+		revokedAt  *time.Time
+	)
+
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tokenHash).Scan(
+		&id, &sessionID, &userID, &tenantID, &hash, &expiresAt, &createdAt, &usedAt, &replacedBy, &revokedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteRefreshToken(id, sessionID, userID, tenantID, hash, expiresAt.UTC(), createdAt.UTC(), usedAt, replacedBy, revokedAt), nil
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeBySession(ctx context.Context, sessionID, tenantID string) error {
+	query := `
+		UPDATE refresh_tokens SET revoked_at = $3
+		WHERE session_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query, sessionID, tenantID, time.Now().UTC())
+	return err
+}
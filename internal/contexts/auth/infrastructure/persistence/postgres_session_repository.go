@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+const sessionColumns = `id, user_id, tenant_id, token_hash, COALESCE(user_agent, ''), COALESCE(ip_address, ''), expires_at, created_at, revoked_at`
+
+type PostgresSessionRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresSessionRepository(db *sql.DB) *PostgresSessionRepository {
+	return &PostgresSessionRepository{db: db}
+}
+
+func (r *PostgresSessionRepository) Save(ctx context.Context, session *domain.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, tenant_id, token_hash, user_agent, ip_address, created_at, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			token_hash = EXCLUDED.token_hash,
+			expires_at = EXCLUDED.expires_at,
+			revoked_at = EXCLUDED.revoked_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		session.ID(),
+		session.UserID(),
+		session.TenantID(),
+		session.TokenHash(),
+		session.Client().UserAgent,
+		session.Client().IPAddress,
+		session.CreatedAt(),
+		session.ExpiresAt(),
+		session.RevokedAt(),
+	)
+
+	return err
+}
+
+func (r *PostgresSessionRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE token_hash = $1`
+
+	return scanSession(persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tokenHash))
+}
+
+func (r *PostgresSessionRepository) FindByID(ctx context.Context, id, tenantID string) (*domain.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE id = $1 AND tenant_id = $2`
+
+	return scanSession(persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, id, tenantID))
+}
+
+func (r *PostgresSessionRepository) FindActiveByUser(ctx context.Context, userID, tenantID string) ([]*domain.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL AND expires_at > $3
+		ORDER BY created_at DESC
+	`
+
+	return r.findByUser(ctx, query, userID, tenantID, time.Now().UTC())
+}
+
+func (r *PostgresSessionRepository) FindUnrevokedByUser(ctx context.Context, userID, tenantID string) ([]*domain.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	return r.findByUser(ctx, query, userID, tenantID)
+}
+
+func (r *PostgresSessionRepository) findByUser(ctx context.Context, query string, args ...interface{}) ([]*domain.Session, error) {
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*domain.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (*domain.Session, error) {
+	var (
+		id        string
+		userID    string
+		tenantID  string
+		hash      string
+		client    domain.ClientInfo
+		expiresAt time.Time
+		createdAt time.Time
+		revokedAt *time.Time
+	)
+
+	err := row.Scan(&id, &userID, &tenantID, &hash, &client.UserAgent, &client.IPAddress, &expiresAt, &createdAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteSession(id, userID, tenantID, hash, client, expiresAt.UTC(), createdAt.UTC(), revokedAt), nil
+}
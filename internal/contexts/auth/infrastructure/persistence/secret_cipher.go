@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// prefijo de los secretos cifrados; lo que no lo tiene es un secreto guardado
+// en claro antes de que se cifraran
+const sealedSecretPrefix = "v1:"
+
+var errInvalidSealedSecret = errors.New("invalid sealed secret")
+
+// SecretCipher cifra con AES-256-GCM los secretos que hay que poder leer en
+// claro (el de TOTP). El dato asociado ata el cifrado a su fila: un secreto
+// copiado a otro usuario no descifra
+type SecretCipher struct {
+	aead cipher.AEAD
+}
+
+// NewSecretCipher recibe la clave en base64, tiene que ser de 32 bytes
+func NewSecretCipher(encodedKey string) (*SecretCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("secret key must be base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secret key must be 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecretCipher{aead: aead}, nil
+}
+
+func (c *SecretCipher) Seal(plain, associatedData string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(plain), []byte(associatedData))
+	return sealedSecretPrefix + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open descifra; un valor sin prefijo se devuelve tal cual
+func (c *SecretCipher) Open(stored, associatedData string) (string, error) {
+	if !isSealed(stored) {
+		return stored, nil
+	}
+
+	sealed, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(stored, sealedSecretPrefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", errInvalidSealedSecret
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, ciphertext, []byte(associatedData))
+	if err != nil {
+		return "", errInvalidSealedSecret
+	}
+	return string(plain), nil
+}
+
+func isSealed(stored string) bool {
+	return strings.HasPrefix(stored, sealedSecretPrefix)
+}
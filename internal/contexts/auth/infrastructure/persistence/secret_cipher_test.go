@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCipher(t *testing.T, key byte) *SecretCipher {
+	cipher, err := NewSecretCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(key), 32))))
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestSecretCipher_SealAndOpen(t *testing.T) {
+	cipher := newTestCipher(t, 'k')
+
+	sealed, err := cipher.Seal("JBSWY3DPEHPK3PXP", "tenant-1/user-1")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, sealedSecretPrefix))
+	assert.NotContains(t, sealed, "JBSWY3DPEHPK3PXP")
+
+	plain, err := cipher.Open(sealed, "tenant-1/user-1")
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+
+	// cada Seal usa otro nonce
+	again, _ := cipher.Seal("JBSWY3DPEHPK3PXP", "tenant-1/user-1")
+	assert.NotEqual(t, sealed, again)
+}
+
+func TestSecretCipher_OpenRejectsOtherRowOrKey(t *testing.T) {
+	cipher := newTestCipher(t, 'k')
+	sealed, _ := cipher.Seal("JBSWY3DPEHPK3PXP", "tenant-1/user-1")
+
+	// copiado a otro usuario no descifra
+	_, err := cipher.Open(sealed, "tenant-1/user-2")
+	assert.Error(t, err)
+
+	_, err = newTestCipher(t, 'x').Open(sealed, "tenant-1/user-1")
+	assert.Error(t, err)
+
+	_, err = cipher.Open(sealedSecretPrefix+"not-base64!", "tenant-1/user-1")
+	assert.Error(t, err)
+}
+
+// los secretos guardados antes de cifrar se leen tal cual
+func TestSecretCipher_OpenPlaintext(t *testing.T) {
+	plain, err := newTestCipher(t, 'k').Open("JBSWY3DPEHPK3PXP", "tenant-1/user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", plain)
+}
+
+func TestNewSecretCipher_InvalidKey(t *testing.T) {
+	_, err := NewSecretCipher("")
+	assert.Error(t, err)
+
+	_, err = NewSecretCipher("not base64")
+	assert.Error(t, err)
+
+	_, err = NewSecretCipher(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.Error(t, err)
+}
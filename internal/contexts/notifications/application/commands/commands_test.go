@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+type MockNotificationRepository struct {
+	mock.Mock
+}
+
+func (m *MockNotificationRepository) Add(ctx context.Context, notification *domain.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) Save(ctx context.Context, notification *domain.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.Notification, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]*domain.Notification), args.Error(1)
+}
+
+type MockTemplateRepository struct {
+	mock.Mock
+}
+
+func (m *MockTemplateRepository) Find(ctx context.Context, tenantID string, kind domain.Kind, locale string) (*domain.Template, error) {
+	args := m.Called(ctx, tenantID, kind, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Template), args.Error(1)
+}
+
+type MockSettingsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSettingsRepository) Find(ctx context.Context, tenantID string) (domain.Settings, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).(domain.Settings), args.Error(1)
+}
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, message domain.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+type MockTransactionManager struct{}
+
+func (m *MockTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, fields map[string]interface{})  {}
+func (nopLogger) Error(msg string, fields map[string]interface{}) {}
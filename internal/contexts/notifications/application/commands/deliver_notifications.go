@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/bus"
+)
+
+// deliveryLease es cuanto queda tomada una notificacion mientras se envia; si
+// el consumer muere en el medio otro la reintenta despues de este tiempo
+const deliveryLease = 5 * time.Minute
+
+// DeliverNotificationsCommandHandler manda las notificaciones pendientes. Lo
+// corre el consumer periodicamente, igual que la purga de usuarios
+type DeliverNotificationsCommandHandler struct {
+	repository domain.NotificationRepository
+	settings   domain.SettingsRepository
+	sender     domain.Sender
+	txManager  TransactionManager
+	retry      bus.RetryPolicy
+	batchSize  int
+	log        Logger
+}
+
+func NewDeliverNotificationsCommandHandler(
+	repo domain.NotificationRepository,
+	settings domain.SettingsRepository,
+	sender domain.Sender,
+	txManager TransactionManager,
+	retry bus.RetryPolicy,
+	batchSize int,
+	log Logger,
+) *DeliverNotificationsCommandHandler {
+	return &DeliverNotificationsCommandHandler{
+		repository: repo,
+		settings:   settings,
+		sender:     sender,
+		txManager:  txManager,
+		retry:      retry,
+		batchSize:  batchSize,
+		log:        log,
+	}
+}
+
+// Handle devuelve cuantas notificaciones se entregaron. El envio corre fuera
+// de transaccion: solo se toman las filas y despues se guarda cada resultado
+// por separado, asi un SMTP lento no deja una transaccion abierta
+func (h *DeliverNotificationsCommandHandler) Handle(ctx context.Context) (int, error) {
+	var notifications []*domain.Notification
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		notifications, err = h.repository.ClaimDue(ctx, h.batchSize, deliveryLease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, notification := range notifications {
+		if err := h.deliver(ctx, notification); err != nil {
+			h.log.Error("failed to deliver notification", map[string]interface{}{
+				"error":           err.Error(),
+				"notification_id": notification.ID(),
+				"kind":            string(notification.Kind()),
+				"attempt":         notification.Attempts() + 1,
+			})
+			notification.MarkFailed(err, h.retry.MaxAttempts, h.retry.Backoff(notification.Attempts()+1))
+		} else {
+			notification.MarkSent()
+			sent++
+		}
+
+		if err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+			return h.repository.Save(ctx, notification)
+		}); err != nil {
+			return sent, err
+		}
+	}
+
+	return sent, nil
+}
+
+func (h *DeliverNotificationsCommandHandler) deliver(ctx context.Context, notification *domain.Notification) error {
+	settings, err := h.settings.Find(ctx, notification.TenantID())
+	if err != nil {
+		return err
+	}
+
+	content := notification.Content()
+	return h.sender.Send(ctx, domain.Message{
+		FromAddress: settings.FromAddress,
+		FromName:    settings.FromName,
+		To:          notification.Recipient(),
+		Subject:     content.Subject,
+		Text:        content.Text,
+		HTML:        content.HTML,
+	})
+}
+
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
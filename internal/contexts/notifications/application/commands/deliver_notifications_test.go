@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/bus"
+)
+
+func TestDeliverNotificationsCommandHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockSettings := new(MockSettingsRepository)
+	mockSender := new(MockSender)
+	retry := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, Multiplier: 2}
+	handler := NewDeliverNotificationsCommandHandler(mockRepo, mockSettings, mockSender, &MockTransactionManager{}, retry, 10, nopLogger{})
+
+	ok := domain.NewNotification("event-1", "tenant-1", "user-1", domain.KindEmailVerification, "es", "ok@example.com", domain.Content{Subject: "Hola", Text: "link"})
+	failing := domain.NewNotification("event-2", "tenant-1", "user-2", domain.KindEmailVerification, "es", "down@example.com", domain.Content{Subject: "Hola", Text: "link"})
+
+	mockRepo.On("ClaimDue", ctx, 10, deliveryLease).Return([]*domain.Notification{ok, failing}, nil)
+	mockSettings.On("Find", ctx, "tenant-1").Return(domain.Settings{FromAddress: "no-reply@example.com", FromName: "App"}, nil)
+	mockSender.On("Send", ctx, mock.MatchedBy(func(m domain.Message) bool { return m.To == "ok@example.com" })).Return(nil)
+	mockSender.On("Send", ctx, mock.MatchedBy(func(m domain.Message) bool { return m.To == "down@example.com" })).Return(errors.New("connection refused"))
+	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+	sent, err := handler.Handle(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	mockRepo.AssertNumberOfCalls(t, "Save", 2)
+
+	message := mockSender.Calls[0].Arguments.Get(1).(domain.Message)
+	assert.Equal(t, "no-reply@example.com", message.FromAddress)
+	assert.Equal(t, "Hola", message.Subject)
+
+	assert.Equal(t, domain.StatusSent, ok.Status())
+	assert.Equal(t, domain.StatusPending, failing.Status())
+	assert.Equal(t, 1, failing.Attempts())
+	assert.Equal(t, "connection refused", failing.LastError())
+	assert.True(t, failing.NextAttemptAt().After(time.Now().UTC().Add(59*time.Second)))
+}
+
+// trackingTransactionManager cuenta las transacciones y marca en el context
+// que se esta dentro de una
+type trackingTransactionManager struct {
+	transactions int
+}
+
+type inTransactionKey struct{}
+
+func (m *trackingTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+	return fn(context.WithValue(ctx, inTransactionKey{}, true))
+}
+
+func TestDeliverNotificationsCommandHandler_Handle_SendsOutsideTransaction(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockSettings := new(MockSettingsRepository)
+	mockSender := new(MockSender)
+	txManager := &trackingTransactionManager{}
+	retry := bus.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour, Multiplier: 2}
+	handler := NewDeliverNotificationsCommandHandler(mockRepo, mockSettings, mockSender, txManager, retry, 10, nopLogger{})
+
+	first := domain.NewNotification("event-1", "tenant-1", "user-1", domain.KindEmailVerification, "es", "a@example.com", domain.Content{Subject: "Hola"})
+	second := domain.NewNotification("event-2", "tenant-1", "user-2", domain.KindEmailVerification, "es", "b@example.com", domain.Content{Subject: "Hola"})
+
+	outsideTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(inTransactionKey{}) == nil })
+	insideTx := mock.MatchedBy(func(ctx context.Context) bool { return ctx.Value(inTransactionKey{}) != nil })
+
+	mockRepo.On("ClaimDue", insideTx, 10, deliveryLease).Return([]*domain.Notification{first, second}, nil)
+	mockSettings.On("Find", outsideTx, "tenant-1").Return(domain.Settings{FromAddress: "no-reply@example.com"}, nil)
+	mockSender.On("Send", outsideTx, mock.Anything).Return(nil)
+	mockRepo.On("Save", insideTx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+	sent, err := handler.Handle(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	// una para tomar las filas y una por cada resultado
+	assert.Equal(t, 3, txManager.transactions)
+	mockSender.AssertNumberOfCalls(t, "Send", 2)
+}
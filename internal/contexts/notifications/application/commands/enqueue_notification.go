@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+// EnqueueNotificationCommand lo arma el suscriptor de cada evento. Data son
+// los valores que usa el template (email, link, vencimiento, ...)
+type EnqueueNotificationCommand struct {
+	EventID       string
+	TenantID      string
+	UserID        string
+	Kind          domain.Kind
+	Recipient     string
+	Data          map[string]string
+	CorrelationID string
+}
+
+// EnqueueNotificationCommandHandler renderiza el mensaje en el idioma del
+// tenant y lo deja pendiente; el envio lo hace DeliverNotificationsCommandHandler
+type EnqueueNotificationCommandHandler struct {
+	repository    domain.NotificationRepository
+	templates     domain.TemplateRepository
+	builtin       domain.TemplateRepository
+	settings      domain.SettingsRepository
+	defaultLocale string
+	log           Logger
+}
+
+func NewEnqueueNotificationCommandHandler(
+	repo domain.NotificationRepository,
+	templates domain.TemplateRepository,
+	builtin domain.TemplateRepository,
+	settings domain.SettingsRepository,
+	defaultLocale string,
+	log Logger,
+) *EnqueueNotificationCommandHandler {
+	return &EnqueueNotificationCommandHandler{
+		repository:    repo,
+		templates:     templates,
+		builtin:       builtin,
+		settings:      settings,
+		defaultLocale: defaultLocale,
+		log:           log,
+	}
+}
+
+func (h *EnqueueNotificationCommandHandler) Handle(ctx context.Context, cmd EnqueueNotificationCommand) error {
+	settings, err := h.settings.Find(ctx, cmd.TenantID)
+	if err != nil {
+		return err
+	}
+
+	template, err := h.findTemplate(ctx, cmd.TenantID, cmd.Kind, settings.Locale)
+	if err != nil {
+		return err
+	}
+
+	content, err := template.Render(cmd.Data)
+	if err != nil {
+		return err
+	}
+
+	notification := domain.NewNotification(cmd.EventID, cmd.TenantID, cmd.UserID, cmd.Kind, template.Locale, cmd.Recipient, content)
+	if err := h.repository.Add(ctx, notification); err != nil {
+		return err
+	}
+
+	h.log.Info("notification enqueued", map[string]interface{}{
+		"notification_id": notification.ID(),
+		"kind":            string(cmd.Kind),
+		"locale":          template.Locale,
+		"correlation_id":  cmd.CorrelationID,
+	})
+
+	return nil
+}
+
+// findTemplate prioriza el template del tenant sobre el que viene con la app,
+// y el idioma del tenant sobre el idioma por defecto
+func (h *EnqueueNotificationCommandHandler) findTemplate(ctx context.Context, tenantID string, kind domain.Kind, locale string) (*domain.Template, error) {
+	locales := []string{locale}
+	if locale != h.defaultLocale {
+		locales = append(locales, h.defaultLocale)
+	}
+
+	for _, source := range []domain.TemplateRepository{h.templates, h.builtin} {
+		for _, l := range locales {
+			template, err := source.Find(ctx, tenantID, kind, l)
+			if err == nil {
+				return template, nil
+			}
+			if !errors.Is(err, domain.ErrTemplateNotFound) {
+				return nil, err
+			}
+		}
+	}
+
+	return nil, domain.ErrTemplateNotFound
+}
+
+type Logger interface {
+	Info(msg string, fields map[string]interface{})
+	Error(msg string, fields map[string]interface{})
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+func TestEnqueueNotificationCommandHandler_TenantTemplate(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockTemplates := new(MockTemplateRepository)
+	mockBuiltin := new(MockTemplateRepository)
+	mockSettings := new(MockSettingsRepository)
+	handler := NewEnqueueNotificationCommandHandler(mockRepo, mockTemplates, mockBuiltin, mockSettings, "es", nopLogger{})
+
+	mockSettings.On("Find", ctx, "tenant-1").Return(domain.Settings{Locale: "en"}, nil)
+	mockTemplates.On("Find", ctx, "tenant-1", domain.KindPasswordReset, "en").Return(&domain.Template{
+		TenantID: "tenant-1",
+		Kind:     domain.KindPasswordReset,
+		Locale:   "en",
+		Subject:  "Reset for {{.Email}}",
+		Text:     "{{.Link}}",
+	}, nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+	err := handler.Handle(ctx, EnqueueNotificationCommand{
+		EventID:   "event-1",
+		TenantID:  "tenant-1",
+		UserID:    "user-1",
+		Kind:      domain.KindPasswordReset,
+		Recipient: "john@example.com",
+		Data:      map[string]string{"Email": "john@example.com", "Link": "https://app/reset?token=abc"},
+	})
+
+	assert.NoError(t, err)
+	mockBuiltin.AssertNotCalled(t, "Find", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	notification := mockRepo.Calls[0].Arguments.Get(1).(*domain.Notification)
+	assert.Equal(t, "event-1", notification.EventID())
+	assert.Equal(t, "en", notification.Locale())
+	assert.Equal(t, domain.StatusPending, notification.Status())
+	assert.Equal(t, "Reset for john@example.com", notification.Content().Subject)
+	assert.Equal(t, "https://app/reset?token=abc", notification.Content().Text)
+}
+
+// sin template del tenant en su idioma ni en el default, se usa el builtin
+func TestEnqueueNotificationCommandHandler_FallbackToBuiltin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockTemplates := new(MockTemplateRepository)
+	mockBuiltin := new(MockTemplateRepository)
+	mockSettings := new(MockSettingsRepository)
+	handler := NewEnqueueNotificationCommandHandler(mockRepo, mockTemplates, mockBuiltin, mockSettings, "es", nopLogger{})
+
+	mockSettings.On("Find", ctx, "tenant-1").Return(domain.Settings{Locale: "pt"}, nil)
+	mockTemplates.On("Find", ctx, "tenant-1", domain.KindAccountLocked, mock.Anything).Return(nil, domain.ErrTemplateNotFound)
+	mockBuiltin.On("Find", ctx, "tenant-1", domain.KindAccountLocked, "pt").Return(nil, domain.ErrTemplateNotFound)
+	mockBuiltin.On("Find", ctx, "tenant-1", domain.KindAccountLocked, "es").Return(&domain.Template{
+		Kind:    domain.KindAccountLocked,
+		Locale:  "es",
+		Subject: "Cuenta bloqueada",
+		Text:    "Hasta {{.LockedUntil}}",
+	}, nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Notification")).Return(nil)
+
+	err := handler.Handle(ctx, EnqueueNotificationCommand{
+		EventID:   "event-1",
+		TenantID:  "tenant-1",
+		Kind:      domain.KindAccountLocked,
+		Recipient: "john@example.com",
+		Data:      map[string]string{"LockedUntil": "2025-01-01 10:00 UTC"},
+	})
+
+	assert.NoError(t, err)
+	mockTemplates.AssertNumberOfCalls(t, "Find", 2)
+
+	notification := mockRepo.Calls[0].Arguments.Get(1).(*domain.Notification)
+	assert.Equal(t, "es", notification.Locale())
+	assert.Equal(t, "Hasta 2025-01-01 10:00 UTC", notification.Content().Text)
+}
+
+func TestEnqueueNotificationCommandHandler_InvalidTemplate(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockTemplates := new(MockTemplateRepository)
+	mockSettings := new(MockSettingsRepository)
+	handler := NewEnqueueNotificationCommandHandler(mockRepo, mockTemplates, new(MockTemplateRepository), mockSettings, "es", nopLogger{})
+
+	mockSettings.On("Find", ctx, "tenant-1").Return(domain.Settings{Locale: "es"}, nil)
+	mockTemplates.On("Find", ctx, "tenant-1", domain.KindPasswordReset, "es").Return(&domain.Template{
+		Subject: "Reset",
+		Text:    "{{.Link}}",
+	}, nil)
+
+	err := handler.Handle(ctx, EnqueueNotificationCommand{
+		EventID:  "event-1",
+		TenantID: "tenant-1",
+		Kind:     domain.KindPasswordReset,
+		Data:     map[string]string{},
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidTemplate)
+	mockRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,8 @@
+package domain
+
+import "errors"
+
+var (
+	ErrTemplateNotFound = errors.New("notification template not found")
+	ErrInvalidTemplate  = errors.New("invalid notification template")
+)
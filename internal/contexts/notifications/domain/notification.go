@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind identifica que mensaje se manda y con que template se renderiza
+type Kind string
+
+const (
+	KindEmailVerification Kind = "email_verification"
+	KindEmailChange       Kind = "email_change"
+	KindEmailChanged      Kind = "email_changed"
+	KindPasswordReset     Kind = "password_reset"
+	KindPasswordChanged   Kind = "password_changed"
+	KindAccountLocked     Kind = "account_locked"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSent    Status = "sent"
+	StatusFailed  Status = "failed"
+)
+
+// Notification es un mensaje ya renderizado esperando ser entregado. EventID
+// es el evento que la origino: el mismo evento no genera dos notificaciones
+type Notification struct {
+	id            string
+	eventID       string
+	tenantID      string
+	userID        string
+	kind          Kind
+	locale        string
+	recipient     string
+	content       Content
+	status        Status
+	attempts      int
+	lastError     string
+	nextAttemptAt time.Time
+	sentAt        *time.Time
+	createdAt     time.Time
+	updatedAt     time.Time
+}
+
+func NewNotification(eventID, tenantID, userID string, kind Kind, locale, recipient string, content Content) *Notification {
+	now := time.Now().UTC()
+	return &Notification{
+		id:            uuid.New().String(),
+		eventID:       eventID,
+		tenantID:      tenantID,
+		userID:        userID,
+		kind:          kind,
+		locale:        locale,
+		recipient:     recipient,
+		content:       content,
+		status:        StatusPending,
+		nextAttemptAt: now,
+		createdAt:     now,
+		updatedAt:     now,
+	}
+}
+
+func ReconstituteNotification(id, eventID, tenantID, userID string, kind Kind, locale, recipient string, content Content, status Status, attempts int, lastError string, nextAttemptAt time.Time, sentAt *time.Time, createdAt, updatedAt time.Time) *Notification {
+	return &Notification{
+		id:            id,
+		eventID:       eventID,
+		tenantID:      tenantID,
+		userID:        userID,
+		kind:          kind,
+		locale:        locale,
+		recipient:     recipient,
+		content:       content,
+		status:        status,
+		attempts:      attempts,
+		lastError:     lastError,
+		nextAttemptAt: nextAttemptAt,
+		sentAt:        sentAt,
+		createdAt:     createdAt,
+		updatedAt:     updatedAt,
+	}
+}
+
+// MarkSent descarta el cuerpo: los links llevan tokens en claro y no hace
+// falta guardarlos una vez entregados
+func (n *Notification) MarkSent() {
+	now := time.Now().UTC()
+	n.attempts++
+	n.status = StatusSent
+	n.sentAt = &now
+	n.lastError = ""
+	n.content = Content{Subject: n.content.Subject}
+	n.updatedAt = now
+}
+
+// MarkFailed programa el proximo intento, o deja la notificacion como fallida
+// si ya no quedan intentos
+func (n *Notification) MarkFailed(cause error, maxAttempts int, backoff time.Duration) {
+	now := time.Now().UTC()
+	n.attempts++
+	n.lastError = cause.Error()
+	n.updatedAt = now
+
+	if n.attempts >= maxAttempts {
+		n.status = StatusFailed
+		n.content = Content{Subject: n.content.Subject}
+		return
+	}
+
+	n.nextAttemptAt = now.Add(backoff)
+}
+
+func (n *Notification) ID() string               { return n.id }
+func (n *Notification) EventID() string          { return n.eventID }
+func (n *Notification) TenantID() string         { return n.tenantID }
+func (n *Notification) UserID() string           { return n.userID }
+func (n *Notification) Kind() Kind               { return n.kind }
+func (n *Notification) Locale() string           { return n.locale }
+func (n *Notification) Recipient() string        { return n.recipient }
+func (n *Notification) Content() Content         { return n.content }
+func (n *Notification) Status() Status           { return n.status }
+func (n *Notification) Attempts() int            { return n.attempts }
+func (n *Notification) LastError() string        { return n.lastError }
+func (n *Notification) NextAttemptAt() time.Time { return n.nextAttemptAt }
+func (n *Notification) SentAt() *time.Time       { return n.sentAt }
+func (n *Notification) CreatedAt() time.Time     { return n.createdAt }
+func (n *Notification) UpdatedAt() time.Time     { return n.updatedAt }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type NotificationRepository interface {
+	// Add ignora una notificacion repetida para el mismo evento y kind, asi
+	// reprocesar un evento no manda el mensaje dos veces
+	Add(ctx context.Context, notification *Notification) error
+	// Save guarda el estado de entrega
+	Save(ctx context.Context, notification *Notification) error
+	// ClaimDue toma las notificaciones pendientes cuyo proximo intento ya
+	// vencio y les corre el proximo intento a lease, asi otro proceso no las
+	// manda mientras se envian. Si el proceso muere se reintentan al vencer
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Notification, error)
+}
+
+type TemplateRepository interface {
+	Find(ctx context.Context, tenantID string, kind Kind, locale string) (*Template, error)
+}
+
+// Settings es la configuracion de envio de un tenant
+type Settings struct {
+	Locale      string
+	FromAddress string
+	FromName    string
+}
+
+type SettingsRepository interface {
+	// Find devuelve los valores por defecto si el tenant no tiene configuracion
+	Find(ctx context.Context, tenantID string) (Settings, error)
+}
+
+// Message es lo que recibe un Sender
+type Message struct {
+	FromAddress string
+	FromName    string
+	To          string
+	Subject     string
+	Text        string
+	HTML        string
+}
+
+// Sender entrega un mensaje por algun canal (SMTP, archivo, log)
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
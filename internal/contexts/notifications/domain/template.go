@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+// Content es un mensaje ya renderizado
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Template es la version de un mensaje para un tenant e idioma. Subject y Text
+// se renderizan como texto plano, HTML con escape automatico de los datos
+type Template struct {
+	TenantID string
+	Kind     Kind
+	Locale   string
+	Subject  string
+	Text     string
+	HTML     string
+}
+
+func (t *Template) Render(data map[string]string) (Content, error) {
+	subject, err := renderText(t.Subject, data)
+	if err != nil {
+		return Content{}, err
+	}
+
+	text, err := renderText(t.Text, data)
+	if err != nil {
+		return Content{}, err
+	}
+
+	html, err := renderHTML(t.HTML, data)
+	if err != nil {
+		return Content{}, err
+	}
+
+	return Content{Subject: subject, Text: text, HTML: html}, nil
+}
+
+func renderText(source string, data map[string]string) (string, error) {
+	tmpl, err := texttemplate.New("").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return out.String(), nil
+}
+
+func renderHTML(source string, data map[string]string) (string, error) {
+	if source == "" {
+		return "", nil
+	}
+
+	tmpl, err := htmltemplate.New("").Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+	return out.String(), nil
+}
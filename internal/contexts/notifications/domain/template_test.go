@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	template := &Template{
+		Subject: "Hola {{.Email}}",
+		Text:    "Link: {{.Link}}",
+		HTML:    `<a href="{{.Link}}">{{.Email}}</a>`,
+	}
+
+	content, err := template.Render(map[string]string{
+		"Email": "<b>john</b>@example.com",
+		"Link":  "https://app.example.com/verify?token=abc&x=1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Hola <b>john</b>@example.com", content.Subject)
+	assert.Equal(t, "Link: https://app.example.com/verify?token=abc&x=1", content.Text)
+	// en HTML los datos se escapan
+	assert.Equal(t, `<a href="https://app.example.com/verify?token=abc&amp;x=1">&lt;b&gt;john&lt;/b&gt;@example.com</a>`, content.HTML)
+}
+
+func TestTemplate_Render_MissingKey(t *testing.T) {
+	template := &Template{Subject: "Hola", Text: "Link: {{.Link}}"}
+
+	_, err := template.Render(map[string]string{})
+
+	assert.ErrorIs(t, err, ErrInvalidTemplate)
+}
+
+func TestNotification_MarkFailed(t *testing.T) {
+	notification := NewNotification("event-1", "tenant-1", "user-1", KindPasswordReset, "es", "john@example.com", Content{
+		Subject: "Reset",
+		Text:    "token secreto",
+	})
+
+	before := time.Now().UTC()
+	notification.MarkFailed(errors.New("connection refused"), 2, time.Minute)
+
+	assert.Equal(t, StatusPending, notification.Status())
+	assert.Equal(t, 1, notification.Attempts())
+	assert.Equal(t, "connection refused", notification.LastError())
+	assert.True(t, notification.NextAttemptAt().After(before.Add(59*time.Second)))
+	assert.Equal(t, "token secreto", notification.Content().Text)
+
+	// al agotar los intentos queda fallida y se descarta el cuerpo
+	notification.MarkFailed(errors.New("connection refused"), 2, time.Minute)
+
+	assert.Equal(t, StatusFailed, notification.Status())
+	assert.Equal(t, 2, notification.Attempts())
+	assert.Equal(t, "Reset", notification.Content().Subject)
+	assert.Empty(t, notification.Content().Text)
+}
+
+func TestNotification_MarkSent(t *testing.T) {
+	notification := NewNotification("event-1", "tenant-1", "user-1", KindPasswordReset, "es", "john@example.com", Content{
+		Subject: "Reset",
+		Text:    "token secreto",
+		HTML:    "<p>token secreto</p>",
+	})
+
+	notification.MarkSent()
+
+	assert.Equal(t, StatusSent, notification.Status())
+	assert.NotNil(t, notification.SentAt())
+	assert.Equal(t, Content{Subject: "Reset"}, notification.Content())
+}
@@ -0,0 +1,126 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/contexts/notifications/application/commands"
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	usersDomain "backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/bus"
+)
+
+// formato de las fechas dentro de los mensajes
+const timeLayout = "2006-01-02 15:04 UTC"
+
+type EventBus interface {
+	Subscribe(eventType string, handler bus.EventHandler) error
+}
+
+// EventSubscriber traduce los eventos de users y auth a notificaciones. Los
+// mensajes con token no pasan por aca, los encola TokenNotifier
+type EventSubscriber struct {
+	enqueue *commands.EnqueueNotificationCommandHandler
+}
+
+func NewEventSubscriber(enqueue *commands.EnqueueNotificationCommandHandler) *EventSubscriber {
+	return &EventSubscriber{
+		enqueue: enqueue,
+	}
+}
+
+func (s *EventSubscriber) Register(eventBus EventBus) error {
+	subscriptions := map[string]bus.EventHandler{
+		usersDomain.UserEmailChangedEventType:    s.onEmailChanged,
+		usersDomain.UserPasswordChangedEventType: s.onPasswordChanged,
+		authDomain.UserLockedEventType:           s.onUserLocked,
+	}
+
+	for eventType, handler := range subscriptions {
+		if err := eventBus.Subscribe(eventType, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// el aviso va al email anterior: si el cambio no lo hizo el usuario, se entera
+func (s *EventSubscriber) onEmailChanged(ctx context.Context, event interface{}) error {
+	var e usersDomain.UserEmailChangedEvent
+	if err := decode(event, &e); err != nil {
+		return err
+	}
+
+	return s.enqueue.Handle(ctx, commands.EnqueueNotificationCommand{
+		EventID:   e.EventID(),
+		TenantID:  e.TenantID(),
+		UserID:    e.UserID,
+		Kind:      domain.KindEmailChanged,
+		Recipient: e.OldEmail,
+		Data: map[string]string{
+			"Email":     e.OldEmail,
+			"NewEmail":  e.NewEmail,
+			"ChangedAt": e.UpdatedAt.UTC().Format(timeLayout),
+		},
+		CorrelationID: e.CorrelationID(),
+	})
+}
+
+func (s *EventSubscriber) onPasswordChanged(ctx context.Context, event interface{}) error {
+	var e usersDomain.UserPasswordChangedEvent
+	if err := decode(event, &e); err != nil {
+		return err
+	}
+
+	return s.enqueue.Handle(ctx, commands.EnqueueNotificationCommand{
+		EventID:   e.EventID(),
+		TenantID:  e.TenantID(),
+		UserID:    e.UserID,
+		Kind:      domain.KindPasswordChanged,
+		Recipient: e.Email,
+		Data: map[string]string{
+			"Email":     e.Email,
+			"ChangedAt": e.UpdatedAt.UTC().Format(timeLayout),
+		},
+		CorrelationID: e.CorrelationID(),
+	})
+}
+
+func (s *EventSubscriber) onUserLocked(ctx context.Context, event interface{}) error {
+	var e authDomain.UserLockedEvent
+	if err := decode(event, &e); err != nil {
+		return err
+	}
+
+	return s.enqueue.Handle(ctx, commands.EnqueueNotificationCommand{
+		EventID:   e.EventID(),
+		TenantID:  e.TenantID(),
+		UserID:    e.UserID,
+		Kind:      domain.KindAccountLocked,
+		Recipient: e.Email,
+		Data: map[string]string{
+			"Email":       e.Email,
+			"Failures":    strconv.Itoa(e.Failures),
+			"LockedUntil": e.LockedUntil.UTC().Format(timeLayout),
+		},
+		CorrelationID: e.CorrelationID(),
+	})
+}
+
+// decode convierte el map que entrega el bus al struct tipado del evento
+func decode(event interface{}, target interface{}) error {
+	eventMap, ok := event.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid event format %T", event)
+	}
+
+	eventBytes, err := json.Marshal(eventMap)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(eventBytes, target)
+}
@@ -0,0 +1,64 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"backend-challenge-guinea/internal/contexts/notifications/application/commands"
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	usersCommands "backend-challenge-guinea/internal/contexts/users/application/commands"
+	usersDomain "backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type tokenMessage struct {
+	kind domain.Kind
+	path string
+}
+
+var tokenMessages = map[usersDomain.TokenPurpose]tokenMessage{
+	usersDomain.TokenPurposeEmailVerification: {kind: domain.KindEmailVerification, path: "/verify-email"},
+	usersDomain.TokenPurposeEmailChange:       {kind: domain.KindEmailChange, path: "/confirm-email"},
+	usersDomain.TokenPurposePasswordReset:     {kind: domain.KindPasswordReset, path: "/reset-password"},
+}
+
+// TokenNotifier encola los mensajes con token dentro de la transaccion del
+// comando de users, asi el token en claro no queda en el outbox ni en la cola.
+// Los links apuntan al frontend (appURL), que es quien llama a la API con el token
+type TokenNotifier struct {
+	enqueue *commands.EnqueueNotificationCommandHandler
+	appURL  string
+}
+
+func NewTokenNotifier(enqueue *commands.EnqueueNotificationCommandHandler, appURL string) *TokenNotifier {
+	return &TokenNotifier{
+		enqueue: enqueue,
+		appURL:  strings.TrimRight(appURL, "/"),
+	}
+}
+
+func (n *TokenNotifier) NotifyToken(ctx context.Context, notice usersCommands.TokenNotice) error {
+	message, ok := tokenMessages[notice.Purpose]
+	if !ok {
+		return fmt.Errorf("no notification for token purpose %q", notice.Purpose)
+	}
+
+	return n.enqueue.Handle(ctx, commands.EnqueueNotificationCommand{
+		EventID:   notice.EventID,
+		TenantID:  notice.TenantID,
+		UserID:    notice.UserID,
+		Kind:      message.kind,
+		Recipient: notice.Recipient,
+		Data: map[string]string{
+			"Email":     notice.Recipient,
+			"Link":      n.link(message.path, notice.Token),
+			"ExpiresAt": notice.ExpiresAt.UTC().Format(timeLayout),
+		},
+		CorrelationID: notice.CorrelationID,
+	})
+}
+
+func (n *TokenNotifier) link(path, token string) string {
+	return n.appURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,144 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresNotificationRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresNotificationRepository(db *sql.DB) *PostgresNotificationRepository {
+	return &PostgresNotificationRepository{db: db}
+}
+
+func (r *PostgresNotificationRepository) Add(ctx context.Context, notification *domain.Notification) error {
+	query := `
+		INSERT INTO notifications (
+			id, event_id, tenant_id, user_id, kind, locale, recipient, subject, text_body, html_body,
+			status, attempts, next_attempt_at, created_at, updated_at
+		)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (event_id, kind) DO NOTHING
+	`
+
+	content := notification.Content()
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		notification.ID(),
+		notification.EventID(),
+		notification.TenantID(),
+		notification.UserID(),
+		string(notification.Kind()),
+		notification.Locale(),
+		notification.Recipient(),
+		content.Subject,
+		content.Text,
+		content.HTML,
+		string(notification.Status()),
+		notification.Attempts(),
+		notification.NextAttemptAt(),
+		notification.CreatedAt(),
+		notification.UpdatedAt(),
+	)
+
+	return err
+}
+
+func (r *PostgresNotificationRepository) Save(ctx context.Context, notification *domain.Notification) error {
+	query := `
+		UPDATE notifications SET
+			text_body = $2,
+			html_body = $3,
+			status = $4,
+			attempts = $5,
+			last_error = NULLIF($6, ''),
+			next_attempt_at = $7,
+			sent_at = $8,
+			updated_at = $9
+		WHERE id = $1
+	`
+
+	content := notification.Content()
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		notification.ID(),
+		content.Text,
+		content.HTML,
+		string(notification.Status()),
+		notification.Attempts(),
+		notification.LastError(),
+		notification.NextAttemptAt(),
+		notification.SentAt(),
+		notification.UpdatedAt(),
+	)
+
+	return err
+}
+
+// ClaimDue usa SKIP LOCKED para que varios consumers puedan repartirse el envio
+func (r *PostgresNotificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*domain.Notification, error) {
+	query := `
+		UPDATE notifications SET next_attempt_at = $4
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = $1 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_id, tenant_id, COALESCE(user_id::text, ''), kind, locale, recipient,
+			subject, text_body, html_body, status, attempts, COALESCE(last_error, ''),
+			next_attempt_at, sent_at, created_at, updated_at
+	`
+
+	now := time.Now().UTC()
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query, string(domain.StatusPending), now, limit, now.Add(lease))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*domain.Notification, 0)
+	for rows.Next() {
+		var (
+			id            string
+			eventID       string
+			tenantID      string
+			userID        string
+			kind          string
+			locale        string
+			recipient     string
+			content       domain.Content
+			status        string
+			attempts      int
+			lastError     string
+			nextAttemptAt time.Time
+			sentAt        *time.Time
+			createdAt     time.Time
+			updatedAt     time.Time
+		)
+
+		if err := rows.Scan(
+			&id, &eventID, &tenantID, &userID, &kind, &locale, &recipient,
+			&content.Subject, &content.Text, &content.HTML, &status, &attempts, &lastError,
+			&nextAttemptAt, &sentAt, &createdAt, &updatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, domain.ReconstituteNotification(
+			id, eventID, tenantID, userID, domain.Kind(kind), locale, recipient, content,
+			domain.Status(status), attempts, lastError, nextAttemptAt.UTC(), sentAt, createdAt.UTC(), updatedAt.UTC(),
+		))
+	}
+
+	return notifications, rows.Err()
+}
@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresSettingsRepository completa con los valores por defecto lo que el
+// tenant no configuro
+type PostgresSettingsRepository struct {
+	db       *sql.DB
+	defaults domain.Settings
+}
+
+func NewPostgresSettingsRepository(db *sql.DB, defaults domain.Settings) *PostgresSettingsRepository {
+	return &PostgresSettingsRepository{db: db, defaults: defaults}
+}
+
+func (r *PostgresSettingsRepository) Find(ctx context.Context, tenantID string) (domain.Settings, error) {
+	query := `
+		SELECT COALESCE(locale, ''), COALESCE(from_address, ''), COALESCE(from_name, '')
+		FROM notification_settings
+		WHERE tenant_id = $1
+	`
+
+	var settings domain.Settings
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tenantID).Scan(
+		&settings.Locale, &settings.FromAddress, &settings.FromName,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.defaults, nil
+		}
+		return domain.Settings{}, err
+	}
+
+	if settings.Locale == "" {
+		settings.Locale = r.defaults.Locale
+	}
+	if settings.FromAddress == "" {
+		settings.FromAddress = r.defaults.FromAddress
+	}
+	if settings.FromName == "" {
+		settings.FromName = r.defaults.FromName
+	}
+
+	return settings, nil
+}
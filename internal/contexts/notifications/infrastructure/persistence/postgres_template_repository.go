@@ -0,0 +1,41 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresTemplateRepository tiene solo los templates que cargo cada tenant,
+// los que vienen con la app estan en infrastructure/templates
+type PostgresTemplateRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTemplateRepository(db *sql.DB) *PostgresTemplateRepository {
+	return &PostgresTemplateRepository{db: db}
+}
+
+func (r *PostgresTemplateRepository) Find(ctx context.Context, tenantID string, kind domain.Kind, locale string) (*domain.Template, error) {
+	query := `
+		SELECT subject, text_body, html_body
+		FROM notification_templates
+		WHERE tenant_id = $1 AND kind = $2 AND locale = $3
+	`
+
+	template := &domain.Template{TenantID: tenantID, Kind: kind, Locale: locale}
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tenantID, string(kind), locale).Scan(
+		&template.Subject, &template.Text, &template.HTML,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	return template, nil
+}
@@ -0,0 +1,60 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+
+	"github.com/google/uuid"
+)
+
+type Logger interface {
+	Info(msg string, fields map[string]interface{})
+}
+
+// FileSender es para desarrollo local: guarda cada mensaje como .eml en dir
+// para abrirlo con cualquier cliente de correo. Sin dir solo lo loguea
+type FileSender struct {
+	dir string
+	log Logger
+}
+
+func NewFileSender(dir string, log Logger) *FileSender {
+	return &FileSender{dir: dir, log: log}
+}
+
+func (s *FileSender) Send(ctx context.Context, message domain.Message) error {
+	fields := map[string]interface{}{
+		"to":      message.To,
+		"subject": message.Subject,
+	}
+
+	if s.dir == "" {
+		fields["text"] = message.Text
+		s.log.Info("notification sent to log", fields)
+		return nil
+	}
+
+	body, err := buildMIME(message)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.New().String())
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return err
+	}
+
+	fields["file"] = path
+	s.log.Info("notification written to file", fields)
+	return nil
+}
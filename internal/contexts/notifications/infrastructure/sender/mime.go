@@ -0,0 +1,67 @@
+package sender
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+// buildMIME arma el mensaje como multipart/alternative con la version en texto
+// y, si hay, la version HTML
+func buildMIME(message domain.Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	from := mail.Address{Name: message.FromName, Address: message.FromAddress}
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", message.To)
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", message.Subject))
+	writeHeader(&buf, "Date", time.Now().UTC().Format(time.RFC1123Z))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	writer := multipart.NewWriter(&buf)
+	writeHeader(&buf, "Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", writer.Boundary()))
+	buf.WriteString("\r\n")
+
+	if err := writePart(writer, "text/plain; charset=utf-8", message.Text); err != nil {
+		return nil, err
+	}
+	if message.HTML != "" {
+		if err := writePart(writer, "text/html; charset=utf-8", message.HTML); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key + ": " + value + "\r\n")
+}
+
+func writePart(writer *multipart.Writer, contentType, body string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	encoder := quotedprintable.NewWriter(part)
+	if _, err := encoder.Write([]byte(body)); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
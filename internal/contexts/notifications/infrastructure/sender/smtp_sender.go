@@ -0,0 +1,41 @@
+package sender
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+// SMTPSender manda por SMTP; si no hay usuario configurado no autentica
+// (por ejemplo contra un relay interno o mailpit)
+type SMTPSender struct {
+	config SMTPConfig
+}
+
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, message domain.Message) error {
+	body, err := buildMIME(message)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := net.JoinHostPort(s.config.Host, s.config.Port)
+	return smtp.SendMail(addr, auth, message.FromAddress, []string{message.To}, body)
+}
@@ -0,0 +1,56 @@
+package templates
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"io/fs"
+	"path"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+// los templates que vienen con la app, en builtin/<locale>/<kind>.{subject.txt,txt,html}
+//
+//go:embed builtin
+var builtinFS embed.FS
+
+// BuiltinTemplates son los templates por defecto, iguales para todos los
+// tenants. Un tenant puede pisarlos cargando los suyos en notification_templates
+type BuiltinTemplates struct {
+	files fs.FS
+}
+
+func NewBuiltinTemplates() *BuiltinTemplates {
+	return &BuiltinTemplates{files: builtinFS}
+}
+
+func (b *BuiltinTemplates) Find(ctx context.Context, tenantID string, kind domain.Kind, locale string) (*domain.Template, error) {
+	base := path.Join("builtin", locale, string(kind))
+
+	subject, err := fs.ReadFile(b.files, base+".subject.txt")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, domain.ErrTemplateNotFound
+		}
+		return nil, err
+	}
+
+	text, err := fs.ReadFile(b.files, base+".txt")
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := fs.ReadFile(b.files, base+".html")
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Template{
+		Kind:    kind,
+		Locale:  locale,
+		Subject: string(subject),
+		Text:    string(text),
+		HTML:    string(html),
+	}, nil
+}
@@ -0,0 +1,54 @@
+package templates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge-guinea/internal/contexts/notifications/domain"
+)
+
+// todos los templates tienen que renderizar con los datos que arma el suscriptor
+func TestBuiltinTemplates_Render(t *testing.T) {
+	builtin := NewBuiltinTemplates()
+	data := map[string]string{
+		"Email":       "john@example.com",
+		"NewEmail":    "jane@example.com",
+		"Link":        "https://app.example.com/verify-email?token=abc",
+		"ExpiresAt":   "2025-01-01 10:00 UTC",
+		"ChangedAt":   "2025-01-01 10:00 UTC",
+		"LockedUntil": "2025-01-01 10:00 UTC",
+		"Failures":    "5",
+	}
+
+	kinds := []domain.Kind{
+		domain.KindEmailVerification,
+		domain.KindEmailChange,
+		domain.KindEmailChanged,
+		domain.KindPasswordReset,
+		domain.KindPasswordChanged,
+		domain.KindAccountLocked,
+	}
+
+	for _, locale := range []string{"es", "en"} {
+		for _, kind := range kinds {
+			template, err := builtin.Find(context.Background(), "tenant-1", kind, locale)
+			if !assert.NoError(t, err, "%s/%s", locale, kind) {
+				continue
+			}
+
+			content, err := template.Render(data)
+			assert.NoError(t, err, "%s/%s", locale, kind)
+			assert.NotEmpty(t, content.Subject)
+			assert.Contains(t, content.Text, "john@example.com")
+			assert.Contains(t, content.HTML, "john@example.com")
+		}
+	}
+}
+
+func TestBuiltinTemplates_NotFound(t *testing.T) {
+	_, err := NewBuiltinTemplates().Find(context.Background(), "tenant-1", domain.KindPasswordReset, "fr")
+
+	assert.Equal(t, domain.ErrTemplateNotFound, err)
+}
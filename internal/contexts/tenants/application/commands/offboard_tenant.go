@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+// ExportStore guarda el export de un tenant a medida que write lo escribe y
+// devuelve donde quedo. Si write falla no queda ningun export
+type ExportStore interface {
+	Store(ctx context.Context, tenantID string, write func(w domain.ExportWriter) error) (string, error)
+}
+
+type OffboardTenantCommand struct {
+	TenantID      string
+	CorrelationID string
+}
+
+// OffboardTenantCommandHandler da de baja un tenant suspendido: exporta sus
+// datos, los borra y deja el registro como offboarded
+type OffboardTenantCommandHandler struct {
+	repository domain.TenantRepository
+	data       domain.TenantDataRepository
+	exports    ExportStore
+	router     TenantRouter
+	eventBus   EventBus
+	txManager  TransactionManager
+}
+
+func NewOffboardTenantCommandHandler(
+	repo domain.TenantRepository,
+	data domain.TenantDataRepository,
+	exports ExportStore,
+	router TenantRouter,
+	eventBus EventBus,
+	txManager TransactionManager,
+) *OffboardTenantCommandHandler {
+	return &OffboardTenantCommandHandler{
+		repository: repo,
+		data:       data,
+		exports:    exports,
+		router:     router,
+		eventBus:   eventBus,
+		txManager:  txManager,
+	}
+}
+
+// Handle devuelve donde quedo el export
+func (h *OffboardTenantCommandHandler) Handle(ctx context.Context, cmd OffboardTenantCommand) (string, error) {
+	// la isolation no cambia: alcanza para elegir la conexion antes del lock
+	tenant, err := h.repository.FindByID(ctx, cmd.TenantID)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, err = h.router.Scope(ctx, tenant.ID(), tenant.HasDedicatedSchema())
+	if err != nil {
+		return "", err
+	}
+
+	// el export se guarda antes de borrar: si algo falla despues se hace
+	// rollback del borrado y el export sobra, pero nunca se borra sin export
+	var location string
+	err = h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// el lock hace que de dos offboards en paralelo el segundo vea el
+		// tenant ya dado de baja
+		tenant, err = h.repository.FindByIDForUpdate(ctx, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		if err := tenant.Offboard(); err != nil {
+			return err
+		}
+
+		location, err = h.exports.Store(ctx, tenant.ID(), func(w domain.ExportWriter) error {
+			return h.data.Export(ctx, tenant.ID(), w)
+		})
+		if err != nil {
+			return err
+		}
+
+		purged, err := h.data.Purge(ctx, tenant.ID())
+		if err != nil {
+			return err
+		}
+
+		if err := h.repository.Save(ctx, tenant); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewTenantOffboardedEvent(tenant, location, purged, cmd.CorrelationID))
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// el schema ya esta vacio; si no se puede borrar ahora lo reintenta el
+	// arranque de la API
+	if tenant.HasDedicatedSchema() {
+		if err := h.router.DropSchema(ctx, tenant.ID()); err != nil {
+			return location, fmt.Errorf("tenant offboarded but schema not dropped: %w", err)
+		}
+	}
+
+	return location, nil
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type MockTenantDataRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantDataRepository) Export(ctx context.Context, tenantID string, w domain.ExportWriter) error {
+	args := m.Called(ctx, tenantID, w)
+	return args.Error(0)
+}
+
+func (m *MockTenantDataRepository) Purge(ctx context.Context, tenantID string) (map[string]int64, error) {
+	args := m.Called(ctx, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
+// MockExportStore corre write contra un writer que no guarda nada, asi el
+// handler llega a llamar a Export
+type MockExportStore struct {
+	mock.Mock
+}
+
+func (m *MockExportStore) Store(ctx context.Context, tenantID string, write func(w domain.ExportWriter) error) (string, error) {
+	args := m.Called(ctx, tenantID)
+	if err := write(discardWriter{}); err != nil {
+		return "", err
+	}
+	return args.String(0), args.Error(1)
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Table(name string) error       { return nil }
+func (discardWriter) Row(row json.RawMessage) error { return nil }
+
+func newOffboardHandler() (*OffboardTenantCommandHandler, *MockTenantRepository, *MockTenantDataRepository, *MockExportStore, *MockTenantRouter, *MockEventBus) {
+	mockRepo := new(MockTenantRepository)
+	mockData := new(MockTenantDataRepository)
+	mockExports := new(MockExportStore)
+	mockRouter := new(MockTenantRouter)
+	mockEventBus := new(MockEventBus)
+	handler := NewOffboardTenantCommandHandler(mockRepo, mockData, mockExports, mockRouter, mockEventBus, &MockTransactionManager{})
+	return handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus
+}
+
+func suspendedTenant(t *testing.T) *domain.Tenant {
+	tenant, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	assert.NoError(t, tenant.Suspend())
+	return tenant
+}
+
+func TestOffboardTenantCommandHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus := newOffboardHandler()
+
+	tenant := suspendedTenant(t)
+	purged := map[string]int64{"users_write": 2, "users_read": 2, "sessions": 3}
+
+	mockRepo.On("FindByID", ctx, "acme").Return(tenant, nil)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(tenant, nil)
+	mockExports.On("Store", ctx, "acme").Return("exports/acme.json", nil)
+	mockData.On("Export", ctx, "acme", discardWriter{}).Return(nil)
+	mockData.On("Purge", ctx, "acme").Return(purged, nil)
+	mockRepo.On("Save", ctx, tenant).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.TenantOffboardedEvent")).Return(nil)
+
+	location, err := handler.Handle(ctx, OffboardTenantCommand{TenantID: "acme"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "exports/acme.json", location)
+	assert.Equal(t, domain.StatusOffboarded, tenant.Status())
+	mockRouter.AssertNotCalled(t, "DropSchema", mock.Anything, mock.Anything)
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.TenantOffboardedEvent)
+	assert.Equal(t, domain.TenantOffboardedEventType, event.EventType())
+	assert.Equal(t, "offboarded", event.Status)
+	assert.Equal(t, "exports/acme.json", event.ExportLocation)
+	assert.Equal(t, purged, event.PurgedRows)
+}
+
+func TestOffboardTenantCommandHandler_Handle_DropsDedicatedSchema(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus := newOffboardHandler()
+
+	tenant, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationDedicated, domain.Settings{})
+	assert.NoError(t, tenant.Suspend())
+
+	mockRepo.On("FindByID", ctx, "acme").Return(tenant, nil)
+	mockRouter.On("Scope", ctx, "acme", true).Return(nil)
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(tenant, nil)
+	mockExports.On("Store", ctx, "acme").Return("exports/acme.json", nil)
+	mockData.On("Export", ctx, "acme", discardWriter{}).Return(nil)
+	mockData.On("Purge", ctx, "acme").Return(map[string]int64{}, nil)
+	mockRepo.On("Save", ctx, tenant).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.TenantOffboardedEvent")).Return(nil)
+	mockRouter.On("DropSchema", ctx, "acme").Return(nil)
+
+	_, err := handler.Handle(ctx, OffboardTenantCommand{TenantID: "acme"})
+
+	assert.NoError(t, err)
+	mockRouter.AssertCalled(t, "DropSchema", ctx, "acme")
+}
+
+func TestOffboardTenantCommandHandler_Handle_RequiresSuspended(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus := newOffboardHandler()
+
+	active, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	mockRepo.On("FindByID", ctx, "acme").Return(active, nil)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(active, nil)
+
+	_, err := handler.Handle(ctx, OffboardTenantCommand{TenantID: "acme"})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidStatusTransition)
+	mockExports.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	mockData.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// el segundo de dos offboards en paralelo lee, despues del lock, el tenant que
+// dejo el primero
+func TestOffboardTenantCommandHandler_Handle_AlreadyOffboarded(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus := newOffboardHandler()
+
+	stale := suspendedTenant(t)
+	current := suspendedTenant(t)
+	assert.NoError(t, current.Offboard())
+
+	mockRepo.On("FindByID", ctx, "acme").Return(stale, nil)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(current, nil)
+
+	_, err := handler.Handle(ctx, OffboardTenantCommand{TenantID: "acme"})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidStatusTransition)
+	mockExports.AssertNotCalled(t, "Store", mock.Anything, mock.Anything)
+	mockData.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestOffboardTenantCommandHandler_Handle_NothingPurgedWithoutExport(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockData, mockExports, mockRouter, mockEventBus := newOffboardHandler()
+
+	tenant := suspendedTenant(t)
+
+	mockRepo.On("FindByID", ctx, "acme").Return(tenant, nil)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(tenant, nil)
+	mockExports.On("Store", ctx, "acme").Return("exports/acme.json", nil)
+	mockData.On("Export", ctx, "acme", discardWriter{}).Return(errors.New("connection lost"))
+
+	_, err := handler.Handle(ctx, OffboardTenantCommand{TenantID: "acme"})
+
+	assert.Error(t, err)
+	mockData.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
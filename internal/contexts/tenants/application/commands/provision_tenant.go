@@ -0,0 +1,155 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+// InitialAdmin es el primer usuario del tenant; queda con rol admin
+type InitialAdmin struct {
+	Name     string
+	Email    string
+	Password string
+}
+
+// AdminCreator da de alta al admin inicial. Lo implementa el contexto de users
+// y tiene que devolver ErrInvalidAdmin para datos invalidos y ErrTenantHasAdmin
+// si el tenant ya tiene un admin activo
+type AdminCreator interface {
+	CreateAdmin(ctx context.Context, tenantID string, admin InitialAdmin, correlationID string) (string, error)
+}
+
+// TenantCache es la cache de tenants de esta instancia; las demas se enteran del
+// alta por el evento
+type TenantCache interface {
+	Invalidate(tenantID string)
+}
+
+// CreateInitialAdminCommand es para un tenant que no tiene ningun admin activo,
+// por ejemplo uno registrado antes de que el alta creara el admin
+type CreateInitialAdminCommand struct {
+	TenantID      string
+	Admin         InitialAdmin
+	CorrelationID string
+}
+
+type ProvisionTenantCommand struct {
+	ID            string
+	Name          string
+	Plan          string
+	Isolation     string
+	Admin         InitialAdmin
+	CorrelationID string
+}
+
+// ProvisionTenantCommandHandler da de alta un tenant listo para usar: registro,
+// schema si es dedicado, feature flags y rate limit por defecto y admin inicial
+type ProvisionTenantCommandHandler struct {
+	repository domain.TenantRepository
+	router     TenantRouter
+	cache      TenantCache
+	admins     AdminCreator
+	defaults   domain.Settings
+	eventBus   EventBus
+	txManager  TransactionManager
+}
+
+func NewProvisionTenantCommandHandler(
+	repo domain.TenantRepository,
+	router TenantRouter,
+	cache TenantCache,
+	admins AdminCreator,
+	defaults domain.Settings,
+	eventBus EventBus,
+	txManager TransactionManager,
+) *ProvisionTenantCommandHandler {
+	return &ProvisionTenantCommandHandler{
+		repository: repo,
+		router:     router,
+		cache:      cache,
+		admins:     admins,
+		defaults:   defaults,
+		eventBus:   eventBus,
+		txManager:  txManager,
+	}
+}
+
+// Handle devuelve el tenant y el id del admin inicial
+func (h *ProvisionTenantCommandHandler) Handle(ctx context.Context, cmd ProvisionTenantCommand) (*domain.Tenant, string, error) {
+	tenant, err := domain.NewTenant(cmd.ID, cmd.Name, domain.Plan(cmd.Plan), domain.Isolation(cmd.Isolation), h.defaults)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if _, err := h.repository.FindByID(ctx, tenant.ID()); err == nil {
+		return nil, "", domain.ErrTenantAlreadyExists
+	} else if !errors.Is(err, domain.ErrTenantNotFound) {
+		return nil, "", err
+	}
+
+	// el schema va antes que el registro para que el tenant no quede habilitado
+	// sin sus tablas. Crearlo es idempotente: si el alta falla, el reintento lo reusa
+	if tenant.HasDedicatedSchema() {
+		if err := h.router.ProvisionSchema(ctx, tenant.ID()); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// la transaccion corre en la conexion del tenant: registro, admin y eventos
+	// se confirman juntos aunque el admin viva en su schema
+	ctx, err = h.router.Scope(ctx, tenant.ID(), tenant.HasDedicatedSchema())
+	if err != nil {
+		return nil, "", err
+	}
+
+	var adminID string
+	err = h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := h.repository.Add(ctx, tenant); err != nil {
+			return err
+		}
+
+		adminID, err = h.admins.CreateAdmin(ctx, tenant.ID(), cmd.Admin, cmd.CorrelationID)
+		if err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewTenantProvisionedEvent(tenant, adminID, cmd.CorrelationID))
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// una request previa con este id pudo dejarlo cacheado como inexistente
+	h.cache.Invalidate(tenant.ID())
+
+	return tenant, adminID, nil
+}
+
+// CreateAdmin le da un primer admin a un tenant existente que no tiene ninguno
+func (h *ProvisionTenantCommandHandler) CreateAdmin(ctx context.Context, cmd CreateInitialAdminCommand) (string, error) {
+	tenant, err := h.repository.FindByID(ctx, cmd.TenantID)
+	if err != nil {
+		return "", err
+	}
+	if tenant.Status() == domain.StatusOffboarded {
+		return "", domain.ErrTenantNotFound
+	}
+
+	ctx, err = h.router.Scope(ctx, tenant.ID(), tenant.HasDedicatedSchema())
+	if err != nil {
+		return "", err
+	}
+
+	var adminID string
+	err = h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		adminID, err = h.admins.CreateAdmin(ctx, tenant.ID(), cmd.Admin, cmd.CorrelationID)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return adminID, nil
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type MockAdminCreator struct {
+	mock.Mock
+}
+
+func (m *MockAdminCreator) CreateAdmin(ctx context.Context, tenantID string, admin InitialAdmin, correlationID string) (string, error) {
+	args := m.Called(ctx, tenantID, admin, correlationID)
+	return args.String(0), args.Error(1)
+}
+
+// recordingCache anota los tenants invalidados
+type recordingCache struct {
+	invalidated []string
+}
+
+func (c *recordingCache) Invalidate(tenantID string) {
+	c.invalidated = append(c.invalidated, tenantID)
+}
+
+var testDefaults = domain.Settings{
+	Features:  map[string]bool{"mfa_required": false},
+	RateLimit: 100,
+}
+
+func newProvisionHandler() (*ProvisionTenantCommandHandler, *MockTenantRepository, *MockTenantRouter, *MockAdminCreator, *MockEventBus) {
+	mockRepo := new(MockTenantRepository)
+	mockRouter := new(MockTenantRouter)
+	mockAdmins := new(MockAdminCreator)
+	mockEventBus := new(MockEventBus)
+	handler := NewProvisionTenantCommandHandler(mockRepo, mockRouter, &recordingCache{}, mockAdmins, testDefaults, mockEventBus, &MockTransactionManager{})
+	return handler, mockRepo, mockRouter, mockAdmins, mockEventBus
+}
+
+func TestProvisionTenantCommandHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, mockEventBus := newProvisionHandler()
+
+	admin := InitialAdmin{Name: "Jane", Email: "jane@acme.com", Password: "Secret123!"}
+	mockRepo.On("FindByID", ctx, "acme").Return(nil, domain.ErrTenantNotFound)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", admin, "corr-1").Return("admin-1", nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.TenantProvisionedEvent")).Return(nil)
+
+	tenant, adminID, err := handler.Handle(ctx, ProvisionTenantCommand{
+		ID:            "acme",
+		Name:          "Acme",
+		Plan:          "pro",
+		Admin:         admin,
+		CorrelationID: "corr-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", adminID)
+	assert.Equal(t, domain.PlanPro, tenant.Plan())
+	assert.Equal(t, testDefaults, tenant.Settings())
+	mockRouter.AssertNotCalled(t, "ProvisionSchema", mock.Anything, mock.Anything)
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.TenantProvisionedEvent)
+	assert.Equal(t, domain.TenantProvisionedEventType, event.EventType())
+	assert.Equal(t, "acme", event.TenantID())
+	assert.Equal(t, "active", event.Status)
+	assert.Equal(t, "shared", event.Isolation)
+	assert.Equal(t, "admin-1", event.AdminUserID)
+	assert.Equal(t, []string{"acme"}, handler.cache.(*recordingCache).invalidated)
+}
+
+func TestProvisionTenantCommandHandler_Handle_DefaultsAreCopied(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, mockEventBus := newProvisionHandler()
+
+	mockRepo.On("FindByID", ctx, "acme").Return(nil, domain.ErrTenantNotFound)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", mock.Anything, mock.Anything).Return("admin-1", nil)
+	mockEventBus.On("Publish", ctx, mock.Anything).Return(nil)
+
+	tenant, _, err := handler.Handle(ctx, ProvisionTenantCommand{ID: "acme", Name: "Acme"})
+	assert.NoError(t, err)
+
+	// cambiar los flags de un tenant no toca los defaults de los proximos
+	tenant.Settings().Features["mfa_required"] = true
+	assert.False(t, testDefaults.Features["mfa_required"])
+}
+
+func TestProvisionTenantCommandHandler_Handle_DedicatedSchema(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, mockEventBus := newProvisionHandler()
+
+	mockRepo.On("FindByID", ctx, "acme").Return(nil, domain.ErrTenantNotFound)
+	mockRouter.On("ProvisionSchema", ctx, "acme").Return(nil)
+	mockRouter.On("Scope", ctx, "acme", true).Return(nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", mock.Anything, mock.Anything).Return("admin-1", nil)
+	mockEventBus.On("Publish", ctx, mock.Anything).Return(nil)
+
+	tenant, _, err := handler.Handle(ctx, ProvisionTenantCommand{ID: "acme", Name: "Acme", Plan: "enterprise", Isolation: "dedicated"})
+
+	assert.NoError(t, err)
+	assert.True(t, tenant.HasDedicatedSchema())
+	mockRouter.AssertExpectations(t)
+}
+
+func TestProvisionTenantCommandHandler_Handle_AlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, mockEventBus := newProvisionHandler()
+
+	existing, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	mockRepo.On("FindByID", ctx, "acme").Return(existing, nil)
+
+	_, _, err := handler.Handle(ctx, ProvisionTenantCommand{ID: "acme", Name: "Acme", Isolation: "dedicated"})
+
+	// no se crea un schema ni un admin para un tenant que ya existe
+	assert.ErrorIs(t, err, domain.ErrTenantAlreadyExists)
+	mockRouter.AssertNotCalled(t, "ProvisionSchema", mock.Anything, mock.Anything)
+	mockAdmins.AssertNotCalled(t, "CreateAdmin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestProvisionTenantCommandHandler_Handle_InvalidAdmin(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, mockEventBus := newProvisionHandler()
+
+	invalid := errors.Join(domain.ErrInvalidAdmin, errors.New("weak password"))
+	mockRepo.On("FindByID", ctx, "acme").Return(nil, domain.ErrTenantNotFound)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockRepo.On("Add", ctx, mock.AnythingOfType("*domain.Tenant")).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", mock.Anything, mock.Anything).Return("", invalid)
+
+	_, _, err := handler.Handle(ctx, ProvisionTenantCommand{ID: "acme", Name: "Acme"})
+
+	// el alta del tenant se deshace con la transaccion
+	assert.ErrorIs(t, err, domain.ErrInvalidAdmin)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestProvisionTenantCommandHandler_CreateAdmin(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, _ := newProvisionHandler()
+
+	tenant, _ := domain.NewTenant("acme", "Acme", domain.PlanPro, domain.IsolationDedicated, testDefaults)
+	admin := InitialAdmin{Name: "Jane", Email: "jane@acme.com", Password: "Secret123!"}
+	mockRepo.On("FindByID", ctx, "acme").Return(tenant, nil)
+	mockRouter.On("Scope", ctx, "acme", true).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", admin, "corr-1").Return("admin-1", nil)
+
+	adminID, err := handler.CreateAdmin(ctx, CreateInitialAdminCommand{TenantID: "acme", Admin: admin, CorrelationID: "corr-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "admin-1", adminID)
+}
+
+func TestProvisionTenantCommandHandler_CreateAdmin_TenantHasAdmin(t *testing.T) {
+	ctx := context.Background()
+	handler, mockRepo, mockRouter, mockAdmins, _ := newProvisionHandler()
+
+	tenant, _ := domain.NewTenant("acme", "Acme", domain.PlanPro, domain.IsolationShared, testDefaults)
+	mockRepo.On("FindByID", ctx, "acme").Return(tenant, nil)
+	mockRouter.On("Scope", ctx, "acme", false).Return(nil)
+	mockAdmins.On("CreateAdmin", ctx, "acme", mock.Anything, mock.Anything).Return("", domain.ErrTenantHasAdmin)
+
+	_, err := handler.CreateAdmin(ctx, CreateInitialAdminCommand{TenantID: "acme"})
+
+	assert.ErrorIs(t, err, domain.ErrTenantHasAdmin)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type EventBus interface {
+	Publish(ctx context.Context, event interface{}) error
+}
+
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TenantStatusCommand sirve para suspend y reactivate
+type TenantStatusCommand struct {
+	TenantID      string
+	CorrelationID string
+}
+
+// TenantRouter crea los schemas dedicados y deja en el context el tenant y la
+// conexion a sus datos. Lo implementa persistence.TenantRouter
+type TenantRouter interface {
+	ProvisionSchema(ctx context.Context, tenantID string) error
+	// DropSchema borra el schema dedicado y cierra su pool; se puede repetir
+	DropSchema(ctx context.Context, tenantID string) error
+	Scope(ctx context.Context, tenantID string, dedicated bool) (context.Context, error)
+}
+
+// TenantCommandHandler cambia el estado de tenants que ya existen; el alta y la
+// baja tienen sus propios handlers
+type TenantCommandHandler struct {
+	repository domain.TenantRepository
+	eventBus   EventBus
+	txManager  TransactionManager
+}
+
+func NewTenantCommandHandler(repo domain.TenantRepository, eventBus EventBus, txManager TransactionManager) *TenantCommandHandler {
+	return &TenantCommandHandler{
+		repository: repo,
+		eventBus:   eventBus,
+		txManager:  txManager,
+	}
+}
+
+func (h *TenantCommandHandler) Suspend(ctx context.Context, cmd TenantStatusCommand) error {
+	return h.transition(ctx, cmd, domain.TenantSuspendedEventType, (*domain.Tenant).Suspend)
+}
+
+func (h *TenantCommandHandler) Reactivate(ctx context.Context, cmd TenantStatusCommand) error {
+	return h.transition(ctx, cmd, domain.TenantReactivatedEventType, (*domain.Tenant).Reactivate)
+}
+
+func (h *TenantCommandHandler) transition(ctx context.Context, cmd TenantStatusCommand, eventType string, apply func(*domain.Tenant) error) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		// con el lock un reactivate no pisa un offboard que esta en curso
+		tenant, err := h.repository.FindByIDForUpdate(ctx, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		if err := apply(tenant); err != nil {
+			return err
+		}
+
+		if err := h.repository.Save(ctx, tenant); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewTenantChangedEvent(eventType, tenant, cmd.CorrelationID))
+	})
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) Add(ctx context.Context, tenant *domain.Tenant) error {
+	args := m.Called(ctx, tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) Save(ctx context.Context, tenant *domain.Tenant) error {
+	args := m.Called(ctx, tenant)
+	return args.Error(0)
+}
+
+func (m *MockTenantRepository) FindByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) FindByIDForUpdate(ctx context.Context, id string) (*domain.Tenant, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) FindAll(ctx context.Context) ([]*domain.Tenant, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Tenant), args.Error(1)
+}
+
+type MockTenantRouter struct {
+	mock.Mock
+}
+
+func (m *MockTenantRouter) ProvisionSchema(ctx context.Context, tenantID string) error {
+	args := m.Called(ctx, tenantID)
+	return args.Error(0)
+}
+
+func (m *MockTenantRouter) DropSchema(ctx context.Context, tenantID string) error {
+	args := m.Called(ctx, tenantID)
+	return args.Error(0)
+}
+
+// Scope devuelve el mismo context para que las expectativas sigan matcheando
+func (m *MockTenantRouter) Scope(ctx context.Context, tenantID string, dedicated bool) (context.Context, error) {
+	args := m.Called(ctx, tenantID, dedicated)
+	return ctx, args.Error(0)
+}
+
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Publish(ctx context.Context, event interface{}) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+type MockTransactionManager struct{}
+
+func (m *MockTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestTenantCommandHandler_Suspend(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockTenantRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewTenantCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{})
+
+	tenant, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	mockRepo.On("FindByIDForUpdate", ctx, "acme").Return(tenant, nil)
+	mockRepo.On("Save", ctx, tenant).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.TenantChangedEvent")).Return(nil)
+
+	err := handler.Suspend(ctx, TenantStatusCommand{TenantID: "acme"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusSuspended, tenant.Status())
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.TenantChangedEvent)
+	assert.Equal(t, domain.TenantSuspendedEventType, event.EventType())
+}
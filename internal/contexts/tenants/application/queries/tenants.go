@@ -0,0 +1,29 @@
+package queries
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type GetTenantQuery struct {
+	TenantID string
+}
+
+type TenantQueryHandler struct {
+	repository domain.TenantRepository
+}
+
+func NewTenantQueryHandler(repo domain.TenantRepository) *TenantQueryHandler {
+	return &TenantQueryHandler{
+		repository: repo,
+	}
+}
+
+func (h *TenantQueryHandler) Get(ctx context.Context, query GetTenantQuery) (*domain.Tenant, error) {
+	return h.repository.FindByID(ctx, query.TenantID)
+}
+
+func (h *TenantQueryHandler) List(ctx context.Context) ([]*domain.Tenant, error) {
+	return h.repository.FindAll(ctx)
+}
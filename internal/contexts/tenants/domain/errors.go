@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+var (
+	ErrTenantNotFound          = errors.New("tenant not found")
+	ErrTenantAlreadyExists     = errors.New("tenant already exists")
+	ErrInvalidTenantID         = errors.New("invalid tenant id")
+	ErrInvalidTenantName       = errors.New("invalid tenant name")
+	ErrInvalidPlan             = errors.New("invalid plan")
+	ErrInvalidIsolation        = errors.New("invalid isolation")
+	ErrInvalidRateLimit        = errors.New("invalid rate limit")
+	ErrInvalidAdmin            = errors.New("invalid initial admin")
+	ErrTenantHasAdmin          = errors.New("tenant already has an admin")
+	ErrInvalidStatusTransition = errors.New("invalid tenant status transition")
+)
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	shared "backend-challenge-guinea/internal/shared/domain"
+)
+
+const (
+	TenantProvisionedEventType = "tenant.provisioned"
+	TenantSuspendedEventType   = "tenant.suspended"
+	TenantReactivatedEventType = "tenant.reactivated"
+	TenantOffboardedEventType  = "tenant.offboarded"
+)
+
+// TenantChangedEvent se usa para todos los eventos del tenant, cambia solo el
+// tipo. Lleva el estado completo para que quien cachea no tenga que releer
+type TenantChangedEvent struct {
+	shared.BaseEvent
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Plan      string    `json:"plan"`
+	Isolation string    `json:"isolation"`
+	Settings  Settings  `json:"settings"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewTenantChangedEvent(eventType string, tenant *Tenant, correlationID string) TenantChangedEvent {
+	return TenantChangedEvent{
+		BaseEvent: shared.NewBaseEvent(eventType, tenant.ID(), tenant.ID(), correlationID),
+		Name:      tenant.Name(),
+		Status:    string(tenant.Status()),
+		Plan:      string(tenant.Plan()),
+		Isolation: string(tenant.Isolation()),
+		Settings:  tenant.Settings(),
+		UpdatedAt: tenant.UpdatedAt(),
+	}
+}
+
+// TenantProvisionedEvent: el tenant quedo creado con sus settings y su admin inicial
+type TenantProvisionedEvent struct {
+	TenantChangedEvent
+	AdminUserID string `json:"admin_user_id"`
+}
+
+func NewTenantProvisionedEvent(tenant *Tenant, adminUserID, correlationID string) TenantProvisionedEvent {
+	return TenantProvisionedEvent{
+		TenantChangedEvent: NewTenantChangedEvent(TenantProvisionedEventType, tenant, correlationID),
+		AdminUserID:        adminUserID,
+	}
+}
+
+// TenantOffboardedEvent: los datos del tenant se exportaron a ExportLocation y
+// se borraron; PurgedRows son las filas borradas por tabla
+type TenantOffboardedEvent struct {
+	TenantChangedEvent
+	ExportLocation string           `json:"export_location"`
+	PurgedRows     map[string]int64 `json:"purged_rows"`
+}
+
+func NewTenantOffboardedEvent(tenant *Tenant, exportLocation string, purgedRows map[string]int64, correlationID string) TenantOffboardedEvent {
+	return TenantOffboardedEvent{
+		TenantChangedEvent: NewTenantChangedEvent(TenantOffboardedEventType, tenant, correlationID),
+		ExportLocation:     exportLocation,
+		PurgedRows:         purgedRows,
+	}
+}
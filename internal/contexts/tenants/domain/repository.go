@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type TenantRepository interface {
+	// Add falla con ErrTenantAlreadyExists si el id ya esta tomado
+	Add(ctx context.Context, tenant *Tenant) error
+	Save(ctx context.Context, tenant *Tenant) error
+	FindByID(ctx context.Context, id string) (*Tenant, error)
+	// FindByIDForUpdate bloquea el tenant hasta el fin de la transaccion, para
+	// los cambios de estado que no se pueden pisar
+	FindByIDForUpdate(ctx context.Context, id string) (*Tenant, error)
+	FindAll(ctx context.Context) ([]*Tenant, error)
+}
+
+// ExportWriter recibe el export de un tenant fila por fila, agrupado por
+// tabla, para no tener todos sus datos en memoria
+type ExportWriter interface {
+	// Table empieza una tabla; las filas que siguen son de ella
+	Table(name string) error
+	Row(row json.RawMessage) error
+}
+
+// TenantDataRepository lee y borra los datos de un tenant en las tablas de
+// otros contextos
+type TenantDataRepository interface {
+	Export(ctx context.Context, tenantID string, w ExportWriter) error
+	// Purge devuelve cuantas filas borro de cada tabla
+	Purge(ctx context.Context, tenantID string) (map[string]int64, error)
+}
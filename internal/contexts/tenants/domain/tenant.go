@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+type TenantStatus string
+
+const (
+	StatusActive    TenantStatus = "active"
+	StatusSuspended TenantStatus = "suspended"
+	// StatusOffboarded es un tenant dado de baja: sus datos ya se exportaron y
+	// borraron, queda el registro para que el id no se reuse
+	StatusOffboarded TenantStatus = "offboarded"
+)
+
+type Plan string
+
+const (
+	PlanFree       Plan = "free"
+	PlanPro        Plan = "pro"
+	PlanEnterprise Plan = "enterprise"
+)
+
+// Isolation es donde viven los datos del tenant: en las tablas compartidas
+// (filtradas por tenant_id) o en un schema propio
+type Isolation string
+
+const (
+	IsolationShared    Isolation = "shared"
+	IsolationDedicated Isolation = "dedicated"
+)
+
+// Settings son los feature flags y el rate limit propios del tenant. Se fijan
+// al provisionarlo con los valores por defecto de ese momento
+type Settings struct {
+	Features map[string]bool `json:"features"`
+	// requests por minuto y ruta; 0 = el limite global
+	RateLimit int `json:"rate_limit"`
+}
+
+// el id es lo que viaja en X-Tenant-Id, por eso se limita a un slug
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,98}[a-z0-9]$`)
+
+// Tenant es una organizacion cliente; todos los datos de los demas contextos
+// cuelgan de su id
+type Tenant struct {
+	id        string
+	name      string
+	status    TenantStatus
+	plan      Plan
+	isolation Isolation
+	settings  Settings
+	createdAt time.Time
+	updatedAt time.Time
+}
+
+func NewTenant(id, name string, plan Plan, isolation Isolation, settings Settings) (*Tenant, error) {
+	if !tenantIDPattern.MatchString(id) {
+		return nil, ErrInvalidTenantID
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 200 {
+		return nil, ErrInvalidTenantName
+	}
+
+	if plan == "" {
+		plan = PlanFree
+	}
+	if !plan.IsValid() {
+		return nil, ErrInvalidPlan
+	}
+
+	if isolation == "" {
+		isolation = IsolationShared
+	}
+	if !isolation.IsValid() {
+		return nil, ErrInvalidIsolation
+	}
+
+	if settings.RateLimit < 0 {
+		return nil, ErrInvalidRateLimit
+	}
+
+	now := time.Now().UTC()
+	return &Tenant{
+		id:        id,
+		name:      name,
+		status:    StatusActive,
+		plan:      plan,
+		isolation: isolation,
+		settings:  settings.clone(),
+		createdAt: now,
+		updatedAt: now,
+	}, nil
+}
+
+// RebuildTenant reconstruye un tenant desde persistencia
+func RebuildTenant(id, name string, status TenantStatus, plan Plan, isolation Isolation, settings Settings, createdAt, updatedAt time.Time) *Tenant {
+	return &Tenant{
+		id:        id,
+		name:      name,
+		status:    status,
+		plan:      plan,
+		isolation: isolation,
+		settings:  settings,
+		createdAt: createdAt,
+		updatedAt: updatedAt,
+	}
+}
+
+func (p Plan) IsValid() bool {
+	return p == PlanFree || p == PlanPro || p == PlanEnterprise
+}
+
+func (i Isolation) IsValid() bool {
+	return i == IsolationShared || i == IsolationDedicated
+}
+
+// cada tenant tiene su propio map: los defaults se comparten entre altas
+func (s Settings) clone() Settings {
+	features := make(map[string]bool, len(s.Features))
+	for feature, enabled := range s.Features {
+		features[feature] = enabled
+	}
+	return Settings{Features: features, RateLimit: s.RateLimit}
+}
+
+// Suspend corta el acceso de todo el tenant sin borrar nada
+func (t *Tenant) Suspend() error {
+	if t.status != StatusActive {
+		return ErrInvalidStatusTransition
+	}
+	t.status = StatusSuspended
+	t.updatedAt = time.Now().UTC()
+	return nil
+}
+
+func (t *Tenant) Reactivate() error {
+	if t.status != StatusSuspended {
+		return ErrInvalidStatusTransition
+	}
+	t.status = StatusActive
+	t.updatedAt = time.Now().UTC()
+	return nil
+}
+
+// Offboard da de baja el tenant. Tiene que estar suspendido antes, asi nadie
+// escribe mientras se exportan y borran sus datos
+func (t *Tenant) Offboard() error {
+	if t.status != StatusSuspended {
+		return ErrInvalidStatusTransition
+	}
+	t.status = StatusOffboarded
+	t.updatedAt = time.Now().UTC()
+	return nil
+}
+
+func (t *Tenant) ID() string           { return t.id }
+func (t *Tenant) Name() string         { return t.name }
+func (t *Tenant) Status() TenantStatus { return t.status }
+func (t *Tenant) Plan() Plan           { return t.plan }
+func (t *Tenant) Isolation() Isolation { return t.isolation }
+func (t *Tenant) Settings() Settings   { return t.settings }
+func (t *Tenant) CreatedAt() time.Time { return t.createdAt }
+func (t *Tenant) UpdatedAt() time.Time { return t.updatedAt }
+func (t *Tenant) IsActive() bool       { return t.status == StatusActive }
+
+func (t *Tenant) HasDedicatedSchema() bool { return t.isolation == IsolationDedicated }
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTenant(t *testing.T) {
+	tenant, err := NewTenant("acme-corp", "  Acme Corp ", "", "", Settings{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme Corp", tenant.Name())
+	assert.Equal(t, PlanFree, tenant.Plan())
+	assert.Equal(t, IsolationShared, tenant.Isolation())
+	assert.True(t, tenant.IsActive())
+}
+
+func TestNewTenant_Validation(t *testing.T) {
+	_, err := NewTenant("Acme Corp", "Acme", PlanFree, IsolationShared, Settings{})
+	assert.ErrorIs(t, err, ErrInvalidTenantID)
+
+	_, err = NewTenant("acme", " ", PlanFree, IsolationShared, Settings{})
+	assert.ErrorIs(t, err, ErrInvalidTenantName)
+
+	_, err = NewTenant("acme", "Acme", Plan("gold"), IsolationShared, Settings{})
+	assert.ErrorIs(t, err, ErrInvalidPlan)
+
+	_, err = NewTenant("acme", "Acme", PlanEnterprise, Isolation("database"), Settings{})
+	assert.ErrorIs(t, err, ErrInvalidIsolation)
+
+	_, err = NewTenant("acme", "Acme", PlanFree, IsolationShared, Settings{RateLimit: -1})
+	assert.ErrorIs(t, err, ErrInvalidRateLimit)
+}
+
+func TestTenant_SuspendAndReactivate(t *testing.T) {
+	tenant, _ := NewTenant("acme", "Acme", PlanPro, IsolationShared, Settings{})
+
+	assert.NoError(t, tenant.Suspend())
+	assert.Equal(t, StatusSuspended, tenant.Status())
+	assert.ErrorIs(t, tenant.Suspend(), ErrInvalidStatusTransition)
+
+	assert.NoError(t, tenant.Reactivate())
+	assert.True(t, tenant.IsActive())
+	assert.ErrorIs(t, tenant.Reactivate(), ErrInvalidStatusTransition)
+}
+
+func TestTenant_Offboard(t *testing.T) {
+	tenant, _ := NewTenant("acme", "Acme", PlanPro, IsolationShared, Settings{})
+
+	// hay que suspenderlo antes
+	assert.ErrorIs(t, tenant.Offboard(), ErrInvalidStatusTransition)
+
+	assert.NoError(t, tenant.Suspend())
+	assert.NoError(t, tenant.Offboard())
+	assert.Equal(t, StatusOffboarded, tenant.Status())
+
+	// la baja no tiene vuelta atras
+	assert.ErrorIs(t, tenant.Reactivate(), ErrInvalidStatusTransition)
+	assert.ErrorIs(t, tenant.Suspend(), ErrInvalidStatusTransition)
+}
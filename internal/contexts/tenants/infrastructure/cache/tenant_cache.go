@@ -0,0 +1,182 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+// TenantCache valida el X-Tenant-Id de cada request sin ir a la base y le dice
+// al router de conexiones que tenants tienen schema dedicado. Las entradas
+// (tambien las de tenants que no existen) vencen a los ttl, y los eventos
+// tenant.* las actualizan antes en todas las instancias
+type TenantCache struct {
+	repository domain.TenantRepository
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	found     bool
+	status    domain.TenantStatus
+	dedicated bool
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+// maxCacheEntries acota la cache: los tenants que no existen los elige quien
+// manda la request, asi que sin tope cualquiera la puede hacer crecer
+const maxCacheEntries = 10000
+
+func NewTenantCache(repo domain.TenantRepository, ttl time.Duration) *TenantCache {
+	return &TenantCache{
+		repository: repo,
+		ttl:        ttl,
+		maxEntries: maxCacheEntries,
+		entries:    make(map[string]cacheEntry),
+	}
+}
+
+func (c *TenantCache) ValidateTenant(ctx context.Context, tenantID string) error {
+	entry, err := c.lookup(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	// un tenant dado de baja ya no tiene datos: para las requests no existe
+	if !entry.found || entry.status == domain.StatusOffboarded {
+		return middleware.ErrUnknownTenant
+	}
+	if entry.status != domain.StatusActive {
+		return middleware.ErrTenantSuspended
+	}
+	return nil
+}
+
+// HasDedicatedSchema dice si los datos del tenant viven en su propio schema.
+// No mira el estado: eso ya lo valida ValidateTenant
+func (c *TenantCache) HasDedicatedSchema(ctx context.Context, tenantID string) (bool, error) {
+	entry, err := c.lookup(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	if !entry.found {
+		return false, middleware.ErrUnknownTenant
+	}
+	return entry.dedicated, nil
+}
+
+// HandleEvent aplica un evento tenant.* (ya decodificado por el bus). El evento
+// trae el estado completo; uno mas viejo que lo cacheado se ignora
+func (c *TenantCache) HandleEvent(ctx context.Context, event interface{}) error {
+	payload, ok := event.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected tenant event %T", event)
+	}
+
+	tenantID, _ := payload["aggregate_id"].(string)
+	status, _ := payload["status"].(string)
+	isolation, _ := payload["isolation"].(string)
+	if tenantID == "" || status == "" {
+		return fmt.Errorf("tenant event without aggregate_id or status")
+	}
+
+	var updatedAt time.Time
+	if raw, ok := payload["updated_at"].(string); ok {
+		updatedAt, _ = time.Parse(time.RFC3339Nano, raw)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if current, ok := c.entries[tenantID]; ok && current.found && current.updatedAt.After(updatedAt) {
+		return nil
+	}
+	c.entries[tenantID] = cacheEntry{
+		found:     true,
+		status:    domain.TenantStatus(status),
+		dedicated: domain.Isolation(isolation) == domain.IsolationDedicated,
+		updatedAt: updatedAt,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+	return nil
+}
+
+// Invalidate descarta lo cacheado del tenant, por ejemplo al darlo de alta en
+// esta instancia, sin esperar al evento
+func (c *TenantCache) Invalidate(tenantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, tenantID)
+}
+
+func (c *TenantCache) lookup(ctx context.Context, tenantID string) (cacheEntry, error) {
+	if entry, ok := c.get(tenantID); ok {
+		return entry, nil
+	}
+	return c.load(ctx, tenantID)
+}
+
+func (c *TenantCache) get(tenantID string) (cacheEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[tenantID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+func (c *TenantCache) load(ctx context.Context, tenantID string) (cacheEntry, error) {
+	entry := cacheEntry{expiresAt: time.Now().Add(c.ttl)}
+
+	tenant, err := c.repository.FindByID(ctx, tenantID)
+	switch {
+	case err == nil:
+		entry.found = true
+		entry.status = tenant.Status()
+		entry.dedicated = tenant.HasDedicatedSchema()
+		entry.updatedAt = tenant.UpdatedAt()
+	case errors.Is(err, domain.ErrTenantNotFound):
+	default:
+		return cacheEntry{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// si mientras tanto llego un evento mas nuevo, gana el evento
+	if current, ok := c.entries[tenantID]; ok && current.found && current.updatedAt.After(entry.updatedAt) {
+		return current, nil
+	}
+
+	// con la cache llena se sacan las vencidas; si no alcanza, un tenant
+	// inexistente no se guarda y la proxima request vuelve a la base
+	if len(c.entries) >= c.maxEntries {
+		c.evictExpired()
+	}
+	if entry.found || len(c.entries) < c.maxEntries {
+		c.entries[tenantID] = entry
+	}
+	return entry, nil
+}
+
+func (c *TenantCache) evictExpired() {
+	now := time.Now()
+	for tenantID, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, tenantID)
+		}
+	}
+}
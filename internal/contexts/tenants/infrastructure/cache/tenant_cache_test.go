@@ -0,0 +1,190 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+// stubTenants cuenta las lecturas para ver cuando pega la cache
+type stubTenants struct {
+	tenants map[string]*domain.Tenant
+	reads   int
+}
+
+func (s *stubTenants) Add(ctx context.Context, tenant *domain.Tenant) error  { return nil }
+func (s *stubTenants) Save(ctx context.Context, tenant *domain.Tenant) error { return nil }
+func (s *stubTenants) FindAll(ctx context.Context) ([]*domain.Tenant, error) { return nil, nil }
+
+func (s *stubTenants) FindByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	s.reads++
+	if tenant, ok := s.tenants[id]; ok {
+		return tenant, nil
+	}
+	return nil, domain.ErrTenantNotFound
+}
+
+func (s *stubTenants) FindByIDForUpdate(ctx context.Context, id string) (*domain.Tenant, error) {
+	return s.FindByID(ctx, id)
+}
+
+func newStubTenants(t *testing.T) *stubTenants {
+	active, _ := domain.NewTenant("acme", "Acme", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	suspended, _ := domain.NewTenant("globex", "Globex", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	assert.NoError(t, suspended.Suspend())
+	dedicated, _ := domain.NewTenant("umbrella", "Umbrella", domain.PlanEnterprise, domain.IsolationDedicated, domain.Settings{})
+	offboarded, _ := domain.NewTenant("initech", "Initech", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	assert.NoError(t, offboarded.Suspend())
+	assert.NoError(t, offboarded.Offboard())
+
+	return &stubTenants{tenants: map[string]*domain.Tenant{
+		"acme":     active,
+		"globex":   suspended,
+		"umbrella": dedicated,
+		"initech":  offboarded,
+	}}
+}
+
+func TestTenantCache_ValidateTenant(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, time.Minute)
+
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "globex"), middleware.ErrTenantSuspended)
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "acme-typo"), middleware.ErrUnknownTenant)
+
+	// la segunda vuelta sale toda de la cache, incluido el tenant inexistente
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "acme-typo"), middleware.ErrUnknownTenant)
+	assert.Equal(t, 3, repo.reads)
+}
+
+func TestTenantCache_ValidateTenant_Offboarded(t *testing.T) {
+	cache := NewTenantCache(newStubTenants(t), time.Minute)
+
+	// el registro queda, pero para las requests es como si no existiera
+	assert.ErrorIs(t, cache.ValidateTenant(context.Background(), "initech"), middleware.ErrUnknownTenant)
+}
+
+func TestTenantCache_HasDedicatedSchema(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, time.Minute)
+
+	dedicated, err := cache.HasDedicatedSchema(ctx, "umbrella")
+	assert.NoError(t, err)
+	assert.True(t, dedicated)
+
+	dedicated, err = cache.HasDedicatedSchema(ctx, "acme")
+	assert.NoError(t, err)
+	assert.False(t, dedicated)
+
+	_, err = cache.HasDedicatedSchema(ctx, "acme-typo")
+	assert.ErrorIs(t, err, middleware.ErrUnknownTenant)
+
+	// comparte las entradas con ValidateTenant
+	assert.NoError(t, cache.ValidateTenant(ctx, "umbrella"))
+	assert.Equal(t, 3, repo.reads)
+}
+
+func TestTenantCache_ExpiredEntriesAreReloaded(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, 0)
+
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.Equal(t, 2, repo.reads)
+}
+
+func TestTenantCache_UnknownTenantsAreBounded(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, time.Hour)
+	cache.maxEntries = 2
+
+	for _, tenantID := range []string{"typo-1", "typo-2", "typo-3"} {
+		assert.ErrorIs(t, cache.ValidateTenant(ctx, tenantID), middleware.ErrUnknownTenant)
+	}
+	assert.Len(t, cache.entries, 2)
+
+	// un tenant que existe se guarda aunque la cache este llena
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.NoError(t, cache.ValidateTenant(ctx, "acme"))
+	assert.Equal(t, 4, repo.reads)
+}
+
+func TestTenantCache_EvictsExpiredEntriesWhenFull(t *testing.T) {
+	ctx := context.Background()
+	cache := NewTenantCache(newStubTenants(t), 0)
+	cache.maxEntries = 2
+
+	for _, tenantID := range []string{"typo-1", "typo-2", "typo-3"} {
+		assert.ErrorIs(t, cache.ValidateTenant(ctx, tenantID), middleware.ErrUnknownTenant)
+	}
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, "typo-3")
+}
+
+func TestTenantCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, time.Hour)
+
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "hooli"), middleware.ErrUnknownTenant)
+
+	hooli, _ := domain.NewTenant("hooli", "Hooli", domain.PlanFree, domain.IsolationShared, domain.Settings{})
+	repo.tenants["hooli"] = hooli
+	cache.Invalidate("hooli")
+
+	assert.NoError(t, cache.ValidateTenant(ctx, "hooli"))
+	assert.Equal(t, 2, repo.reads)
+}
+
+func TestTenantCache_HandleEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := newStubTenants(t)
+	cache := NewTenantCache(repo, time.Hour)
+
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "initech"), middleware.ErrUnknownTenant)
+
+	now := time.Now().UTC()
+	err := cache.HandleEvent(ctx, map[string]interface{}{
+		"type":         domain.TenantProvisionedEventType,
+		"aggregate_id": "initech",
+		"status":       "active",
+		"isolation":    "dedicated",
+		"updated_at":   now.Format(time.RFC3339Nano),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, cache.ValidateTenant(ctx, "initech"))
+	dedicated, err := cache.HasDedicatedSchema(ctx, "initech")
+	assert.NoError(t, err)
+	assert.True(t, dedicated)
+
+	err = cache.HandleEvent(ctx, map[string]interface{}{
+		"type":         domain.TenantSuspendedEventType,
+		"aggregate_id": "initech",
+		"status":       "suspended",
+		"updated_at":   now.Add(time.Second).Format(time.RFC3339Nano),
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "initech"), middleware.ErrTenantSuspended)
+
+	// un evento atrasado no pisa el estado mas nuevo
+	err = cache.HandleEvent(ctx, map[string]interface{}{
+		"type":         domain.TenantProvisionedEventType,
+		"aggregate_id": "initech",
+		"status":       "active",
+		"updated_at":   now.Format(time.RFC3339Nano),
+	})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, cache.ValidateTenant(ctx, "initech"), middleware.ErrTenantSuspended)
+	assert.Equal(t, 1, repo.reads)
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+// FeatureSetter y RateLimitSetter los implementan FeatureFlags y RateLimiter
+// del middleware
+type FeatureSetter interface {
+	SetFeature(tenantID, feature string, enabled bool)
+}
+
+type RateLimitSetter interface {
+	SetTenantLimit(tenantID string, requests int)
+}
+
+// TenantSettings lleva los settings de cada tenant a los feature flags y al
+// rate limiter, que son los que los aplican en cada request. Se carga al
+// arrancar y despues se mantiene con los eventos tenant.*
+type TenantSettings struct {
+	repository domain.TenantRepository
+	features   FeatureSetter
+	limits     RateLimitSetter
+}
+
+func NewTenantSettings(repo domain.TenantRepository, features FeatureSetter, limits RateLimitSetter) *TenantSettings {
+	return &TenantSettings{
+		repository: repo,
+		features:   features,
+		limits:     limits,
+	}
+}
+
+func (s *TenantSettings) Load(ctx context.Context) error {
+	tenants, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, tenant := range tenants {
+		s.apply(tenant.ID(), tenant.Status(), tenant.Settings())
+	}
+	return nil
+}
+
+// HandleEvent aplica los settings que trae un evento tenant.*
+func (s *TenantSettings) HandleEvent(ctx context.Context, event interface{}) error {
+	payload, ok := event.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected tenant event %T", event)
+	}
+
+	tenantID, _ := payload["aggregate_id"].(string)
+	status, _ := payload["status"].(string)
+	if tenantID == "" {
+		return fmt.Errorf("tenant event without aggregate_id")
+	}
+
+	// el bus ya decodifico el JSON a maps: se vuelve a pasar por JSON para
+	// leerlo con los tags de Settings
+	raw, err := json.Marshal(payload["settings"])
+	if err != nil {
+		return err
+	}
+	var settings domain.Settings
+	if err := json.Unmarshal(raw, &settings); err != nil {
+		return fmt.Errorf("invalid tenant settings: %w", err)
+	}
+
+	s.apply(tenantID, domain.TenantStatus(status), settings)
+	return nil
+}
+
+// los tenants dados de baja no reciben requests: alcanza con soltar su limite
+func (s *TenantSettings) apply(tenantID string, status domain.TenantStatus, settings domain.Settings) {
+	if status == domain.StatusOffboarded {
+		s.limits.SetTenantLimit(tenantID, 0)
+		return
+	}
+
+	for feature, enabled := range settings.Features {
+		s.features.SetFeature(tenantID, feature, enabled)
+	}
+	s.limits.SetTenantLimit(tenantID, settings.RateLimit)
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+type recordedSettings struct {
+	features map[string]map[string]bool
+	limits   map[string]int
+}
+
+func newRecordedSettings() *recordedSettings {
+	return &recordedSettings{
+		features: make(map[string]map[string]bool),
+		limits:   make(map[string]int),
+	}
+}
+
+func (r *recordedSettings) SetFeature(tenantID, feature string, enabled bool) {
+	if r.features[tenantID] == nil {
+		r.features[tenantID] = make(map[string]bool)
+	}
+	r.features[tenantID][feature] = enabled
+}
+
+func (r *recordedSettings) SetTenantLimit(tenantID string, requests int) {
+	r.limits[tenantID] = requests
+}
+
+type listedTenants struct {
+	stubTenants
+	all []*domain.Tenant
+}
+
+func (l *listedTenants) FindAll(ctx context.Context) ([]*domain.Tenant, error) {
+	return l.all, nil
+}
+
+func TestTenantSettings_Load(t *testing.T) {
+	acme, _ := domain.NewTenant("acme", "Acme", domain.PlanPro, domain.IsolationShared, domain.Settings{
+		Features:  map[string]bool{"mfa_required": true},
+		RateLimit: 500,
+	})
+	recorded := newRecordedSettings()
+	settings := NewTenantSettings(&listedTenants{all: []*domain.Tenant{acme}}, recorded, recorded)
+
+	assert.NoError(t, settings.Load(context.Background()))
+	assert.True(t, recorded.features["acme"]["mfa_required"])
+	assert.Equal(t, 500, recorded.limits["acme"])
+}
+
+func TestTenantSettings_HandleEvent(t *testing.T) {
+	recorded := newRecordedSettings()
+	settings := NewTenantSettings(&listedTenants{}, recorded, recorded)
+
+	// asi llega el payload despues de pasar por el bus
+	err := settings.HandleEvent(context.Background(), map[string]interface{}{
+		"aggregate_id": "acme",
+		"status":       "active",
+		"settings": map[string]interface{}{
+			"features":   map[string]interface{}{"self_signup": true},
+			"rate_limit": float64(250),
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, recorded.features["acme"]["self_signup"])
+	assert.Equal(t, 250, recorded.limits["acme"])
+
+	// la baja suelta el limite propio
+	err = settings.HandleEvent(context.Background(), map[string]interface{}{
+		"aggregate_id": "acme",
+		"status":       "offboarded",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, recorded.limits["acme"])
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+// FileExportStore guarda cada export como un .json en dir. Los exports tienen
+// datos personales: solo los puede leer el usuario del proceso
+type FileExportStore struct {
+	dir string
+}
+
+func NewFileExportStore(dir string) *FileExportStore {
+	return &FileExportStore{dir: dir}
+}
+
+// Store escribe las filas a un archivo temporal a medida que llegan y lo
+// renombra al terminar; si write falla el temporal se borra
+func (s *FileExportStore) Store(ctx context.Context, tenantID string, write func(w domain.ExportWriter) error) (string, error) {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return "", err
+	}
+
+	exportedAt := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.json", tenantID, exportedAt.Format("20060102T150405"))
+	path := filepath.Join(s.dir, name)
+
+	file, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeExport(file, tenantID, exportedAt, write); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	if err := os.Rename(file.Name(), path); err != nil {
+		_ = os.Remove(file.Name())
+		return "", err
+	}
+
+	return path, nil
+}
+
+func writeExport(file *os.File, tenantID string, exportedAt time.Time, write func(w domain.ExportWriter) error) error {
+	w := &jsonExportWriter{out: bufio.NewWriter(file)}
+
+	header, err := json.Marshal(struct {
+		TenantID   string    `json:"tenant_id"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{tenantID, exportedAt})
+	if err != nil {
+		return err
+	}
+
+	// {"tenant_id":...,"exported_at":...,"tables":{"tabla":[fila,...],...}}
+	w.out.Write(header[:len(header)-1])
+	w.out.WriteString(`,"tables":{`)
+
+	if err := write(w); err != nil {
+		return err
+	}
+
+	if w.table {
+		w.out.WriteString("]")
+	}
+	w.out.WriteString("}}\n")
+
+	if err := w.out.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// jsonExportWriter arma el JSON del export sobre la marcha; los errores de
+// escritura los devuelve el Flush del final
+type jsonExportWriter struct {
+	out   *bufio.Writer
+	table bool
+	rows  int
+}
+
+func (w *jsonExportWriter) Table(name string) error {
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+
+	if w.table {
+		w.out.WriteString("],")
+	}
+	w.out.Write(key)
+	w.out.WriteString(":[")
+
+	w.table = true
+	w.rows = 0
+	return nil
+}
+
+func (w *jsonExportWriter) Row(row json.RawMessage) error {
+	if !w.table {
+		return fmt.Errorf("export row without table")
+	}
+
+	if w.rows > 0 {
+		w.out.WriteString(",")
+	}
+	w.out.Write(row)
+
+	w.rows++
+	return nil
+}
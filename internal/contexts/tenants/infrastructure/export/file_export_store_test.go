@@ -0,0 +1,60 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+)
+
+func TestFileExportStore_Store(t *testing.T) {
+	store := NewFileExportStore(t.TempDir())
+
+	path, err := store.Store(context.Background(), "acme", func(w domain.ExportWriter) error {
+		require.NoError(t, w.Table("users_write"))
+		require.NoError(t, w.Row(json.RawMessage(`{"id":"u1"}`)))
+		require.NoError(t, w.Row(json.RawMessage(`{"id":"u2"}`)))
+		require.NoError(t, w.Table("sessions"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	body, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var export struct {
+		TenantID string                       `json:"tenant_id"`
+		Tables   map[string][]json.RawMessage `json:"tables"`
+	}
+	require.NoError(t, json.Unmarshal(body, &export))
+	assert.Equal(t, "acme", export.TenantID)
+	assert.Len(t, export.Tables["users_write"], 2)
+	assert.Empty(t, export.Tables["sessions"])
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFileExportStore_Store_RemovesPartialExport(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileExportStore(dir)
+
+	failure := errors.New("connection lost")
+	_, err := store.Store(context.Background(), "acme", func(w domain.ExportWriter) error {
+		require.NoError(t, w.Table("users_write"))
+		require.NoError(t, w.Row(json.RawMessage(`{"id":"u1"}`)))
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,246 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend-challenge-guinea/internal/contexts/tenants/application/commands"
+	"backend-challenge-guinea/internal/contexts/tenants/application/queries"
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+// TenantHandlers es la API de administracion de tenants; la usa el operador
+// de la plataforma, no los usuarios de un tenant
+type TenantHandlers struct {
+	tenantHandler      *commands.TenantCommandHandler
+	provisionHandler   *commands.ProvisionTenantCommandHandler
+	offboardHandler    *commands.OffboardTenantCommandHandler
+	tenantQueryHandler *queries.TenantQueryHandler
+}
+
+func NewTenantHandlers(
+	tenantHandler *commands.TenantCommandHandler,
+	provisionHandler *commands.ProvisionTenantCommandHandler,
+	offboardHandler *commands.OffboardTenantCommandHandler,
+	tenantQueryHandler *queries.TenantQueryHandler,
+) *TenantHandlers {
+	return &TenantHandlers{
+		tenantHandler:      tenantHandler,
+		provisionHandler:   provisionHandler,
+		offboardHandler:    offboardHandler,
+		tenantQueryHandler: tenantQueryHandler,
+	}
+}
+
+type InitialAdminRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ProvisionTenantRequest struct {
+	ID        string              `json:"id" binding:"required"`
+	Name      string              `json:"name" binding:"required"`
+	Plan      string              `json:"plan"`
+	Isolation string              `json:"isolation"`
+	Admin     InitialAdminRequest `json:"admin" binding:"required"`
+}
+
+type TenantResponse struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Status    string          `json:"status"`
+	Plan      string          `json:"plan"`
+	Isolation string          `json:"isolation"`
+	Settings  domain.Settings `json:"settings"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ProvisionTenantResponse struct {
+	TenantResponse
+	AdminUserID string `json:"admin_user_id"`
+}
+
+func newTenantResponse(tenant *domain.Tenant) TenantResponse {
+	return TenantResponse{
+		ID:        tenant.ID(),
+		Name:      tenant.Name(),
+		Status:    string(tenant.Status()),
+		Plan:      string(tenant.Plan()),
+		Isolation: string(tenant.Isolation()),
+		Settings:  tenant.Settings(),
+		CreatedAt: tenant.CreatedAt(),
+		UpdatedAt: tenant.UpdatedAt(),
+	}
+}
+
+// ProvisionTenant da de alta el tenant con su primer admin
+func (h *TenantHandlers) ProvisionTenant(c *gin.Context) {
+	var req ProvisionTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tenant, adminID, err := h.provisionHandler.Handle(c.Request.Context(), commands.ProvisionTenantCommand{
+		ID:        req.ID,
+		Name:      req.Name,
+		Plan:      req.Plan,
+		Isolation: req.Isolation,
+		Admin: commands.InitialAdmin{
+			Name:     req.Admin.Name,
+			Email:    req.Admin.Email,
+			Password: req.Admin.Password,
+		},
+		CorrelationID: middleware.GetCorrelationID(c),
+	})
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ProvisionTenantResponse{
+		TenantResponse: newTenantResponse(tenant),
+		AdminUserID:    adminID,
+	})
+}
+
+// CreateAdmin da un primer admin a un tenant que no tiene ninguno activo
+func (h *TenantHandlers) CreateAdmin(c *gin.Context) {
+	var req InitialAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	adminID, err := h.provisionHandler.CreateAdmin(c.Request.Context(), commands.CreateInitialAdminCommand{
+		TenantID: c.Param("id"),
+		Admin: commands.InitialAdmin{
+			Name:     req.Name,
+			Email:    req.Email,
+			Password: req.Password,
+		},
+		CorrelationID: middleware.GetCorrelationID(c),
+	})
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"admin_user_id": adminID,
+	})
+}
+
+func (h *TenantHandlers) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantQueryHandler.List(c.Request.Context())
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	response := make([]TenantResponse, len(tenants))
+	for i, tenant := range tenants {
+		response[i] = newTenantResponse(tenant)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tenants": response,
+	})
+}
+
+func (h *TenantHandlers) GetTenant(c *gin.Context) {
+	tenant, err := h.tenantQueryHandler.Get(c.Request.Context(), queries.GetTenantQuery{TenantID: c.Param("id")})
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newTenantResponse(tenant))
+}
+
+func (h *TenantHandlers) SuspendTenant(c *gin.Context) {
+	h.changeStatus(c, h.tenantHandler.Suspend)
+}
+
+func (h *TenantHandlers) ReactivateTenant(c *gin.Context) {
+	h.changeStatus(c, h.tenantHandler.Reactivate)
+}
+
+func (h *TenantHandlers) changeStatus(c *gin.Context, handle func(ctx context.Context, cmd commands.TenantStatusCommand) error) {
+	cmd := commands.TenantStatusCommand{
+		TenantID:      c.Param("id"),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := handle(c.Request.Context(), cmd); err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// OffboardTenant exporta y borra los datos de un tenant suspendido
+func (h *TenantHandlers) OffboardTenant(c *gin.Context) {
+	location, err := h.offboardHandler.Handle(c.Request.Context(), commands.OffboardTenantCommand{
+		TenantID:      c.Param("id"),
+		CorrelationID: middleware.GetCorrelationID(c),
+	})
+	if err != nil {
+		respondTenantError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"export_location": location,
+	})
+}
+
+func respondTenantError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrTenantNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrTenantAlreadyExists), errors.Is(err, domain.ErrInvalidStatusTransition), errors.Is(err, domain.ErrTenantHasAdmin):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidTenantID), errors.Is(err, domain.ErrInvalidTenantName), errors.Is(err, domain.ErrInvalidPlan),
+		errors.Is(err, domain.ErrInvalidIsolation), errors.Is(err, domain.ErrInvalidRateLimit), errors.Is(err, domain.ErrInvalidAdmin):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+// las rutas de admin no llevan X-Tenant-Id: operan sobre cualquier tenant
+func (h *TenantHandlers) RegisterRoutes(router *gin.Engine, adminMiddleware gin.HandlerFunc) {
+	tenants := router.Group("/api/v1/admin/tenants")
+
+	tenants.Use(middleware.CorrelationIDMiddleware())
+	tenants.Use(adminMiddleware)
+
+	tenants.POST("", h.ProvisionTenant)
+	tenants.GET("", h.ListTenants)
+	tenants.GET("/:id", h.GetTenant)
+	tenants.POST("/:id/suspend", h.SuspendTenant)
+	tenants.POST("/:id/reactivate", h.ReactivateTenant)
+	tenants.POST("/:id/offboard", h.OffboardTenant)
+	tenants.POST("/:id/admin", h.CreateAdmin)
+}
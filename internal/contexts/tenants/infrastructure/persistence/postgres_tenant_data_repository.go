@@ -0,0 +1,116 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// tenantTable es una tabla con columna tenant_id. Secrets son las columnas que
+// no salen en el export: el export sale de la base y no tiene que llevarse
+// credenciales ni hashes de tokens
+type tenantTable struct {
+	name    string
+	secrets []string
+}
+
+// todas las tablas con datos del tenant, en el orden en que se borran (las
+// hijas antes que users_write). Las de notifications y outbox quedan siempre
+// en public; el resto, en el schema dedicado si el tenant tiene uno. Una tabla
+// nueva con tenant_id va en esta lista
+var tenantDataTables = []tenantTable{
+	{name: "idempotency_keys"},
+	{name: "refresh_tokens", secrets: []string{"token_hash"}},
+	{name: "sessions", secrets: []string{"token_hash"}},
+	{name: "mfa_challenges", secrets: []string{"token_hash"}},
+	{name: "mfa_enrollments", secrets: []string{"secret", "recovery_code_hashes"}},
+	{name: "login_attempts"},
+	{name: "user_tokens", secrets: []string{"token_hash"}},
+	{name: "password_history", secrets: []string{"password_hash"}},
+	{name: "user_roles"},
+	{name: "roles"},
+	{name: "password_policies"},
+	{name: "users_read"},
+	{name: "users_write", secrets: []string{"password_hash"}},
+	{name: "notifications"},
+	{name: "notification_templates"},
+	{name: "notification_settings"},
+	{name: "outbox"},
+}
+
+// PostgresTenantDataRepository trabaja sobre las tablas de otros contextos; el
+// schema (compartido o dedicado) lo decide el pool del context
+type PostgresTenantDataRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTenantDataRepository(db *sql.DB) *PostgresTenantDataRepository {
+	return &PostgresTenantDataRepository{db: db}
+}
+
+// Export pasa cada fila como JSON a w mientras la lee
+func (r *PostgresTenantDataRepository) Export(ctx context.Context, tenantID string, w domain.ExportWriter) error {
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		for _, table := range tenantDataTables {
+			if err := w.Table(table.name); err != nil {
+				return err
+			}
+			if err := exportTable(ctx, executor, table, tenantID, w); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func exportTable(ctx context.Context, executor persistence.Executor, table tenantTable, tenantID string, w domain.ExportWriter) error {
+	query := fmt.Sprintf(`SELECT to_jsonb(t) - $2::text[] FROM %s t WHERE tenant_id = $1`, table.name)
+
+	rows, err := executor.QueryContext(ctx, query, tenantID, pq.Array(table.secrets))
+	if err != nil {
+		return fmt.Errorf("failed to export %s: %w", table.name, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return err
+		}
+		if err := w.Row(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresTenantDataRepository) Purge(ctx context.Context, tenantID string) (map[string]int64, error) {
+	purged := make(map[string]int64, len(tenantDataTables))
+
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		for _, table := range tenantDataTables {
+			result, err := executor.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE tenant_id = $1`, table.name), tenantID)
+			if err != nil {
+				return fmt.Errorf("failed to purge %s: %w", table.name, err)
+			}
+
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			purged[table.name] = affected
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
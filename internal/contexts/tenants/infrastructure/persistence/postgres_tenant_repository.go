@@ -0,0 +1,170 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresTenantRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresTenantRepository(db *sql.DB) *PostgresTenantRepository {
+	return &PostgresTenantRepository{db: db}
+}
+
+func (r *PostgresTenantRepository) Add(ctx context.Context, tenant *domain.Tenant) error {
+	query := `
+		INSERT INTO tenants (id, name, status, plan, isolation, settings, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	settings, err := json.Marshal(tenant.Settings())
+	if err != nil {
+		return err
+	}
+
+	result, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		tenant.ID(),
+		tenant.Name(),
+		string(tenant.Status()),
+		string(tenant.Plan()),
+		string(tenant.Isolation()),
+		settings,
+		tenant.CreatedAt(),
+		tenant.UpdatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTenantAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *PostgresTenantRepository) Save(ctx context.Context, tenant *domain.Tenant) error {
+	query := `
+		UPDATE tenants
+		SET name = $2, status = $3, plan = $4, updated_at = $5
+		WHERE id = $1
+	`
+
+	result, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		tenant.ID(),
+		tenant.Name(),
+		string(tenant.Status()),
+		string(tenant.Plan()),
+		tenant.UpdatedAt(),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrTenantNotFound
+	}
+
+	return nil
+}
+
+func (r *PostgresTenantRepository) FindByID(ctx context.Context, id string) (*domain.Tenant, error) {
+	query := `
+		SELECT id, name, status, plan, isolation, settings, created_at, updated_at
+		FROM tenants
+		WHERE id = $1
+	`
+
+	tenant, err := scanTenant(persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (r *PostgresTenantRepository) FindByIDForUpdate(ctx context.Context, id string) (*domain.Tenant, error) {
+	query := `
+		SELECT id, name, status, plan, isolation, settings, created_at, updated_at
+		FROM tenants
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	tenant, err := scanTenant(persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	return tenant, nil
+}
+
+func (r *PostgresTenantRepository) FindAll(ctx context.Context) ([]*domain.Tenant, error) {
+	query := `
+		SELECT id, name, status, plan, isolation, settings, created_at, updated_at
+		FROM tenants
+		ORDER BY id
+	`
+
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := make([]*domain.Tenant, 0)
+	for rows.Next() {
+		tenant, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTenant(row rowScanner) (*domain.Tenant, error) {
+	var (
+		id, name, status, plan, isolation string
+		rawSettings                       []byte
+		createdAt, updatedAt              time.Time
+	)
+	if err := row.Scan(&id, &name, &status, &plan, &isolation, &rawSettings, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+
+	var settings domain.Settings
+	if err := json.Unmarshal(rawSettings, &settings); err != nil {
+		return nil, err
+	}
+
+	return domain.RebuildTenant(id, name, domain.TenantStatus(status), domain.Plan(plan), domain.Isolation(isolation), settings, createdAt, updatedAt), nil
+}
@@ -0,0 +1,45 @@
+package provisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"backend-challenge-guinea/internal/contexts/tenants/application/commands"
+	"backend-challenge-guinea/internal/contexts/tenants/domain"
+	userCommands "backend-challenge-guinea/internal/contexts/users/application/commands"
+	userDomain "backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+// AdminCreator crea el primer admin del tenant con los comandos de users, asi
+// pasa por las mismas validaciones y eventos que cualquier alta
+type AdminCreator struct {
+	handler *userCommands.TenantAdminCommandHandler
+}
+
+func NewAdminCreator(handler *userCommands.TenantAdminCommandHandler) *AdminCreator {
+	return &AdminCreator{handler: handler}
+}
+
+func (a *AdminCreator) CreateAdmin(ctx context.Context, tenantID string, admin commands.InitialAdmin, correlationID string) (string, error) {
+	userID, err := a.handler.Handle(ctx, userCommands.CreateTenantAdminCommand{
+		TenantID:      tenantID,
+		Name:          admin.Name,
+		Email:         admin.Email,
+		Password:      admin.Password,
+		CorrelationID: correlationID,
+	})
+	if err != nil {
+		// los datos invalidos del admin son un error del request, no del alta
+		switch {
+		case errors.Is(err, vo.ErrWeakPassword), errors.Is(err, userDomain.ErrInvalidUserName), errors.Is(err, userDomain.ErrUserAlreadyExists):
+			return "", fmt.Errorf("%w: %v", domain.ErrInvalidAdmin, err)
+		case errors.Is(err, userDomain.ErrTenantHasAdmin):
+			return "", domain.ErrTenantHasAdmin
+		}
+		return "", err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+// RequestEmailChangeCommand no cambia el email: genera un token y lo manda al
+// nuevo email. El cambio se aplica con ConfirmEmailChangeCommand
+type RequestEmailChangeCommand struct {
+	UserID        string
+	TenantID      string
+	NewEmail      string
+	CorrelationID string
+}
+
+type ConfirmEmailChangeCommand struct {
+	Token         string
+	TenantID      string
+	CorrelationID string
+}
+
+type ChangeEmailCommandHandler struct {
+	repository domain.UserRepository
+	tokenRepo  domain.UserTokenRepository
+	eventBus   EventBus
+	notifier   TokenNotifier
+	txManager  TransactionManager
+	tokenTTL   time.Duration
+}
+
+func NewChangeEmailCommandHandler(
+	repo domain.UserRepository,
+	tokenRepo domain.UserTokenRepository,
+	eventBus EventBus,
+	notifier TokenNotifier,
+	txManager TransactionManager,
+	tokenTTL time.Duration,
+) *ChangeEmailCommandHandler {
+	return &ChangeEmailCommandHandler{
+		repository: repo,
+		tokenRepo:  tokenRepo,
+		eventBus:   eventBus,
+		notifier:   notifier,
+		txManager:  txManager,
+		tokenTTL:   tokenTTL,
+	}
+}
+
+func (h *ChangeEmailCommandHandler) Request(ctx context.Context, cmd RequestEmailChangeCommand) error {
+	email, err := vo.NewEmail(cmd.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+		if user.Email().Value() == email.Value() {
+			return domain.ErrEmailUnchanged
+		}
+
+		// chequeo temprano para avisar ya; la unicidad real se valida al confirmar
+		exists, err := h.repository.ExistsByEmail(ctx, email.Value(), cmd.TenantID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrUserAlreadyExists
+		}
+
+		// solo vale el ultimo link pedido
+		if err := h.tokenRepo.InvalidateForUser(ctx, user.ID(), cmd.TenantID, domain.TokenPurposeEmailChange); err != nil {
+			return err
+		}
+
+		token, err := domain.NewEmailChangeToken(user.ID(), cmd.TenantID, email.Value(), h.tokenTTL)
+		if err != nil {
+			return err
+		}
+
+		if err := h.tokenRepo.Save(ctx, token); err != nil {
+			return err
+		}
+
+		event := domain.NewUserEmailChangeRequestedEvent(token, cmd.CorrelationID)
+		if err := h.eventBus.Publish(ctx, event); err != nil {
+			return err
+		}
+
+		// el link va al email nuevo: recibirlo prueba que es del usuario
+		return h.notifier.NotifyToken(ctx, newTokenNotice(event.EventID(), token, email.Value(), cmd.CorrelationID))
+	})
+}
+
+func (h *ChangeEmailCommandHandler) Confirm(ctx context.Context, cmd ConfirmEmailChangeCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := h.tokenRepo.FindByHash(ctx, domain.HashUserToken(cmd.Token), domain.TokenPurposeEmailChange)
+		if err != nil {
+			return err
+		}
+		if token.TenantID() != cmd.TenantID {
+			return domain.ErrInvalidToken
+		}
+		if err := token.Use(); err != nil {
+			return err
+		}
+
+		user, err := h.repository.FindByID(ctx, token.UserID(), token.TenantID())
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+
+		email, err := vo.NewEmail(token.NewEmail())
+		if err != nil {
+			return err
+		}
+
+		// entre el pedido y la confirmacion otro usuario pudo tomar el email
+		exists, err := h.repository.ExistsByEmail(ctx, email.Value(), token.TenantID())
+		if err != nil {
+			return err
+		}
+		if exists {
+			return domain.ErrUserAlreadyExists
+		}
+
+		oldEmail := user.Email().Value()
+		if err := user.ChangeEmail(email); err != nil {
+			return err
+		}
+
+		if err := h.tokenRepo.Save(ctx, token); err != nil {
+			return err
+		}
+		// el nuevo email ya quedo verificado, los links pendientes del anterior no sirven mas
+		if err := h.tokenRepo.InvalidateForUser(ctx, user.ID(), user.TenantID(), domain.TokenPurposeEmailVerification); err != nil {
+			return err
+		}
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserEmailChangedEvent(user, oldEmail, cmd.CorrelationID))
+	})
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) Save(ctx context.Context, token *domain.UserToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) FindByHash(ctx context.Context, tokenHash string, purpose domain.TokenPurpose) (*domain.UserToken, error) {
+	args := m.Called(ctx, tokenHash, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserToken), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) InvalidateForUser(ctx context.Context, userID, tenantID string, purpose domain.TokenPurpose) error {
+	args := m.Called(ctx, userID, tenantID, purpose)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) LastIssuedAt(ctx context.Context, userID, tenantID string, purpose domain.TokenPurpose) (*time.Time, error) {
+	args := m.Called(ctx, userID, tenantID, purpose)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*time.Time), args.Error(1)
+}
+
+type MockTokenNotifier struct {
+	mock.Mock
+}
+
+func (m *MockTokenNotifier) NotifyToken(ctx context.Context, notice TokenNotice) error {
+	args := m.Called(ctx, notice)
+	return args.Error(0)
+}
+
+func TestChangeEmailCommandHandler_Request(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	mockNotifier := new(MockTokenNotifier)
+	handler := NewChangeEmailCommandHandler(mockRepo, mockTokens, mockEventBus, mockNotifier, &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("ExistsByEmail", ctx, "new@example.com", "tenant-1").Return(false, nil)
+	mockTokens.On("InvalidateForUser", ctx, user.ID(), "tenant-1", domain.TokenPurposeEmailChange).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserEmailChangeRequestedEvent")).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
+
+	err := handler.Request(ctx, RequestEmailChangeCommand{
+		UserID:   user.ID(),
+		TenantID: "tenant-1",
+		NewEmail: "new@example.com",
+	})
+
+	assert.NoError(t, err)
+	// el email no cambia hasta confirmar
+	assert.Equal(t, "test@example.com", user.Email().Value())
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+
+	token := mockTokens.Calls[1].Arguments.Get(1).(*domain.UserToken)
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserEmailChangeRequestedEvent)
+	notice := mockNotifier.Calls[0].Arguments.Get(1).(TokenNotice)
+	assert.Equal(t, "new@example.com", event.NewEmail)
+	// el token en claro solo llega al notificador, no al evento
+	assert.Equal(t, event.EventID(), notice.EventID)
+	assert.Equal(t, "new@example.com", notice.Recipient)
+	assert.Equal(t, domain.HashUserToken(notice.Token), token.TokenHash())
+}
+
+func TestChangeEmailCommandHandler_RequestEmailTaken(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewChangeEmailCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("ExistsByEmail", ctx, "taken@example.com", "tenant-1").Return(true, nil)
+
+	err := handler.Request(ctx, RequestEmailChangeCommand{
+		UserID:   user.ID(),
+		TenantID: "tenant-1",
+		NewEmail: "taken@example.com",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	mockTokens.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestChangeEmailCommandHandler_Confirm(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewChangeEmailCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+	token, _ := domain.NewEmailChangeToken(user.ID(), "tenant-1", "new@example.com", time.Hour)
+
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposeEmailChange).Return(token, nil)
+	mockTokens.On("Save", ctx, token).Return(nil)
+	mockTokens.On("InvalidateForUser", ctx, user.ID(), "tenant-1", domain.TokenPurposeEmailVerification).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("ExistsByEmail", ctx, "new@example.com", "tenant-1").Return(false, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserEmailChangedEvent")).Return(nil)
+
+	err := handler.Confirm(ctx, ConfirmEmailChangeCommand{Token: token.Token(), TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email().Value())
+	assert.True(t, user.IsEmailVerified())
+	assert.NotNil(t, token.UsedAt())
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserEmailChangedEvent)
+	assert.Equal(t, "test@example.com", event.OldEmail)
+	assert.Equal(t, "new@example.com", event.NewEmail)
+}
+
+func TestChangeEmailCommandHandler_ConfirmEmailTakenMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewChangeEmailCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+	token, _ := domain.NewEmailChangeToken(user.ID(), "tenant-1", "new@example.com", time.Hour)
+
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposeEmailChange).Return(token, nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("ExistsByEmail", ctx, "new@example.com", "tenant-1").Return(true, nil)
+
+	err := handler.Confirm(ctx, ConfirmEmailChangeCommand{Token: token.Token(), TenantID: "tenant-1"})
+
+	assert.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	assert.Equal(t, "test@example.com", user.Email().Value())
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestChangeEmailCommandHandler_ConfirmOtherTenant(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	handler := NewChangeEmailCommandHandler(mockRepo, mockTokens, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour)
+
+	token, _ := domain.NewEmailChangeToken("user-1", "tenant-1", "new@example.com", time.Hour)
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposeEmailChange).Return(token, nil)
+
+	err := handler.Confirm(ctx, ConfirmEmailChangeCommand{Token: token.Token(), TenantID: "tenant-2"})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"time"
 
 	"backend-challenge-guinea/internal/contexts/users/domain"
 	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
@@ -23,17 +24,38 @@ type CreateUserCommandHandler struct {
 	repository      domain.UserRepository    
 	eventBus        EventBus                 
 	idempotencyRepo IdempotencyRepository    
+	txManager       TransactionManager
+	tokenRepo       domain.UserTokenRepository
+	notifier        TokenNotifier
+	policies        domain.PasswordPolicyRepository
+	screener        domain.PasswordScreener
+	hasher          *vo.PasswordHasher
+	verificationTTL time.Duration
 }
 
 func NewCreateUserCommandHandler(
 	repo domain.UserRepository,
 	eventBus EventBus,
 	idempotencyRepo IdempotencyRepository,
+	txManager TransactionManager,
+	tokenRepo domain.UserTokenRepository,
+	notifier TokenNotifier,
+	policies domain.PasswordPolicyRepository,
+	screener domain.PasswordScreener,
+	hasher *vo.PasswordHasher,
+	verificationTTL time.Duration,
 ) *CreateUserCommandHandler {
 	return &CreateUserCommandHandler{
 		repository:      repo,
 		eventBus:        eventBus,
 		idempotencyRepo: idempotencyRepo,
+		txManager:       txManager,
+		tokenRepo:       tokenRepo,
+		notifier:        notifier,
+		policies:        policies,
+		screener:        screener,
+		hasher:          hasher,
+		verificationTTL: verificationTTL,
 	}
 }
 
@@ -63,26 +85,21 @@ func (h *CreateUserCommandHandler) Handle(ctx context.Context, cmd CreateUserCom
 		return "", domain.ErrUserAlreadyExists
 	}
 
-	password, err := vo.NewPassword(cmd.Password)
+	policy, err := h.policies.FindByTenant(ctx, cmd.TenantID)
 	if err != nil {
-		return "", err 
+		return "", err
 	}
 
-	user, err := domain.NewUser(cmd.Name, email, password, cmd.TenantID, cmd.DisplayName)
+	password, err := newPassword(ctx, policy, h.screener, h.hasher, cmd.Password, cmd.Name, email.Value())
 	if err != nil {
 		return "", err
 	}
 
-	if err := h.repository.Save(ctx, user); err != nil {
+	user, err := domain.NewUser(cmd.Name, email, password, cmd.TenantID, cmd.DisplayName)
+	if err != nil {
 		return "", err
 	}
 
-
-	if cmd.IdempotencyKey != "" {
-		if err := h.idempotencyRepo.Store(ctx, cmd.IdempotencyKey, cmd.TenantID, user.ID()); err != nil {
-		}
-	}
-
 	event := domain.NewUserCreatedEvent(
 		user.ID(),
 		user.Name(),
@@ -92,9 +109,28 @@ func (h *CreateUserCommandHandler) Handle(ctx context.Context, cmd CreateUserCom
 		user.DisplayName(),
 	)
 
-	if err := h.eventBus.Publish(ctx, event); err != nil {
+	// usuario, idempotency key y evento van en la misma transaccion (outbox),
+	// asi el evento no se pierde si RabbitMQ esta caido
+	err = h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		if cmd.IdempotencyKey != "" {
+			if err := h.idempotencyRepo.Store(ctx, cmd.IdempotencyKey, cmd.TenantID, user.ID()); err != nil {
+				return err
+			}
+		}
+
+		if err := h.eventBus.Publish(ctx, event); err != nil {
+			return err
+		}
 
-		return user.ID(), err
+		// el usuario nace sin verificar; el link sale con el mismo commit
+		return issueEmailVerification(ctx, h.tokenRepo, h.eventBus, h.notifier, user, h.verificationTTL, cmd.CorrelationID)
+	})
+	if err != nil {
+		return "", err
 	}
 
 	return user.ID(), nil
@@ -104,6 +140,10 @@ type EventBus interface {
 	Publish(ctx context.Context, event interface{}) error
 }
 
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
 type IdempotencyRepository interface {
 	IsProcessed(ctx context.Context, key, tenantID string) (bool, string, error)
 	Store(ctx context.Context, key, tenantID, result string) error
@@ -2,12 +2,15 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	
 	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 )
 
 type MockUserRepository struct {
@@ -40,6 +43,11 @@ func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email, tenantID
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockUserRepository) UpdatePasswordHash(ctx context.Context, id, tenantID, currentHash, newHash string) error {
+	args := m.Called(ctx, id, tenantID, currentHash, newHash)
+	return args.Error(0)
+}
+
 type MockEventBus struct {
 	mock.Mock
 }
@@ -63,6 +71,39 @@ func (m *MockIdempotencyRepository) Store(ctx context.Context, key, tenantID, re
 	return args.Error(0)
 }
 
+// ejecuta fn directo, sin base de datos
+type MockTransactionManager struct{}
+
+func (m *MockTransactionManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// stubPasswordPolicies devuelve la misma politica para cualquier tenant
+type stubPasswordPolicies struct {
+	policy vo.PasswordPolicy
+}
+
+func (s stubPasswordPolicies) FindByTenant(ctx context.Context, tenantID string) (vo.PasswordPolicy, error) {
+	return s.policy, nil
+}
+
+func defaultPolicies() stubPasswordPolicies {
+	return stubPasswordPolicies{policy: vo.DefaultPasswordPolicy()}
+}
+
+// stubScreener da por filtradas solo las passwords de compromised
+type stubScreener struct {
+	compromised []string
+}
+
+func (s stubScreener) IsCompromised(ctx context.Context, plainPassword string) (bool, error) {
+	for _, password := range s.compromised {
+		if password == plainPassword {
+			return true, nil
+		}
+	}
+	return false, nil
+}
 
 func TestCreateUserCommandHandler_Success(t *testing.T) {
 
@@ -70,8 +111,11 @@ func TestCreateUserCommandHandler_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	mockEventBus := new(MockEventBus)
 	mockIdempotency := new(MockIdempotencyRepository)
+	mockTokens := new(MockUserTokenRepository)
+
+	mockNotifier := new(MockTokenNotifier)
 
-	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency)
+	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency, &MockTransactionManager{}, mockTokens, mockNotifier, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
 
 	cmd := CreateUserCommand{
 		Name:           "John Doe",
@@ -87,6 +131,10 @@ func TestCreateUserCommandHandler_Success(t *testing.T) {
 	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
 	mockIdempotency.On("Store", ctx, cmd.IdempotencyKey, cmd.TenantID, mock.AnythingOfType("string")).Return(nil)
 	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserCreatedEvent")).Return(nil)
+	mockTokens.On("InvalidateForUser", ctx, mock.AnythingOfType("string"), cmd.TenantID, domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserEmailVerificationRequestedEvent")).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
 
 	userID, err := handler.Handle(ctx, cmd)
 
@@ -96,6 +144,8 @@ func TestCreateUserCommandHandler_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 	mockEventBus.AssertExpectations(t)
 	mockIdempotency.AssertExpectations(t)
+	mockTokens.AssertExpectations(t)
+	mockNotifier.AssertExpectations(t)
 }
 
 func TestCreateUserCommandHandler_EmailAlreadyExists(t *testing.T) {
@@ -104,7 +154,7 @@ func TestCreateUserCommandHandler_EmailAlreadyExists(t *testing.T) {
 	mockEventBus := new(MockEventBus)
 	mockIdempotency := new(MockIdempotencyRepository)
 
-	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency)
+	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency, &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
 
 	cmd := CreateUserCommand{
 		Name:          "John Doe",
@@ -130,7 +180,7 @@ func TestCreateUserCommandHandler_Idempotency(t *testing.T) {
 	mockEventBus := new(MockEventBus)
 	mockIdempotency := new(MockIdempotencyRepository)
 
-	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency)
+	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency, &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
 
 	cmd := CreateUserCommand{
 		Name:           "John Doe",
@@ -153,4 +203,84 @@ func TestCreateUserCommandHandler_Idempotency(t *testing.T) {
 	
 	mockRepo.AssertNotCalled(t, "Save")
 	mockEventBus.AssertNotCalled(t, "Publish")
-}
\ No newline at end of file
+}
+
+// si no se puede escribir el evento en el outbox la creacion tiene que fallar
+func TestCreateUserCommandHandler_OutboxFailure(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	mockIdempotency := new(MockIdempotencyRepository)
+
+	handler := NewCreateUserCommandHandler(mockRepo, mockEventBus, mockIdempotency, &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
+
+	cmd := CreateUserCommand{
+		Name:          "John Doe",
+		Email:         "john@example.com",
+		Password:      "SecurePass123!",
+		TenantID:      "tenant-1",
+		CorrelationID: "corr-123",
+	}
+
+	outboxErr := errors.New("outbox insert failed")
+
+	mockRepo.On("ExistsByEmail", ctx, "john@example.com", cmd.TenantID).Return(false, nil)
+	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserCreatedEvent")).Return(outboxErr)
+
+	userID, err := handler.Handle(ctx, cmd)
+
+	assert.ErrorIs(t, err, outboxErr)
+	assert.Empty(t, userID)
+	mockRepo.AssertExpectations(t)
+	mockEventBus.AssertExpectations(t)
+}
+
+// la politica del tenant se aplica al crear y se informan todas las violaciones
+func TestCreateUserCommandHandler_TenantPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	policies := stubPasswordPolicies{policy: vo.PasswordPolicy{MinLength: 20, MaxLength: 64, DenyPersonalInfo: true}}
+	handler := NewCreateUserCommandHandler(mockRepo, new(MockEventBus), new(MockIdempotencyRepository), &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), policies, stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
+
+	mockRepo.On("ExistsByEmail", ctx, "john@example.com", "tenant-1").Return(false, nil)
+
+	_, err := handler.Handle(ctx, CreateUserCommand{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "johnpassword",
+		TenantID: "tenant-1",
+	})
+
+	assert.ErrorIs(t, err, vo.ErrWeakPassword)
+
+	var policyErr *vo.PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, []vo.PasswordViolation{
+		{Code: vo.ViolationTooShort, Message: "password must be at least 20 characters long"},
+		{Code: vo.ViolationContainsPersonalInfo, Message: "password must not contain your name or email"},
+	}, policyErr.Violations)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+// una password filtrada se informa como una violacion mas de la politica
+func TestCreateUserCommandHandler_BreachedPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	screener := stubScreener{compromised: []string{"Password1!"}}
+	handler := NewCreateUserCommandHandler(mockRepo, new(MockEventBus), new(MockIdempotencyRepository), &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), defaultPolicies(), screener, vo.DefaultPasswordHasher(), time.Hour)
+
+	mockRepo.On("ExistsByEmail", ctx, "john@example.com", "tenant-1").Return(false, nil)
+
+	_, err := handler.Handle(ctx, CreateUserCommand{
+		Name:     "John Doe",
+		Email:    "john@example.com",
+		Password: "Password1!",
+		TenantID: "tenant-1",
+	})
+
+	var policyErr *vo.PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, []vo.PasswordViolation{vo.BreachedPasswordViolation()}, policyErr.Violations)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
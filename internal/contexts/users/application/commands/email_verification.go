@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+type VerifyEmailCommand struct {
+	Token         string
+	TenantID      string
+	CorrelationID string
+}
+
+// ResendEmailVerificationCommand se pide por email porque quien lo usa
+// todavia no puede loguearse
+type ResendEmailVerificationCommand struct {
+	Email         string
+	TenantID      string
+	CorrelationID string
+}
+
+type EmailVerificationCommandHandler struct {
+	repository     domain.UserRepository
+	tokenRepo      domain.UserTokenRepository
+	eventBus       EventBus
+	notifier       TokenNotifier
+	txManager      TransactionManager
+	tokenTTL       time.Duration
+	resendInterval time.Duration
+}
+
+func NewEmailVerificationCommandHandler(
+	repo domain.UserRepository,
+	tokenRepo domain.UserTokenRepository,
+	eventBus EventBus,
+	notifier TokenNotifier,
+	txManager TransactionManager,
+	tokenTTL time.Duration,
+	resendInterval time.Duration,
+) *EmailVerificationCommandHandler {
+	return &EmailVerificationCommandHandler{
+		repository:     repo,
+		tokenRepo:      tokenRepo,
+		eventBus:       eventBus,
+		notifier:       notifier,
+		txManager:      txManager,
+		tokenTTL:       tokenTTL,
+		resendInterval: resendInterval,
+	}
+}
+
+func (h *EmailVerificationCommandHandler) Verify(ctx context.Context, cmd VerifyEmailCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := h.tokenRepo.FindByHash(ctx, domain.HashUserToken(cmd.Token), domain.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		if token.TenantID() != cmd.TenantID {
+			return domain.ErrInvalidToken
+		}
+		if err := token.Use(); err != nil {
+			return err
+		}
+
+		user, err := h.repository.FindByID(ctx, token.UserID(), token.TenantID())
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+		if err := user.VerifyEmail(); err != nil {
+			return err
+		}
+
+		if err := h.tokenRepo.Save(ctx, token); err != nil {
+			return err
+		}
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserEmailVerifiedEvent(user, cmd.CorrelationID))
+	})
+}
+
+// Resend no informa si el email no existe o ya esta verificado, para no
+// filtrar que cuentas hay en el tenant
+func (h *EmailVerificationCommandHandler) Resend(ctx context.Context, cmd ResendEmailVerificationCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		email, err := vo.NewEmail(cmd.Email)
+		if err != nil {
+			return err
+		}
+
+		user, err := h.repository.FindByEmail(ctx, email.Value(), cmd.TenantID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return nil
+			}
+			return err
+		}
+		if user.IsDeleted() || user.IsEmailVerified() {
+			return nil
+		}
+
+		lastIssuedAt, err := h.tokenRepo.LastIssuedAt(ctx, user.ID(), user.TenantID(), domain.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		// el reenvio demasiado seguido tampoco da error: un 429 solo para las
+		// cuentas que existen las delataria
+		if lastIssuedAt != nil && time.Now().UTC().Before(lastIssuedAt.Add(h.resendInterval)) {
+			return nil
+		}
+
+		return issueEmailVerification(ctx, h.tokenRepo, h.eventBus, h.notifier, user, h.tokenTTL, cmd.CorrelationID)
+	})
+}
+
+// issueEmailVerification invalida los links anteriores y publica uno nuevo;
+// se usa al crear el usuario y en cada reenvio
+func issueEmailVerification(ctx context.Context, tokenRepo domain.UserTokenRepository, eventBus EventBus, notifier TokenNotifier, user *domain.User, ttl time.Duration, correlationID string) error {
+	if err := tokenRepo.InvalidateForUser(ctx, user.ID(), user.TenantID(), domain.TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	token, err := domain.NewUserToken(user.ID(), user.TenantID(), domain.TokenPurposeEmailVerification, ttl)
+	if err != nil {
+		return err
+	}
+
+	if err := tokenRepo.Save(ctx, token); err != nil {
+		return err
+	}
+
+	event := domain.NewUserEmailVerificationRequestedEvent(user, token, correlationID)
+	if err := eventBus.Publish(ctx, event); err != nil {
+		return err
+	}
+
+	return notifier.NotifyToken(ctx, newTokenNotice(event.EventID(), token, user.Email().Value(), correlationID))
+}
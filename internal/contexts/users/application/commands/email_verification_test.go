@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+func TestEmailVerificationCommandHandler_Verify(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewEmailVerificationCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	token, _ := domain.NewUserToken(user.ID(), "tenant-1", domain.TokenPurposeEmailVerification, time.Hour)
+
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposeEmailVerification).Return(token, nil)
+	mockTokens.On("Save", ctx, token).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserEmailVerifiedEvent")).Return(nil)
+
+	err := handler.Verify(ctx, VerifyEmailCommand{Token: token.Token(), TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	assert.True(t, user.IsEmailVerified())
+	assert.NotNil(t, token.UsedAt())
+}
+
+func TestEmailVerificationCommandHandler_VerifyUsedToken(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	handler := NewEmailVerificationCommandHandler(mockRepo, mockTokens, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	token, _ := domain.NewUserToken("user-1", "tenant-1", domain.TokenPurposeEmailVerification, time.Hour)
+	_ = token.Use()
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposeEmailVerification).Return(token, nil)
+
+	err := handler.Verify(ctx, VerifyEmailCommand{Token: token.Token(), TenantID: "tenant-1"})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	mockRepo.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEmailVerificationCommandHandler_Resend(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	mockNotifier := new(MockTokenNotifier)
+	handler := NewEmailVerificationCommandHandler(mockRepo, mockTokens, mockEventBus, mockNotifier, &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	lastIssuedAt := time.Now().UTC().Add(-2 * time.Minute)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockTokens.On("LastIssuedAt", ctx, user.ID(), "tenant-1", domain.TokenPurposeEmailVerification).Return(&lastIssuedAt, nil)
+	mockTokens.On("InvalidateForUser", ctx, user.ID(), "tenant-1", domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserEmailVerificationRequestedEvent")).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
+
+	err := handler.Resend(ctx, ResendEmailVerificationCommand{Email: "test@example.com", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	mockEventBus.AssertNumberOfCalls(t, "Publish", 1)
+	mockNotifier.AssertNumberOfCalls(t, "NotifyToken", 1)
+}
+
+// un reenvio antes del intervalo minimo no genera un token nuevo y responde
+// igual que uno valido
+func TestEmailVerificationCommandHandler_ResendThrottled(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewEmailVerificationCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	lastIssuedAt := time.Now().UTC().Add(-10 * time.Second)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockTokens.On("LastIssuedAt", ctx, user.ID(), "tenant-1", domain.TokenPurposeEmailVerification).Return(&lastIssuedAt, nil)
+
+	err := handler.Resend(ctx, ResendEmailVerificationCommand{Email: "test@example.com", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	mockTokens.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// un email desconocido no da error, para no filtrar que cuentas existen
+func TestEmailVerificationCommandHandler_ResendUnknownEmail(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewEmailVerificationCommandHandler(mockRepo, mockTokens, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	mockRepo.On("FindByEmail", ctx, "nobody@example.com", "tenant-1").Return(nil, domain.ErrUserNotFound)
+
+	err := handler.Resend(ctx, ResendEmailVerificationCommand{Email: "nobody@example.com", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,340 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+// ChangePasswordCommand lo usa el propio usuario logueado; CurrentSessionID
+// queda abierta y el resto de sus sesiones se revocan
+type ChangePasswordCommand struct {
+	UserID           string
+	TenantID         string
+	CurrentPassword  string
+	NewPassword      string
+	CurrentSessionID string
+	IPAddress        string
+	CorrelationID    string
+}
+
+// PasswordAttemptGuard bloquea por un tiempo la cuenta despues de varias
+// passwords actuales erradas, con los mismos contadores que el login: sin
+// esto el cambio de password sirve para probar passwords sin limite. Lo
+// implementa el LoginGuard de auth
+type PasswordAttemptGuard interface {
+	// Check devuelve ErrTooManyAttempts si la cuenta o la IP estan bloqueadas
+	Check(ctx context.Context, tenantID, email, ip string) error
+	RecordFailure(ctx context.Context, tenantID, email, ip, userID, correlationID string) error
+	RecordSuccess(ctx context.Context, tenantID, email string) error
+}
+
+type RequestPasswordResetCommand struct {
+	Email         string
+	TenantID      string
+	CorrelationID string
+}
+
+type ResetPasswordCommand struct {
+	Token         string
+	NewPassword   string
+	TenantID      string
+	CorrelationID string
+}
+
+type PasswordCommandHandler struct {
+	repository domain.UserRepository
+	tokenRepo  domain.UserTokenRepository
+	policies   domain.PasswordPolicyRepository
+	screener   domain.PasswordScreener
+	hasher     *vo.PasswordHasher
+	history    domain.PasswordHistoryRepository
+	guard      PasswordAttemptGuard
+	eventBus   EventBus
+	notifier   TokenNotifier
+	txManager  TransactionManager
+	resetTTL   time.Duration
+	// resetInterval es el minimo entre links de reset para un mismo usuario
+	resetInterval time.Duration
+}
+
+func NewPasswordCommandHandler(
+	repo domain.UserRepository,
+	tokenRepo domain.UserTokenRepository,
+	policies domain.PasswordPolicyRepository,
+	screener domain.PasswordScreener,
+	hasher *vo.PasswordHasher,
+	history domain.PasswordHistoryRepository,
+	guard PasswordAttemptGuard,
+	eventBus EventBus,
+	notifier TokenNotifier,
+	txManager TransactionManager,
+	resetTTL time.Duration,
+	resetInterval time.Duration,
+) *PasswordCommandHandler {
+	return &PasswordCommandHandler{
+		repository:    repo,
+		tokenRepo:     tokenRepo,
+		policies:      policies,
+		screener:      screener,
+		hasher:        hasher,
+		history:       history,
+		guard:         guard,
+		eventBus:      eventBus,
+		notifier:      notifier,
+		txManager:     txManager,
+		resetTTL:      resetTTL,
+		resetInterval: resetInterval,
+	}
+}
+
+func (h *PasswordCommandHandler) Change(ctx context.Context, cmd ChangePasswordCommand) error {
+	policy, err := h.policies.FindByTenant(ctx, cmd.TenantID)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+	if err != nil {
+		return err
+	}
+	if user.IsDeleted() {
+		return domain.ErrUserNotFound
+	}
+	email := user.Email().Value()
+
+	if err := h.guard.Check(ctx, cmd.TenantID, email, cmd.IPAddress); err != nil {
+		return err
+	}
+
+	err = h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+		if !user.Password().Compare(cmd.CurrentPassword) {
+			return domain.ErrInvalidCredentials
+		}
+
+		if err := h.replacePassword(ctx, policy, user, cmd.NewPassword); err != nil {
+			return err
+		}
+
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserPasswordChangedEvent(user, domain.PasswordChangeReasonChanged, cmd.CurrentSessionID, cmd.CorrelationID))
+	})
+
+	// el fallo se registra fuera de la transaccion, que se deshace con el error
+	if errors.Is(err, domain.ErrInvalidCredentials) {
+		if err := h.guard.RecordFailure(ctx, cmd.TenantID, email, cmd.IPAddress, user.ID(), cmd.CorrelationID); err != nil {
+			return err
+		}
+		return domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.guard.RecordSuccess(ctx, cmd.TenantID, email)
+}
+
+// RequestReset no informa si el email existe, igual que el reenvio de verificacion
+func (h *PasswordCommandHandler) RequestReset(ctx context.Context, cmd RequestPasswordResetCommand) error {
+	email, err := vo.NewEmail(cmd.Email)
+	if err != nil {
+		return err
+	}
+
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := h.repository.FindByEmail(ctx, email.Value(), cmd.TenantID)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return nil
+			}
+			return err
+		}
+		if user.IsDeleted() {
+			return nil
+		}
+
+		// un pedido repetido se ignora sin avisar, como el email inexistente
+		lastIssuedAt, err := h.tokenRepo.LastIssuedAt(ctx, user.ID(), user.TenantID(), domain.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		if lastIssuedAt != nil && time.Now().UTC().Before(lastIssuedAt.Add(h.resetInterval)) {
+			return nil
+		}
+
+		// solo vale el ultimo link pedido
+		if err := h.tokenRepo.InvalidateForUser(ctx, user.ID(), user.TenantID(), domain.TokenPurposePasswordReset); err != nil {
+			return err
+		}
+
+		token, err := domain.NewUserToken(user.ID(), user.TenantID(), domain.TokenPurposePasswordReset, h.resetTTL)
+		if err != nil {
+			return err
+		}
+
+		if err := h.tokenRepo.Save(ctx, token); err != nil {
+			return err
+		}
+
+		event := domain.NewUserPasswordResetRequestedEvent(user, token, cmd.CorrelationID)
+		if err := h.eventBus.Publish(ctx, event); err != nil {
+			return err
+		}
+
+		return h.notifier.NotifyToken(ctx, newTokenNotice(event.EventID(), token, user.Email().Value(), cmd.CorrelationID))
+	})
+}
+
+// Reset cambia la password con el token del email; el consumer revoca todas las sesiones
+func (h *PasswordCommandHandler) Reset(ctx context.Context, cmd ResetPasswordCommand) error {
+	policy, err := h.policies.FindByTenant(ctx, cmd.TenantID)
+	if err != nil {
+		return err
+	}
+
+	// se valida antes de tocar el token, una password debil no lo consume. Los
+	// datos personales se chequean despues, cuando ya se sabe de quien es
+	if err := validatePassword(ctx, policy, h.screener, cmd.NewPassword); err != nil {
+		return err
+	}
+
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		token, err := h.tokenRepo.FindByHash(ctx, domain.HashUserToken(cmd.Token), domain.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		if token.TenantID() != cmd.TenantID {
+			return domain.ErrInvalidToken
+		}
+		if err := token.Use(); err != nil {
+			return err
+		}
+
+		user, err := h.repository.FindByID(ctx, token.UserID(), token.TenantID())
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+
+		if err := h.replacePassword(ctx, policy, user, cmd.NewPassword); err != nil {
+			return err
+		}
+
+		if err := h.tokenRepo.Save(ctx, token); err != nil {
+			return err
+		}
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserPasswordChangedEvent(user, domain.PasswordChangeReasonReset, "", cmd.CorrelationID))
+	})
+}
+
+// replacePassword aplica la politica y el historial del usuario; la password
+// reemplazada pasa al historial
+func (h *PasswordCommandHandler) replacePassword(ctx context.Context, policy vo.PasswordPolicy, user *domain.User, plainPassword string) error {
+	violations, err := passwordViolations(ctx, policy, h.screener, plainPassword, user.Name(), user.Email().Value())
+	if err != nil {
+		return err
+	}
+
+	reused, err := h.isReused(ctx, policy, user, plainPassword)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violations = append(violations, vo.ReusedPasswordViolation(policy.HistorySize))
+	}
+
+	if len(violations) > 0 {
+		return &vo.PasswordPolicyError{Violations: violations}
+	}
+
+	password, err := policy.NewPassword(h.hasher, plainPassword, user.Name(), user.Email().Value())
+	if err != nil {
+		return err
+	}
+
+	previous := user.Password()
+	user.ChangePassword(password)
+
+	// se guarda aunque la politica no lo pida, asi un tenant que activa el
+	// control despues ya tiene las anteriores. La actual cuenta como una de
+	// las HistorySize, el historial guarda las demas
+	return h.history.Add(ctx, user.ID(), user.TenantID(), previous, vo.MaxPasswordHistorySize-1)
+}
+
+func (h *PasswordCommandHandler) isReused(ctx context.Context, policy vo.PasswordPolicy, user *domain.User, plainPassword string) (bool, error) {
+	if policy.HistorySize == 0 {
+		return false, nil
+	}
+	if user.Password().Compare(plainPassword) {
+		return true, nil
+	}
+	if policy.HistorySize == 1 {
+		return false, nil
+	}
+
+	previous, err := h.history.Recent(ctx, user.ID(), user.TenantID(), policy.HistorySize-1)
+	if err != nil {
+		return false, err
+	}
+	for _, password := range previous {
+		if password.Compare(plainPassword) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// passwordViolations junta las violaciones de la politica con las del screener,
+// asi el usuario ve todo lo que tiene que corregir de una vez
+func passwordViolations(ctx context.Context, policy vo.PasswordPolicy, screener domain.PasswordScreener, plainPassword string, personalInfo ...string) ([]vo.PasswordViolation, error) {
+	violations := policy.Validate(plainPassword, personalInfo...)
+
+	if policy.DenyBreached {
+		compromised, err := screener.IsCompromised(ctx, plainPassword)
+		if err != nil {
+			return nil, err
+		}
+		if compromised {
+			violations = append(violations, vo.BreachedPasswordViolation())
+		}
+	}
+
+	return violations, nil
+}
+
+func validatePassword(ctx context.Context, policy vo.PasswordPolicy, screener domain.PasswordScreener, plainPassword string, personalInfo ...string) error {
+	violations, err := passwordViolations(ctx, policy, screener, plainPassword, personalInfo...)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &vo.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func newPassword(ctx context.Context, policy vo.PasswordPolicy, screener domain.PasswordScreener, hasher *vo.PasswordHasher, plainPassword string, personalInfo ...string) (vo.Password, error) {
+	if err := validatePassword(ctx, policy, screener, plainPassword, personalInfo...); err != nil {
+		return vo.Password{}, err
+	}
+	return policy.NewPassword(hasher, plainPassword, personalInfo...)
+}
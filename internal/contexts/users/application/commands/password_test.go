@@ -0,0 +1,410 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+type MockPasswordHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasswordHistoryRepository) Recent(ctx context.Context, userID, tenantID string, limit int) ([]vo.Password, error) {
+	args := m.Called(ctx, userID, tenantID, limit)
+	return args.Get(0).([]vo.Password), args.Error(1)
+}
+
+func (m *MockPasswordHistoryRepository) Add(ctx context.Context, userID, tenantID string, password vo.Password, keep int) error {
+	args := m.Called(ctx, userID, tenantID, password, keep)
+	return args.Error(0)
+}
+
+// newRecordingHistory acepta cualquier Add, para los tests que no miran el historial
+func newRecordingHistory() *MockPasswordHistoryRepository {
+	history := new(MockPasswordHistoryRepository)
+	history.On("Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return history
+}
+
+// openGuard no bloquea nunca
+type openGuard struct{}
+
+func (openGuard) Check(ctx context.Context, tenantID, email, ip string) error { return nil }
+func (openGuard) RecordFailure(ctx context.Context, tenantID, email, ip, userID, correlationID string) error {
+	return nil
+}
+func (openGuard) RecordSuccess(ctx context.Context, tenantID, email string) error { return nil }
+
+type MockPasswordAttemptGuard struct {
+	mock.Mock
+}
+
+func (m *MockPasswordAttemptGuard) Check(ctx context.Context, tenantID, email, ip string) error {
+	args := m.Called(ctx, tenantID, email, ip)
+	return args.Error(0)
+}
+
+func (m *MockPasswordAttemptGuard) RecordFailure(ctx context.Context, tenantID, email, ip, userID, correlationID string) error {
+	args := m.Called(ctx, tenantID, email, ip, userID, correlationID)
+	return args.Error(0)
+}
+
+func (m *MockPasswordAttemptGuard) RecordSuccess(ctx context.Context, tenantID, email string) error {
+	args := m.Called(ctx, tenantID, email)
+	return args.Error(0)
+}
+
+func TestPasswordCommandHandler_Change(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserPasswordChangedEvent")).Return(nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:           user.ID(),
+		TenantID:         "tenant-1",
+		CurrentPassword:  "SecurePass123!",
+		NewPassword:      "AnotherPass456!",
+		CurrentSessionID: "session-1",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, user.Password().Compare("AnotherPass456!"))
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserPasswordChangedEvent)
+	assert.Equal(t, domain.PasswordChangeReasonChanged, event.Reason)
+	assert.Equal(t, "session-1", event.KeepSessionID)
+}
+
+func TestPasswordCommandHandler_ChangeWrongCurrentPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "WrongPass123!",
+		NewPassword:     "AnotherPass456!",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	assert.True(t, user.Password().Compare("SecurePass123!"))
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestPasswordCommandHandler_ChangeWrongCurrentPasswordCountsAsFailure(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockGuard := new(MockPasswordAttemptGuard)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), mockGuard, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockGuard.On("Check", ctx, "tenant-1", "test@example.com", "10.0.0.1").Return(nil)
+	mockGuard.On("RecordFailure", ctx, "tenant-1", "test@example.com", "10.0.0.1", user.ID(), "corr-1").Return(nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "WrongPass123!",
+		NewPassword:     "AnotherPass456!",
+		IPAddress:       "10.0.0.1",
+		CorrelationID:   "corr-1",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	mockGuard.AssertExpectations(t)
+	mockGuard.AssertNotCalled(t, "RecordSuccess", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// con la cuenta bloqueada ni se compara la password actual
+func TestPasswordCommandHandler_ChangeLocked(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockGuard := new(MockPasswordAttemptGuard)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), mockGuard, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockGuard.On("Check", ctx, "tenant-1", "test@example.com", "").Return(domain.ErrTooManyAttempts)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "AnotherPass456!",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrTooManyAttempts)
+	assert.True(t, user.Password().Compare("SecurePass123!"))
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockGuard.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPasswordCommandHandler_RequestReset(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	mockNotifier := new(MockTokenNotifier)
+	handler := NewPasswordCommandHandler(mockRepo, mockTokens, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, mockEventBus, mockNotifier, &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockTokens.On("LastIssuedAt", ctx, user.ID(), "tenant-1", domain.TokenPurposePasswordReset).Return(nil, nil)
+	mockTokens.On("InvalidateForUser", ctx, user.ID(), "tenant-1", domain.TokenPurposePasswordReset).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserPasswordResetRequestedEvent")).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
+
+	err := handler.RequestReset(ctx, RequestPasswordResetCommand{Email: "test@example.com", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+
+	token := mockTokens.Calls[2].Arguments.Get(1).(*domain.UserToken)
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserPasswordResetRequestedEvent)
+	notice := mockNotifier.Calls[0].Arguments.Get(1).(TokenNotice)
+	assert.Equal(t, event.EventID(), notice.EventID)
+	assert.Equal(t, domain.TokenPurposePasswordReset, notice.Purpose)
+	assert.Equal(t, domain.HashUserToken(notice.Token), token.TokenHash())
+}
+
+// un pedido repetido dentro del intervalo no manda otro link ni da error
+func TestPasswordCommandHandler_RequestResetThrottled(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	mockNotifier := new(MockTokenNotifier)
+	handler := NewPasswordCommandHandler(mockRepo, mockTokens, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, mockEventBus, mockNotifier, &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	lastIssuedAt := time.Now().UTC().Add(-10 * time.Second)
+
+	mockRepo.On("FindByEmail", ctx, "test@example.com", "tenant-1").Return(user, nil)
+	mockTokens.On("LastIssuedAt", ctx, user.ID(), "tenant-1", domain.TokenPurposePasswordReset).Return(&lastIssuedAt, nil)
+
+	err := handler.RequestReset(ctx, RequestPasswordResetCommand{Email: "test@example.com", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	mockTokens.AssertNotCalled(t, "InvalidateForUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockTokens.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+	mockNotifier.AssertNotCalled(t, "NotifyToken", mock.Anything, mock.Anything)
+}
+
+func TestPasswordCommandHandler_Reset(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPasswordCommandHandler(mockRepo, mockTokens, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	token, _ := domain.NewUserToken(user.ID(), "tenant-1", domain.TokenPurposePasswordReset, time.Hour)
+
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposePasswordReset).Return(token, nil)
+	mockTokens.On("Save", ctx, token).Return(nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserPasswordChangedEvent")).Return(nil)
+
+	err := handler.Reset(ctx, ResetPasswordCommand{Token: token.Token(), NewPassword: "AnotherPass456!", TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	assert.True(t, user.Password().Compare("AnotherPass456!"))
+	assert.NotNil(t, token.UsedAt())
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserPasswordChangedEvent)
+	assert.Equal(t, domain.PasswordChangeReasonReset, event.Reason)
+	assert.Empty(t, event.KeepSessionID)
+}
+
+// una password que no cumple la politica no consume el token
+func TestPasswordCommandHandler_ResetWeakPassword(t *testing.T) {
+	ctx := context.Background()
+	mockTokens := new(MockUserTokenRepository)
+	handler := NewPasswordCommandHandler(new(MockUserRepository), mockTokens, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	err := handler.Reset(ctx, ResetPasswordCommand{Token: "token", NewPassword: "weak", TenantID: "tenant-1"})
+
+	assert.ErrorIs(t, err, vo.ErrWeakPassword)
+	mockTokens.AssertNotCalled(t, "FindByHash", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// los datos personales se chequean con el usuario del token; si falla no se guarda nada
+func TestPasswordCommandHandler_ResetWithPersonalInfo(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	policies := stubPasswordPolicies{policy: vo.PasswordPolicy{MinLength: 8, DenyPersonalInfo: true}}
+	handler := NewPasswordCommandHandler(mockRepo, mockTokens, policies, stubScreener{}, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	token, _ := domain.NewUserToken(user.ID(), "tenant-1", domain.TokenPurposePasswordReset, time.Hour)
+
+	mockTokens.On("FindByHash", ctx, token.TokenHash(), domain.TokenPurposePasswordReset).Return(token, nil)
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Reset(ctx, ResetPasswordCommand{Token: token.Token(), NewPassword: "my name is john", TenantID: "tenant-1"})
+
+	var policyErr *vo.PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, vo.ViolationContainsPersonalInfo, policyErr.Violations[0].Code)
+	mockTokens.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestPasswordCommandHandler_ChangeToBreachedPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	screener := stubScreener{compromised: []string{"Password1!"}}
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), screener, vo.DefaultPasswordHasher(), newRecordingHistory(), openGuard{}, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "Password1!",
+	})
+
+	assert.ErrorIs(t, err, vo.ErrWeakPassword)
+	assert.True(t, user.Password().Compare("SecurePass123!"))
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func historyPolicy(size int) stubPasswordPolicies {
+	policy := vo.DefaultPasswordPolicy()
+	policy.HistorySize = size
+	return stubPasswordPolicies{policy: policy}
+}
+
+// con historial la password actual tambien cuenta como usada
+func TestPasswordCommandHandler_ChangeToCurrentPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockHistory := new(MockPasswordHistoryRepository)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), historyPolicy(3), stubScreener{}, vo.DefaultPasswordHasher(), mockHistory, openGuard{}, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "SecurePass123!",
+	})
+
+	var policyErr *vo.PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, []vo.PasswordViolation{vo.ReusedPasswordViolation(3)}, policyErr.Violations)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestPasswordCommandHandler_ChangeToPreviousPassword(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockHistory := new(MockPasswordHistoryRepository)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), historyPolicy(3), stubScreener{}, vo.DefaultPasswordHasher(), mockHistory, openGuard{}, new(MockEventBus), new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	older, _ := vo.NewPassword("OldestPass111!")
+	old, _ := vo.NewPassword("OlderPass222!")
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockHistory.On("Recent", ctx, user.ID(), "tenant-1", 2).Return([]vo.Password{old, older}, nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "OldestPass111!",
+	})
+
+	assert.ErrorIs(t, err, vo.ErrWeakPassword)
+	assert.True(t, user.Password().Compare("SecurePass123!"))
+	mockHistory.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// la password reemplazada pasa al historial, que guarda hasta el maximo
+// aunque la politica mire menos
+func TestPasswordCommandHandler_ChangeRecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockHistory := new(MockPasswordHistoryRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), historyPolicy(3), stubScreener{}, vo.DefaultPasswordHasher(), mockHistory, openGuard{}, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	previous := user.Password()
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockHistory.On("Recent", ctx, user.ID(), "tenant-1", 2).Return([]vo.Password{}, nil)
+	mockHistory.On("Add", ctx, user.ID(), "tenant-1", previous, vo.MaxPasswordHistorySize-1).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserPasswordChangedEvent")).Return(nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "AnotherPass456!",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, user.Password().Compare("AnotherPass456!"))
+	mockHistory.AssertExpectations(t)
+}
+
+// sin control de historial en la politica igual se guarda la anterior
+func TestPasswordCommandHandler_ChangeRecordsHistoryWithoutPolicy(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockHistory := new(MockPasswordHistoryRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPasswordCommandHandler(mockRepo, new(MockUserTokenRepository), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), mockHistory, openGuard{}, mockEventBus, new(MockTokenNotifier), &MockTransactionManager{}, time.Hour, time.Minute)
+
+	user := newExistingUser(nil)
+	previous := user.Password()
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockHistory.On("Add", ctx, user.ID(), "tenant-1", previous, vo.MaxPasswordHistorySize-1).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserPasswordChangedEvent")).Return(nil)
+
+	err := handler.Change(ctx, ChangePasswordCommand{
+		UserID:          user.ID(),
+		TenantID:        "tenant-1",
+		CurrentPassword: "SecurePass123!",
+		NewPassword:     "AnotherPass456!",
+	})
+
+	assert.NoError(t, err)
+	mockHistory.AssertExpectations(t)
+	mockHistory.AssertNotCalled(t, "Recent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// AssignRoleCommand reemplaza el rol del usuario; AssignedBy es quien lo pide
+type AssignRoleCommand struct {
+	UserID        string
+	TenantID      string
+	Role          string
+	AssignedBy    string
+	CorrelationID string
+}
+
+// DefineRoleCommand crea un rol custom o reemplaza sus permisos si ya existe;
+// DefinedBy es quien lo pide y tiene que tener todos esos permisos
+type DefineRoleCommand struct {
+	TenantID    string
+	Name        string
+	Description string
+	Permissions []string
+	DefinedBy   string
+}
+
+type DeleteRoleCommand struct {
+	TenantID string
+	Name     string
+}
+
+type RoleCommandHandler struct {
+	repository  domain.UserRepository
+	roles       domain.RoleRepository
+	assignments domain.RoleAssignmentRepository
+	eventBus    EventBus
+	txManager   TransactionManager
+}
+
+func NewRoleCommandHandler(
+	repo domain.UserRepository,
+	roles domain.RoleRepository,
+	assignments domain.RoleAssignmentRepository,
+	eventBus EventBus,
+	txManager TransactionManager,
+) *RoleCommandHandler {
+	return &RoleCommandHandler{
+		repository:  repo,
+		roles:       roles,
+		assignments: assignments,
+		eventBus:    eventBus,
+		txManager:   txManager,
+	}
+}
+
+// Assign exige que quien asigna tenga todos los permisos del rol nuevo y del
+// que tenia el usuario: roles:manage solo no alcanza para darse admin
+func (h *RoleCommandHandler) Assign(ctx context.Context, cmd AssignRoleCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		actor, err := h.roleOf(ctx, cmd.AssignedBy, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		return h.assign(ctx, cmd, actor)
+	})
+}
+
+// assignInitialAdmin no tiene a nadie que asigne: solo vale mientras el tenant
+// no tenga ningun admin activo
+func (h *RoleCommandHandler) assignInitialAdmin(ctx context.Context, cmd AssignRoleCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		admins, err := h.assignments.CountByRole(ctx, cmd.TenantID, domain.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if admins > 0 {
+			return domain.ErrTenantHasAdmin
+		}
+
+		cmd.Role = domain.RoleAdmin
+		return h.assign(ctx, cmd, nil)
+	})
+}
+
+// assign corre dentro de una transaccion; actor nil saltea el chequeo de permisos
+func (h *RoleCommandHandler) assign(ctx context.Context, cmd AssignRoleCommand, actor *domain.Role) error {
+	role, err := h.findRole(ctx, cmd.TenantID, cmd.Role)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+	if err != nil {
+		return err
+	}
+	if user.IsDeleted() {
+		return domain.ErrUserNotFound
+	}
+
+	previous, err := h.assignments.RoleOf(ctx, user.ID(), user.TenantID())
+	if err != nil {
+		return err
+	}
+
+	if actor != nil {
+		if !actor.Covers(role) {
+			return domain.ErrPermissionEscalation
+		}
+		// un rol custom borrado ya no da permisos, cualquiera lo puede reemplazar
+		previousRole, err := h.findRole(ctx, user.TenantID(), previous)
+		if err != nil && !errors.Is(err, domain.ErrRoleNotFound) {
+			return err
+		}
+		if previousRole != nil && !actor.Covers(previousRole) {
+			return domain.ErrPermissionEscalation
+		}
+	}
+
+	if previous == cmd.Role {
+		return nil
+	}
+
+	if err := ensureNotLastAdmin(ctx, h.assignments, user, previous); err != nil {
+		return err
+	}
+
+	if err := h.assignments.Assign(ctx, user.ID(), user.TenantID(), cmd.Role, cmd.AssignedBy); err != nil {
+		return err
+	}
+
+	return h.eventBus.Publish(ctx, domain.NewUserRoleAssignedEvent(user, cmd.Role, previous, cmd.AssignedBy, cmd.CorrelationID))
+}
+
+func (h *RoleCommandHandler) Define(ctx context.Context, cmd DefineRoleCommand) (*domain.Role, error) {
+	var role *domain.Role
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		actor, err := h.roleOf(ctx, cmd.DefinedBy, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := h.roles.FindByName(ctx, cmd.TenantID, cmd.Name)
+		switch {
+		case err == nil:
+			if err := existing.Redefine(cmd.Description, cmd.Permissions); err != nil {
+				return err
+			}
+			role = existing
+		case errors.Is(err, domain.ErrRoleNotFound):
+			role, err = domain.NewCustomRole(cmd.TenantID, cmd.Name, cmd.Description, cmd.Permissions)
+			if err != nil {
+				return err
+			}
+		default:
+			return err
+		}
+
+		if !actor.Covers(role) {
+			return domain.ErrPermissionEscalation
+		}
+
+		return h.roles.Save(ctx, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// Delete no borra un rol que todavia tiene usuarios asignados
+func (h *RoleCommandHandler) Delete(ctx context.Context, cmd DeleteRoleCommand) error {
+	if domain.IsBuiltinRole(cmd.Name) {
+		return domain.ErrBuiltinRole
+	}
+
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := h.roles.FindByName(ctx, cmd.TenantID, cmd.Name); err != nil {
+			return err
+		}
+
+		assigned, err := h.assignments.CountByRole(ctx, cmd.TenantID, cmd.Name)
+		if err != nil {
+			return err
+		}
+		if assigned > 0 {
+			return domain.ErrRoleInUse
+		}
+
+		return h.roles.Delete(ctx, cmd.TenantID, cmd.Name)
+	})
+}
+
+// roleOf devuelve el rol del usuario; si era un rol custom que ya no existe,
+// uno sin permisos
+func (h *RoleCommandHandler) roleOf(ctx context.Context, userID, tenantID string) (*domain.Role, error) {
+	name, err := h.assignments.RoleOf(ctx, userID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := h.findRole(ctx, tenantID, name)
+	if errors.Is(err, domain.ErrRoleNotFound) {
+		return domain.RebuildRole(tenantID, name, "", nil, time.Time{}, time.Time{}), nil
+	}
+	return role, err
+}
+
+func (h *RoleCommandHandler) findRole(ctx context.Context, tenantID, name string) (*domain.Role, error) {
+	if role := domain.BuiltinRole(tenantID, name); role != nil {
+		return role, nil
+	}
+	return h.roles.FindByName(ctx, tenantID, name)
+}
+
+// ensureNotLastAdmin corta si el usuario es el unico admin activo: sin admins
+// nadie puede volver a asignar roles en el tenant
+func ensureNotLastAdmin(ctx context.Context, assignments domain.RoleAssignmentRepository, user *domain.User, role string) error {
+	if role != domain.RoleAdmin || user.Status() != domain.StatusActive {
+		return nil
+	}
+
+	admins, err := assignments.CountByRole(ctx, user.TenantID(), domain.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if admins <= 1 {
+		return domain.ErrLastAdmin
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) Save(ctx context.Context, role *domain.Role) error {
+	args := m.Called(ctx, role)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) FindByName(ctx context.Context, tenantID, name string) (*domain.Role, error) {
+	args := m.Called(ctx, tenantID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) FindAll(ctx context.Context, tenantID string) ([]*domain.Role, error) {
+	args := m.Called(ctx, tenantID)
+	return args.Get(0).([]*domain.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) Delete(ctx context.Context, tenantID, name string) error {
+	args := m.Called(ctx, tenantID, name)
+	return args.Error(0)
+}
+
+type MockRoleAssignmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleAssignmentRepository) RoleOf(ctx context.Context, userID, tenantID string) (string, error) {
+	args := m.Called(ctx, userID, tenantID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockRoleAssignmentRepository) Assign(ctx context.Context, userID, tenantID, role, assignedBy string) error {
+	args := m.Called(ctx, userID, tenantID, role, assignedBy)
+	return args.Error(0)
+}
+
+func (m *MockRoleAssignmentRepository) CountByRole(ctx context.Context, tenantID, role string) (int, error) {
+	args := m.Called(ctx, tenantID, role)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRoleCommandHandler_Assign(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewRoleCommandHandler(mockRepo, new(MockRoleRepository), mockAssignments, mockEventBus, &MockTransactionManager{})
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockAssignments.On("RoleOf", ctx, "admin-1", "tenant-1").Return(domain.RoleAdmin, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return(domain.RoleMember, nil)
+	mockAssignments.On("Assign", ctx, user.ID(), "tenant-1", domain.RoleReadOnly, "admin-1").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserRoleAssignedEvent")).Return(nil)
+
+	err := handler.Assign(ctx, AssignRoleCommand{
+		UserID:     user.ID(),
+		TenantID:   "tenant-1",
+		Role:       domain.RoleReadOnly,
+		AssignedBy: "admin-1",
+	})
+
+	assert.NoError(t, err)
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserRoleAssignedEvent)
+	assert.Equal(t, domain.UserRoleAssignedEventType, event.EventType())
+	assert.Equal(t, domain.RoleReadOnly, event.Role)
+	assert.Equal(t, domain.RoleMember, event.PreviousRole)
+	assert.Equal(t, "admin-1", event.AssignedBy)
+}
+
+func TestRoleCommandHandler_Assign_UnknownCustomRole(t *testing.T) {
+	ctx := context.Background()
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	handler := NewRoleCommandHandler(new(MockUserRepository), mockRoles, mockAssignments, new(MockEventBus), &MockTransactionManager{})
+
+	mockAssignments.On("RoleOf", ctx, "admin-1", "tenant-1").Return(domain.RoleAdmin, nil)
+	mockRoles.On("FindByName", ctx, "tenant-1", "auditor").Return(nil, domain.ErrRoleNotFound)
+
+	err := handler.Assign(ctx, AssignRoleCommand{UserID: "user-1", TenantID: "tenant-1", Role: "auditor", AssignedBy: "admin-1"})
+
+	assert.ErrorIs(t, err, domain.ErrRoleNotFound)
+}
+
+func TestRoleCommandHandler_Assign_LastAdmin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewRoleCommandHandler(mockRepo, new(MockRoleRepository), mockAssignments, mockEventBus, &MockTransactionManager{})
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return(domain.RoleAdmin, nil)
+	mockAssignments.On("CountByRole", ctx, "tenant-1", domain.RoleAdmin).Return(1, nil)
+
+	// el unico admin intentando bajarse a si mismo
+	err := handler.Assign(ctx, AssignRoleCommand{UserID: user.ID(), TenantID: "tenant-1", Role: domain.RoleMember, AssignedBy: user.ID()})
+
+	assert.ErrorIs(t, err, domain.ErrLastAdmin)
+	mockAssignments.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// con roles:manage y sin el resto de los permisos no se puede dar admin
+func TestRoleCommandHandler_Assign_PermissionEscalation(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewRoleCommandHandler(mockRepo, mockRoles, mockAssignments, mockEventBus, &MockTransactionManager{})
+
+	user := newExistingUser(nil)
+	manager, _ := domain.NewCustomRole("tenant-1", "manager", "", []string{domain.PermissionRolesManage, domain.PermissionUsersRead})
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return("manager", nil)
+	mockRoles.On("FindByName", ctx, "tenant-1", "manager").Return(manager, nil)
+
+	err := handler.Assign(ctx, AssignRoleCommand{UserID: user.ID(), TenantID: "tenant-1", Role: domain.RoleAdmin, AssignedBy: user.ID()})
+
+	assert.ErrorIs(t, err, domain.ErrPermissionEscalation)
+	mockAssignments.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestRoleCommandHandler_Define_PermissionEscalation(t *testing.T) {
+	ctx := context.Background()
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	handler := NewRoleCommandHandler(new(MockUserRepository), mockRoles, mockAssignments, new(MockEventBus), &MockTransactionManager{})
+
+	permissions := []string{domain.PermissionRolesManage, domain.PermissionUsersRead}
+	actorRole, _ := domain.NewCustomRole("tenant-1", "manager", "", permissions)
+	existing, _ := domain.NewCustomRole("tenant-1", "manager", "", permissions)
+
+	mockAssignments.On("RoleOf", ctx, "manager-1", "tenant-1").Return("manager", nil)
+	mockRoles.On("FindByName", ctx, "tenant-1", "manager").Return(actorRole, nil).Once()
+	mockRoles.On("FindByName", ctx, "tenant-1", "manager").Return(existing, nil).Once()
+
+	// redefinir su propio rol para sumarse permisos
+	_, err := handler.Define(ctx, DefineRoleCommand{
+		TenantID:    "tenant-1",
+		Name:        "manager",
+		Permissions: []string{domain.PermissionRolesManage, domain.PermissionUsersRead, domain.PermissionUsersDelete},
+		DefinedBy:   "manager-1",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrPermissionEscalation)
+	mockRoles.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestRoleCommandHandler_Define_CreatesCustomRole(t *testing.T) {
+	ctx := context.Background()
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	handler := NewRoleCommandHandler(new(MockUserRepository), mockRoles, mockAssignments, new(MockEventBus), &MockTransactionManager{})
+
+	mockAssignments.On("RoleOf", ctx, "admin-1", "tenant-1").Return(domain.RoleAdmin, nil)
+	mockRoles.On("FindByName", ctx, "tenant-1", "support").Return(nil, domain.ErrRoleNotFound)
+	mockRoles.On("Save", ctx, mock.AnythingOfType("*domain.Role")).Return(nil)
+
+	role, err := handler.Define(ctx, DefineRoleCommand{
+		TenantID:    "tenant-1",
+		Name:        "support",
+		Permissions: []string{domain.PermissionUsersRead, domain.PermissionAccountsUnlock, domain.PermissionUsersRead},
+		DefinedBy:   "admin-1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{domain.PermissionAccountsUnlock, domain.PermissionUsersRead}, role.Permissions())
+}
+
+func TestRoleCommandHandler_Define_RejectsBuiltinName(t *testing.T) {
+	ctx := context.Background()
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	handler := NewRoleCommandHandler(new(MockUserRepository), mockRoles, mockAssignments, new(MockEventBus), &MockTransactionManager{})
+
+	mockAssignments.On("RoleOf", ctx, "admin-1", "tenant-1").Return(domain.RoleAdmin, nil)
+	mockRoles.On("FindByName", ctx, "tenant-1", domain.RoleAdmin).Return(nil, domain.ErrRoleNotFound)
+
+	_, err := handler.Define(ctx, DefineRoleCommand{TenantID: "tenant-1", Name: domain.RoleAdmin, Permissions: []string{domain.PermissionUsersRead}, DefinedBy: "admin-1"})
+
+	assert.ErrorIs(t, err, domain.ErrBuiltinRole)
+	mockRoles.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
+
+func TestRoleCommandHandler_Delete_RoleInUse(t *testing.T) {
+	ctx := context.Background()
+	mockRoles := new(MockRoleRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	handler := NewRoleCommandHandler(new(MockUserRepository), mockRoles, mockAssignments, new(MockEventBus), &MockTransactionManager{})
+
+	role, _ := domain.NewCustomRole("tenant-1", "support", "", []string{domain.PermissionUsersRead})
+	mockRoles.On("FindByName", ctx, "tenant-1", "support").Return(role, nil)
+	mockAssignments.On("CountByRole", ctx, "tenant-1", "support").Return(2, nil)
+
+	err := handler.Delete(ctx, DeleteRoleCommand{TenantID: "tenant-1", Name: "support"})
+
+	assert.ErrorIs(t, err, domain.ErrRoleInUse)
+	mockRoles.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
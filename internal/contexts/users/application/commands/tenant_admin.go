@@ -0,0 +1,57 @@
+package commands
+
+import "context"
+
+// provisioningActor es el AssignedBy del rol del primer admin, que no lo
+// asigna ningun usuario
+const provisioningActor = "provisioning"
+
+type CreateTenantAdminCommand struct {
+	TenantID      string
+	Name          string
+	Email         string
+	Password      string
+	CorrelationID string
+}
+
+// TenantAdminCommandHandler crea el primer admin de un tenant: uno recien
+// provisionado o uno que se quedo sin admins. Con un admin activo devuelve
+// ErrTenantHasAdmin
+type TenantAdminCommandHandler struct {
+	createHandler *CreateUserCommandHandler
+	roleHandler   *RoleCommandHandler
+}
+
+func NewTenantAdminCommandHandler(createHandler *CreateUserCommandHandler, roleHandler *RoleCommandHandler) *TenantAdminCommandHandler {
+	return &TenantAdminCommandHandler{
+		createHandler: createHandler,
+		roleHandler:   roleHandler,
+	}
+}
+
+// Handle corre dentro de la transaccion del context, si hay una: si falla la
+// asignacion del rol no queda un usuario sin permisos
+func (h *TenantAdminCommandHandler) Handle(ctx context.Context, cmd CreateTenantAdminCommand) (string, error) {
+	userID, err := h.createHandler.Handle(ctx, CreateUserCommand{
+		Name:          cmd.Name,
+		Email:         cmd.Email,
+		Password:      cmd.Password,
+		TenantID:      cmd.TenantID,
+		CorrelationID: cmd.CorrelationID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = h.roleHandler.assignInitialAdmin(ctx, AssignRoleCommand{
+		UserID:        userID,
+		TenantID:      cmd.TenantID,
+		AssignedBy:    provisioningActor,
+		CorrelationID: cmd.CorrelationID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+func TestTenantAdminCommandHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	mockTokens := new(MockUserTokenRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockNotifier := new(MockTokenNotifier)
+
+	createHandler := NewCreateUserCommandHandler(mockRepo, mockEventBus, new(MockIdempotencyRepository), &MockTransactionManager{}, mockTokens, mockNotifier, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
+	roleHandler := NewRoleCommandHandler(mockRepo, new(MockRoleRepository), mockAssignments, mockEventBus, &MockTransactionManager{})
+	handler := NewTenantAdminCommandHandler(createHandler, roleHandler)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("ExistsByEmail", ctx, "admin@acme.com", "tenant-1").Return(false, nil)
+	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+	mockTokens.On("InvalidateForUser", ctx, mock.AnythingOfType("string"), "tenant-1", domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockRepo.On("FindByID", ctx, mock.AnythingOfType("string"), "tenant-1").Return(user, nil)
+	mockAssignments.On("CountByRole", ctx, "tenant-1", domain.RoleAdmin).Return(0, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return(domain.DefaultRole, nil)
+	mockAssignments.On("Assign", ctx, user.ID(), "tenant-1", domain.RoleAdmin, "provisioning").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.Anything).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
+
+	userID, err := handler.Handle(ctx, CreateTenantAdminCommand{
+		TenantID: "tenant-1",
+		Name:     "Jane Admin",
+		Email:    "admin@acme.com",
+		Password: "SecurePass123!",
+	})
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, userID)
+	mockAssignments.AssertExpectations(t)
+}
+
+func TestTenantAdminCommandHandler_Handle_CreateFails(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+
+	createHandler := NewCreateUserCommandHandler(mockRepo, new(MockEventBus), new(MockIdempotencyRepository), &MockTransactionManager{}, new(MockUserTokenRepository), new(MockTokenNotifier), defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
+	roleHandler := NewRoleCommandHandler(mockRepo, new(MockRoleRepository), mockAssignments, new(MockEventBus), &MockTransactionManager{})
+	handler := NewTenantAdminCommandHandler(createHandler, roleHandler)
+
+	mockRepo.On("ExistsByEmail", ctx, "admin@acme.com", "tenant-1").Return(true, nil)
+
+	_, err := handler.Handle(ctx, CreateTenantAdminCommand{
+		TenantID: "tenant-1",
+		Name:     "Jane Admin",
+		Email:    "admin@acme.com",
+		Password: "SecurePass123!",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrUserAlreadyExists)
+	mockAssignments.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// con un admin activo no se puede crear otro por esta via
+func TestTenantAdminCommandHandler_Handle_TenantHasAdmin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockTokens := new(MockUserTokenRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockNotifier := new(MockTokenNotifier)
+	mockEventBus := new(MockEventBus)
+
+	createHandler := NewCreateUserCommandHandler(mockRepo, mockEventBus, new(MockIdempotencyRepository), &MockTransactionManager{}, mockTokens, mockNotifier, defaultPolicies(), stubScreener{}, vo.DefaultPasswordHasher(), time.Hour)
+	roleHandler := NewRoleCommandHandler(mockRepo, new(MockRoleRepository), mockAssignments, new(MockEventBus), &MockTransactionManager{})
+	handler := NewTenantAdminCommandHandler(createHandler, roleHandler)
+
+	mockRepo.On("ExistsByEmail", ctx, "admin@acme.com", "tenant-1").Return(false, nil)
+	mockRepo.On("Save", ctx, mock.AnythingOfType("*domain.User")).Return(nil)
+	mockTokens.On("InvalidateForUser", ctx, mock.AnythingOfType("string"), "tenant-1", domain.TokenPurposeEmailVerification).Return(nil)
+	mockTokens.On("Save", ctx, mock.AnythingOfType("*domain.UserToken")).Return(nil)
+	mockNotifier.On("NotifyToken", ctx, mock.AnythingOfType("commands.TokenNotice")).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.Anything).Return(nil)
+	mockAssignments.On("CountByRole", ctx, "tenant-1", domain.RoleAdmin).Return(1, nil)
+
+	_, err := handler.Handle(ctx, CreateTenantAdminCommand{
+		TenantID: "tenant-1",
+		Name:     "Jane Admin",
+		Email:    "admin@acme.com",
+		Password: "SecurePass123!",
+	})
+
+	assert.ErrorIs(t, err, domain.ErrTenantHasAdmin)
+	mockAssignments.AssertNotCalled(t, "Assign", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// TokenNotifier hace llegar al usuario el link con el token en claro. El token
+// no viaja en los eventos: quedaria guardado en el outbox y pasaria por la cola
+type TokenNotifier interface {
+	NotifyToken(ctx context.Context, notice TokenNotice) error
+}
+
+// TokenNotice usa el EventID del evento publicado junto con el token para que
+// la notificacion no se duplique
+type TokenNotice struct {
+	EventID       string
+	TenantID      string
+	UserID        string
+	Purpose       domain.TokenPurpose
+	Recipient     string
+	Token         string
+	ExpiresAt     time.Time
+	CorrelationID string
+}
+
+func newTokenNotice(eventID string, token *domain.UserToken, recipient, correlationID string) TokenNotice {
+	return TokenNotice{
+		EventID:       eventID,
+		TenantID:      token.TenantID(),
+		UserID:        token.UserID(),
+		Purpose:       token.Purpose(),
+		Recipient:     recipient,
+		Token:         token.Token(),
+		ExpiresAt:     token.ExpiresAt(),
+		CorrelationID: correlationID,
+	}
+}
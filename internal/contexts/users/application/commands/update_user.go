@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// UpdateUserCommand con semantica de PATCH: Name nil no se toca, y el display
+// name solo se cambia si SetDisplayName (DisplayName nil lo borra)
+type UpdateUserCommand struct {
+	UserID         string
+	TenantID       string
+	Name           *string
+	DisplayName    *string
+	SetDisplayName bool
+	CorrelationID  string
+}
+
+type UpdateUserCommandHandler struct {
+	repository domain.UserRepository
+	eventBus   EventBus
+	txManager  TransactionManager
+}
+
+func NewUpdateUserCommandHandler(repo domain.UserRepository, eventBus EventBus, txManager TransactionManager) *UpdateUserCommandHandler {
+	return &UpdateUserCommandHandler{
+		repository: repo,
+		eventBus:   eventBus,
+		txManager:  txManager,
+	}
+}
+
+func (h *UpdateUserCommandHandler) Handle(ctx context.Context, cmd UpdateUserCommand) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+		if user.IsDeleted() {
+			return domain.ErrUserNotFound
+		}
+
+		var changed []string
+
+		if cmd.Name != nil && *cmd.Name != user.Name() {
+			if err := user.Rename(*cmd.Name); err != nil {
+				return err
+			}
+			changed = append(changed, "name")
+		}
+
+		if cmd.SetDisplayName && !sameDisplayName(user.DisplayName(), cmd.DisplayName) {
+			user.ChangeDisplayName(cmd.DisplayName)
+			changed = append(changed, "display_name")
+		}
+
+		// nada cambio: no se guarda ni se publica evento
+		if len(changed) == 0 {
+			return nil
+		}
+
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserUpdatedEvent(user, cmd.CorrelationID, changed))
+	})
+}
+
+func sameDisplayName(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
+
+func newExistingUser(displayName *string) *domain.User {
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := domain.NewUser("John Doe", email, password, "tenant-1", displayName)
+	return user
+}
+
+func TestUpdateUserCommandHandler_PartialUpdate(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUpdateUserCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{})
+
+	displayName := "Johnny"
+	user := newExistingUser(&displayName)
+	newName := "John Smith"
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserUpdatedEvent")).Return(nil)
+
+	err := handler.Handle(ctx, UpdateUserCommand{
+		UserID:   user.ID(),
+		TenantID: "tenant-1",
+		Name:     &newName,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "John Smith", user.Name())
+	// display_name no vino en el comando, se mantiene
+	assert.Equal(t, "Johnny", *user.DisplayName())
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserUpdatedEvent)
+	assert.Equal(t, []string{"name"}, event.ChangedFields)
+	assert.Equal(t, "Johnny", *event.DisplayName)
+}
+
+func TestUpdateUserCommandHandler_ClearDisplayName(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUpdateUserCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{})
+
+	displayName := "Johnny"
+	user := newExistingUser(&displayName)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserUpdatedEvent")).Return(nil)
+
+	err := handler.Handle(ctx, UpdateUserCommand{
+		UserID:         user.ID(),
+		TenantID:       "tenant-1",
+		SetDisplayName: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, user.DisplayName())
+	assert.Equal(t, "John Doe", user.Name())
+}
+
+func TestUpdateUserCommandHandler_NoChanges(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUpdateUserCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{})
+
+	user := newExistingUser(nil)
+	sameName := "John Doe"
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Handle(ctx, UpdateUserCommand{
+		UserID:   user.ID(),
+		TenantID: "tenant-1",
+		Name:     &sameName,
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestUpdateUserCommandHandler_EmptyName(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUpdateUserCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{})
+
+	user := newExistingUser(nil)
+	empty := ""
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Handle(ctx, UpdateUserCommand{
+		UserID:   user.ID(),
+		TenantID: "tenant-1",
+		Name:     &empty,
+	})
+
+	assert.Equal(t, domain.ErrInvalidUserName, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+}
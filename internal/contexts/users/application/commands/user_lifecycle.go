@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// UserLifecycleCommand sirve para deactivate, reactivate, delete y restore
+type UserLifecycleCommand struct {
+	UserID        string
+	TenantID      string
+	CorrelationID string
+}
+
+// UserLifecycleCommandHandler maneja los cambios de estado de un usuario.
+// Todos siguen el mismo camino: cargar, transicionar, guardar y publicar
+type UserLifecycleCommandHandler struct {
+	repository  domain.UserRepository
+	assignments domain.RoleAssignmentRepository
+	eventBus    EventBus
+	txManager   TransactionManager
+	purgeAfter  time.Duration
+}
+
+func NewUserLifecycleCommandHandler(repo domain.UserRepository, assignments domain.RoleAssignmentRepository, eventBus EventBus, txManager TransactionManager, purgeAfter time.Duration) *UserLifecycleCommandHandler {
+	return &UserLifecycleCommandHandler{
+		repository:  repo,
+		assignments: assignments,
+		eventBus:    eventBus,
+		txManager:   txManager,
+		purgeAfter:  purgeAfter,
+	}
+}
+
+// Deactivate y Delete no dejan al tenant sin admins activos
+func (h *UserLifecycleCommandHandler) Deactivate(ctx context.Context, cmd UserLifecycleCommand) error {
+	return h.transition(ctx, cmd, domain.UserDeactivatedEventType, h.keepAdmin, (*domain.User).Deactivate)
+}
+
+func (h *UserLifecycleCommandHandler) Reactivate(ctx context.Context, cmd UserLifecycleCommand) error {
+	return h.transition(ctx, cmd, domain.UserReactivatedEventType, nil, (*domain.User).Reactivate)
+}
+
+func (h *UserLifecycleCommandHandler) Delete(ctx context.Context, cmd UserLifecycleCommand) error {
+	return h.transition(ctx, cmd, domain.UserDeletedEventType, h.keepAdmin, (*domain.User).Delete)
+}
+
+func (h *UserLifecycleCommandHandler) Restore(ctx context.Context, cmd UserLifecycleCommand) error {
+	return h.transition(ctx, cmd, domain.UserRestoredEventType, nil, func(user *domain.User) error {
+		return user.Restore(h.purgeAfter)
+	})
+}
+
+func (h *UserLifecycleCommandHandler) keepAdmin(ctx context.Context, user *domain.User) error {
+	role, err := h.assignments.RoleOf(ctx, user.ID(), user.TenantID())
+	if err != nil {
+		return err
+	}
+	return ensureNotLastAdmin(ctx, h.assignments, user, role)
+}
+
+// transition carga, chequea (check puede ser nil), transiciona, guarda y publica
+func (h *UserLifecycleCommandHandler) transition(ctx context.Context, cmd UserLifecycleCommand, eventType string, check func(context.Context, *domain.User) error, apply func(*domain.User) error) error {
+	return h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := h.repository.FindByID(ctx, cmd.UserID, cmd.TenantID)
+		if err != nil {
+			return err
+		}
+
+		if check != nil {
+			if err := check(ctx, user); err != nil {
+				return err
+			}
+		}
+
+		if err := apply(user); err != nil {
+			return err
+		}
+
+		if err := h.repository.Save(ctx, user); err != nil {
+			return err
+		}
+
+		return h.eventBus.Publish(ctx, domain.NewUserStatusChangedEvent(eventType, user, cmd.CorrelationID))
+	})
+}
+
+// PurgeableUserRepository es lo que necesita la purga ademas del repositorio comun
+type PurgeableUserRepository interface {
+	FindDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error)
+	Delete(ctx context.Context, id, tenantID string) error
+}
+
+// PurgeDeletedUsersCommandHandler borra definitivamente a los usuarios cuya
+// ventana de restauracion vencio, liberando el email para registrarse de nuevo
+type PurgeDeletedUsersCommandHandler struct {
+	repository PurgeableUserRepository
+	eventBus   EventBus
+	txManager  TransactionManager
+	purgeAfter time.Duration
+	batchSize  int
+}
+
+func NewPurgeDeletedUsersCommandHandler(repo PurgeableUserRepository, eventBus EventBus, txManager TransactionManager, purgeAfter time.Duration, batchSize int) *PurgeDeletedUsersCommandHandler {
+	return &PurgeDeletedUsersCommandHandler{
+		repository: repo,
+		eventBus:   eventBus,
+		txManager:  txManager,
+		purgeAfter: purgeAfter,
+		batchSize:  batchSize,
+	}
+}
+
+// Handle purga un lote y devuelve cuantos usuarios se borraron
+func (h *PurgeDeletedUsersCommandHandler) Handle(ctx context.Context) (int, error) {
+	purged := 0
+	cutoff := time.Now().UTC().Add(-h.purgeAfter)
+
+	err := h.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		users, err := h.repository.FindDeletedBefore(ctx, cutoff, h.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := h.repository.Delete(ctx, user.ID(), user.TenantID()); err != nil {
+				return err
+			}
+			if err := h.eventBus.Publish(ctx, domain.NewUserStatusChangedEvent(domain.UserPurgedEventType, user, "")); err != nil {
+				return err
+			}
+			purged++
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
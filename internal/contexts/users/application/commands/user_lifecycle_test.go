@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type MockPurgeableUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockPurgeableUserRepository) FindDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).([]*domain.User), args.Error(1)
+}
+
+func (m *MockPurgeableUserRepository) Delete(ctx context.Context, id, tenantID string) error {
+	args := m.Called(ctx, id, tenantID)
+	return args.Error(0)
+}
+
+func TestUserLifecycleCommandHandler_Deactivate(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUserLifecycleCommandHandler(mockRepo, mockAssignments, mockEventBus, &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return(domain.RoleMember, nil)
+	mockRepo.On("Save", ctx, user).Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserStatusChangedEvent")).Return(nil)
+
+	err := handler.Deactivate(ctx, UserLifecycleCommand{UserID: user.ID(), TenantID: "tenant-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.StatusSuspended, user.Status())
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserStatusChangedEvent)
+	assert.Equal(t, domain.UserDeactivatedEventType, event.EventType())
+	assert.Equal(t, "suspended", event.Status)
+}
+
+func TestUserLifecycleCommandHandler_InvalidTransition(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUserLifecycleCommandHandler(mockRepo, new(MockRoleAssignmentRepository), mockEventBus, &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+
+	err := handler.Restore(ctx, UserLifecycleCommand{UserID: user.ID(), TenantID: "tenant-1"})
+
+	assert.Equal(t, domain.ErrInvalidStatusTransition, err)
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// ni borrar ni suspender al unico admin activo
+func TestUserLifecycleCommandHandler_LastAdmin(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	mockAssignments := new(MockRoleAssignmentRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewUserLifecycleCommandHandler(mockRepo, mockAssignments, mockEventBus, &MockTransactionManager{}, time.Hour)
+
+	user := newExistingUser(nil)
+
+	mockRepo.On("FindByID", ctx, user.ID(), "tenant-1").Return(user, nil)
+	mockAssignments.On("RoleOf", ctx, user.ID(), "tenant-1").Return(domain.RoleAdmin, nil)
+	mockAssignments.On("CountByRole", ctx, "tenant-1", domain.RoleAdmin).Return(1, nil)
+
+	cmd := UserLifecycleCommand{UserID: user.ID(), TenantID: "tenant-1"}
+	assert.ErrorIs(t, handler.Delete(ctx, cmd), domain.ErrLastAdmin)
+	assert.ErrorIs(t, handler.Deactivate(ctx, cmd), domain.ErrLastAdmin)
+
+	assert.Equal(t, domain.StatusActive, user.Status())
+	mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	mockEventBus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func TestPurgeDeletedUsersCommandHandler_Handle(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockPurgeableUserRepository)
+	mockEventBus := new(MockEventBus)
+	handler := NewPurgeDeletedUsersCommandHandler(mockRepo, mockEventBus, &MockTransactionManager{}, 24*time.Hour, 10)
+
+	user := newExistingUser(nil)
+	_ = user.Delete()
+
+	mockRepo.On("FindDeletedBefore", ctx, mock.AnythingOfType("time.Time"), 10).Return([]*domain.User{user}, nil)
+	mockRepo.On("Delete", ctx, user.ID(), "tenant-1").Return(nil)
+	mockEventBus.On("Publish", ctx, mock.AnythingOfType("domain.UserStatusChangedEvent")).Return(nil)
+
+	purged, err := handler.Handle(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	event := mockEventBus.Calls[0].Arguments.Get(1).(domain.UserStatusChangedEvent)
+	assert.Equal(t, domain.UserPurgedEventType, event.EventType())
+
+	// el cutoff es ahora menos la ventana de purga
+	cutoff := mockRepo.Calls[0].Arguments.Get(1).(time.Time)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), cutoff, time.Minute)
+}
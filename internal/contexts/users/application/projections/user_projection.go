@@ -2,6 +2,7 @@ package projections
 
 import (
 	"context"
+	"time"
 
 	"backend-challenge-guinea/internal/contexts/users/domain"
 )
@@ -46,8 +47,93 @@ func (p *UserProjector) ProjectUserCreated(ctx context.Context, event domain.Use
 	return nil
 }
 
+func (p *UserProjector) ProjectUserUpdated(ctx context.Context, event domain.UserUpdatedEvent) error {
+	err := p.readModelRepo.UpdateProfile(ctx, event.UserID, event.TenantID(), event.Name, event.DisplayName, event.UpdatedAt)
+	if err != nil {
+		p.log.Error("failed to update user view", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": event.UserID,
+		})
+		return err
+	}
+
+	p.log.Info("user view updated", map[string]interface{}{
+		"user_id":        event.UserID,
+		"changed_fields": event.ChangedFields,
+		"correlation_id": event.CorrelationID(),
+	})
+
+	return nil
+}
+
+func (p *UserProjector) ProjectUserEmailChanged(ctx context.Context, event domain.UserEmailChangedEvent) error {
+	if err := p.readModelRepo.UpdateEmail(ctx, event.UserID, event.TenantID(), event.NewEmail, event.EmailVerifiedAt, event.UpdatedAt); err != nil {
+		p.log.Error("failed to update user email", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": event.UserID,
+		})
+		return err
+	}
+
+	p.log.Info("user email projected", map[string]interface{}{
+		"user_id":        event.UserID,
+		"correlation_id": event.CorrelationID(),
+	})
+
+	return nil
+}
+
+func (p *UserProjector) ProjectUserEmailVerified(ctx context.Context, event domain.UserEmailVerifiedEvent) error {
+	if err := p.readModelRepo.MarkEmailVerified(ctx, event.UserID, event.TenantID(), event.VerifiedAt, event.UpdatedAt); err != nil {
+		p.log.Error("failed to mark user email verified", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": event.UserID,
+		})
+		return err
+	}
+
+	p.log.Info("user email verification projected", map[string]interface{}{
+		"user_id":        event.UserID,
+		"correlation_id": event.CorrelationID(),
+	})
+
+	return nil
+}
+
+// ProjectUserStatusChanged aplica deactivated/reactivated/deleted/restored; purged borra la fila
+func (p *UserProjector) ProjectUserStatusChanged(ctx context.Context, event domain.UserStatusChangedEvent) error {
+	var err error
+	if event.EventType() == domain.UserPurgedEventType {
+		err = p.readModelRepo.Delete(ctx, event.UserID, event.TenantID())
+	} else {
+		err = p.readModelRepo.UpdateStatus(ctx, event.UserID, event.TenantID(), event.Status, event.DeletedAt, event.UpdatedAt)
+	}
+
+	if err != nil {
+		p.log.Error("failed to project user status", map[string]interface{}{
+			"error":      err.Error(),
+			"user_id":    event.UserID,
+			"event_type": event.EventType(),
+		})
+		return err
+	}
+
+	p.log.Info("user status projected", map[string]interface{}{
+		"user_id":        event.UserID,
+		"status":         event.Status,
+		"correlation_id": event.CorrelationID(),
+	})
+
+	return nil
+}
+
 type UserReadModelRepository interface {
 	Save(ctx context.Context, view *domain.UserView) error
+	UpdateProfile(ctx context.Context, id, tenantID, name string, displayName *string, updatedAt time.Time) error
+	UpdateEmail(ctx context.Context, id, tenantID, email string, verifiedAt *time.Time, updatedAt time.Time) error
+	MarkEmailVerified(ctx context.Context, id, tenantID string, verifiedAt, updatedAt time.Time) error
+	UpdateStatus(ctx context.Context, id, tenantID, status string, deletedAt *time.Time, updatedAt time.Time) error
+	Delete(ctx context.Context, id, tenantID string) error
 	FindByID(ctx context.Context, id, tenantID string) (*domain.UserView, error)
 }
 
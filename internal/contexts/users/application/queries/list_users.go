@@ -0,0 +1,151 @@
+package queries
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort")
+	ErrInvalidStatus = errors.New("invalid status")
+)
+
+// ListUsersQuery: Sort es "created_at", "name" o con "-" adelante para
+// descendente. Por defecto los mas nuevos primero
+type ListUsersQuery struct {
+	TenantID    string
+	Cursor      string
+	Limit       int
+	EmailPrefix string
+	NamePrefix  string
+	Status      string
+	Sort        string
+}
+
+type ListUsersResult struct {
+	Users      []domain.UserView `json:"users"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+type ListUsersQueryHandler struct {
+	readModel domain.UserReadModel
+}
+
+func NewListUsersQueryHandler(readModel domain.UserReadModel) *ListUsersQueryHandler {
+	return &ListUsersQueryHandler{
+		readModel: readModel,
+	}
+}
+
+func (h *ListUsersQueryHandler) Handle(ctx context.Context, query ListUsersQuery) (*ListUsersResult, error) {
+	criteria := domain.UserListCriteria{
+		TenantID:    query.TenantID,
+		EmailPrefix: query.EmailPrefix,
+		NamePrefix:  query.NamePrefix,
+		Limit:       query.Limit,
+	}
+
+	if criteria.Limit <= 0 {
+		criteria.Limit = DefaultPageSize
+	}
+	if criteria.Limit > MaxPageSize {
+		criteria.Limit = MaxPageSize
+	}
+
+	switch domain.UserStatus(query.Status) {
+	case "", domain.StatusActive, domain.StatusSuspended, domain.StatusDeleted:
+		criteria.Status = query.Status
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	sort := query.Sort
+	if sort == "" {
+		sort = "-" + domain.SortByCreatedAt
+	}
+	criteria.Descending = strings.HasPrefix(sort, "-")
+	criteria.SortField = strings.TrimPrefix(sort, "-")
+	if criteria.SortField != domain.SortByCreatedAt && criteria.SortField != domain.SortByName {
+		return nil, ErrInvalidSort
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeCursor(query.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		criteria.After = after
+	}
+
+	// se pide una fila de mas para saber si hay otra pagina
+	limit := criteria.Limit
+	criteria.Limit++
+
+	users, err := h.readModel.FindAll(ctx, criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListUsersResult{Users: users}
+	if result.Users == nil {
+		result.Users = []domain.UserView{}
+	}
+
+	if len(users) > limit {
+		result.Users = users[:limit]
+		last := result.Users[limit-1]
+		result.NextCursor = encodeCursor(sort, sortValue(last, criteria.SortField), last.ID)
+	}
+
+	return result, nil
+}
+
+func sortValue(view domain.UserView, field string) string {
+	if field == domain.SortByName {
+		return view.Name
+	}
+	return view.CreatedAt
+}
+
+// el cursor es opaco para el cliente; lleva el orden con el que se genero
+// para que no se mezcle con otro sort
+type cursorPayload struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(sort, value, id string) string {
+	data, _ := json.Marshal(cursorPayload{Sort: sort, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor, sort string) (*domain.ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(payload.ID); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &domain.ListCursor{SortValue: payload.Value, ID: payload.ID}, nil
+}
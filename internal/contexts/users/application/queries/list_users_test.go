@@ -0,0 +1,97 @@
+package queries
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type MockUserReadModel struct {
+	mock.Mock
+}
+
+func (m *MockUserReadModel) FindByID(ctx context.Context, id, tenantID string) (*domain.UserView, error) {
+	args := m.Called(ctx, id, tenantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.UserView), args.Error(1)
+}
+
+func (m *MockUserReadModel) FindAll(ctx context.Context, criteria domain.UserListCriteria) ([]domain.UserView, error) {
+	args := m.Called(ctx, criteria)
+	return args.Get(0).([]domain.UserView), args.Error(1)
+}
+
+func TestListUsersQueryHandler_NextCursor(t *testing.T) {
+	ctx := context.Background()
+	readModel := new(MockUserReadModel)
+	handler := NewListUsersQueryHandler(readModel)
+
+	views := []domain.UserView{
+		{ID: "7d4a3b5e-3f6b-4d7c-9a3e-000000000003", Name: "C", CreatedAt: "2024-01-03T00:00:00Z"},
+		{ID: "7d4a3b5e-3f6b-4d7c-9a3e-000000000002", Name: "B", CreatedAt: "2024-01-02T00:00:00Z"},
+		{ID: "7d4a3b5e-3f6b-4d7c-9a3e-000000000001", Name: "A", CreatedAt: "2024-01-01T00:00:00Z"},
+	}
+
+	// se piden limit+1 filas, por defecto los mas nuevos primero
+	readModel.On("FindAll", ctx, mock.MatchedBy(func(c domain.UserListCriteria) bool {
+		return c.Limit == 3 && c.Descending && c.SortField == domain.SortByCreatedAt && c.After == nil
+	})).Return(views, nil).Once()
+
+	result, err := handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Limit: 2})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Users, 2)
+	require.NotEmpty(t, result.NextCursor)
+
+	// la segunda pagina arranca despues de la ultima fila devuelta
+	readModel.On("FindAll", ctx, mock.MatchedBy(func(c domain.UserListCriteria) bool {
+		return c.After != nil && c.After.ID == views[1].ID && c.After.SortValue == views[1].CreatedAt
+	})).Return(views[2:], nil).Once()
+
+	result, err = handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Limit: 2, Cursor: result.NextCursor})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Users, 1)
+	assert.Empty(t, result.NextCursor)
+}
+
+func TestListUsersQueryHandler_LimitIsCapped(t *testing.T) {
+	ctx := context.Background()
+	readModel := new(MockUserReadModel)
+	handler := NewListUsersQueryHandler(readModel)
+
+	readModel.On("FindAll", ctx, mock.MatchedBy(func(c domain.UserListCriteria) bool {
+		return c.Limit == MaxPageSize+1
+	})).Return([]domain.UserView{}, nil)
+
+	result, err := handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Limit: 10000})
+
+	require.NoError(t, err)
+	assert.NotNil(t, result.Users)
+}
+
+func TestListUsersQueryHandler_InvalidInput(t *testing.T) {
+	ctx := context.Background()
+	handler := NewListUsersQueryHandler(new(MockUserReadModel))
+
+	_, err := handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Sort: "password"})
+	assert.Equal(t, ErrInvalidSort, err)
+
+	_, err = handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Status: "banned"})
+	assert.Equal(t, ErrInvalidStatus, err)
+
+	_, err = handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Cursor: "not-a-cursor"})
+	assert.Equal(t, ErrInvalidCursor, err)
+
+	// un cursor generado con otro orden no sirve
+	cursor := encodeCursor("name", "John", "7d4a3b5e-3f6b-4d7c-9a3e-000000000001")
+	_, err = handler.Handle(ctx, ListUsersQuery{TenantID: "tenant-1", Cursor: cursor, Sort: "-created_at"})
+	assert.Equal(t, ErrInvalidCursor, err)
+}
@@ -0,0 +1,72 @@
+package queries
+
+import (
+	"context"
+	"errors"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type ListRolesQuery struct {
+	TenantID string
+}
+
+// ListRolesQueryHandler devuelve los roles predefinidos y despues los custom del tenant
+type ListRolesQueryHandler struct {
+	roles domain.RoleRepository
+}
+
+func NewListRolesQueryHandler(roles domain.RoleRepository) *ListRolesQueryHandler {
+	return &ListRolesQueryHandler{
+		roles: roles,
+	}
+}
+
+func (h *ListRolesQueryHandler) Handle(ctx context.Context, query ListRolesQuery) ([]*domain.Role, error) {
+	custom, err := h.roles.FindAll(ctx, query.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(domain.BuiltinRoles(query.TenantID), custom...), nil
+}
+
+type CheckPermissionQuery struct {
+	UserID     string
+	TenantID   string
+	Permission string
+}
+
+// CheckPermissionQueryHandler resuelve el rol del usuario y dice si incluye el permiso
+type CheckPermissionQueryHandler struct {
+	roles       domain.RoleRepository
+	assignments domain.RoleAssignmentRepository
+}
+
+func NewCheckPermissionQueryHandler(roles domain.RoleRepository, assignments domain.RoleAssignmentRepository) *CheckPermissionQueryHandler {
+	return &CheckPermissionQueryHandler{
+		roles:       roles,
+		assignments: assignments,
+	}
+}
+
+func (h *CheckPermissionQueryHandler) Handle(ctx context.Context, query CheckPermissionQuery) (bool, error) {
+	name, err := h.assignments.RoleOf(ctx, query.UserID, query.TenantID)
+	if err != nil {
+		return false, err
+	}
+
+	role := domain.BuiltinRole(query.TenantID, name)
+	if role == nil {
+		role, err = h.roles.FindByName(ctx, query.TenantID, name)
+		if err != nil {
+			// un rol que ya no existe no da ningun permiso
+			if errors.Is(err, domain.ErrRoleNotFound) {
+				return false, nil
+			}
+			return false, err
+		}
+	}
+
+	return role.HasPermission(query.Permission), nil
+}
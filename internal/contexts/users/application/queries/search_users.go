@@ -0,0 +1,159 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 50
+
+	minSearchLength = 2
+	maxSearchLength = 100
+
+	// mismo umbral que usa pg_trgm por defecto para el operador %
+	fuzzyHighlightThreshold = 0.3
+)
+
+var ErrSearchTooShort = errors.New("search text too short")
+
+type SearchUsersQuery struct {
+	TenantID string
+	Text     string
+	Limit    int
+}
+
+// UserSearchResult trae los campos que matchearon resaltados con <mark>,
+// el resto del valor va escapado para poder mostrarlo como HTML
+type UserSearchResult struct {
+	domain.UserView
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+type SearchUsersResult struct {
+	Users []UserSearchResult `json:"users"`
+}
+
+type UserSearchReadModel interface {
+	Search(ctx context.Context, tenantID, text, tsQuery string, limit int) ([]domain.UserSearchHit, error)
+}
+
+type SearchUsersQueryHandler struct {
+	readModel UserSearchReadModel
+}
+
+func NewSearchUsersQueryHandler(readModel UserSearchReadModel) *SearchUsersQueryHandler {
+	return &SearchUsersQueryHandler{
+		readModel: readModel,
+	}
+}
+
+func (h *SearchUsersQueryHandler) Handle(ctx context.Context, query SearchUsersQuery) (*SearchUsersResult, error) {
+	runes := []rune(strings.TrimSpace(query.Text))
+	if len(runes) < minSearchLength {
+		return nil, ErrSearchTooShort
+	}
+	if len(runes) > maxSearchLength {
+		runes = runes[:maxSearchLength]
+	}
+	text := string(runes)
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	hits, err := h.readModel.Search(ctx, query.TenantID, text, buildPrefixTSQuery(text), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := termsMatcher(text)
+	result := &SearchUsersResult{Users: make([]UserSearchResult, 0, len(hits))}
+
+	for _, hit := range hits {
+		highlights := map[string]string{}
+
+		addHighlight(highlights, "name", hit.Name, hit.NameSimilarity, matcher)
+		if hit.DisplayName != nil {
+			addHighlight(highlights, "display_name", *hit.DisplayName, hit.DisplayNameSimilarity, matcher)
+		}
+		addHighlight(highlights, "email", hit.Email, hit.EmailSimilarity, matcher)
+
+		result.Users = append(result.Users, UserSearchResult{
+			UserView:   hit.UserView,
+			Score:      hit.Rank + maxFloat(hit.NameSimilarity, hit.DisplayNameSimilarity, hit.EmailSimilarity),
+			Highlights: highlights,
+		})
+	}
+
+	return result, nil
+}
+
+// buildPrefixTSQuery arma "term1:* & term2:*" solo con letras y digitos, asi el
+// texto del usuario nunca llega crudo a to_tsquery
+func buildPrefixTSQuery(text string) string {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+func termsMatcher(text string) *regexp.Regexp {
+	terms := strings.Fields(text)
+	for i, term := range terms {
+		terms[i] = regexp.QuoteMeta(term)
+	}
+	return regexp.MustCompile("(?i)(" + strings.Join(terms, "|") + ")")
+}
+
+// addHighlight marca las apariciones literales de los terminos; si el campo
+// matcheo solo por similitud (un typo) se marca el valor entero
+func addHighlight(highlights map[string]string, field, value string, similarity float64, matcher *regexp.Regexp) {
+	matches := matcher.FindAllStringIndex(value, -1)
+
+	if len(matches) == 0 {
+		if similarity >= fuzzyHighlightThreshold {
+			highlights[field] = "<mark>" + html.EscapeString(value) + "</mark>"
+		}
+		return
+	}
+
+	var b strings.Builder
+	prev := 0
+	for _, m := range matches {
+		b.WriteString(html.EscapeString(value[prev:m[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(value[m[0]:m[1]]))
+		b.WriteString("</mark>")
+		prev = m[1]
+	}
+	b.WriteString(html.EscapeString(value[prev:]))
+
+	highlights[field] = b.String()
+}
+
+func maxFloat(values ...float64) float64 {
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max
+}
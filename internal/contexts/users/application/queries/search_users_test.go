@@ -0,0 +1,86 @@
+package queries
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+type MockUserSearchReadModel struct {
+	mock.Mock
+}
+
+func (m *MockUserSearchReadModel) Search(ctx context.Context, tenantID, text, tsQuery string, limit int) ([]domain.UserSearchHit, error) {
+	args := m.Called(ctx, tenantID, text, tsQuery, limit)
+	return args.Get(0).([]domain.UserSearchHit), args.Error(1)
+}
+
+func TestSearchUsersQueryHandler_Highlights(t *testing.T) {
+	ctx := context.Background()
+	readModel := new(MockUserSearchReadModel)
+	handler := NewSearchUsersQueryHandler(readModel)
+
+	displayName := "<b>Johnny</b>"
+	hits := []domain.UserSearchHit{
+		{
+			UserView:              domain.UserView{ID: "user-1", Name: "John Doe", Email: "jdoe@example.com", DisplayName: &displayName},
+			Rank:                  0.5,
+			NameSimilarity:        0.4,
+			DisplayNameSimilarity: 0.2,
+			EmailSimilarity:       0.1,
+		},
+	}
+
+	readModel.On("Search", ctx, "tenant-1", "john", "john:*", DefaultSearchLimit).Return(hits, nil)
+
+	result, err := handler.Handle(ctx, SearchUsersQuery{TenantID: "tenant-1", Text: "  john "})
+
+	require.NoError(t, err)
+	require.Len(t, result.Users, 1)
+
+	user := result.Users[0]
+	assert.InDelta(t, 0.9, user.Score, 0.0001)
+	assert.Equal(t, "<mark>John</mark> Doe", user.Highlights["name"])
+	// el resto del valor se escapa
+	assert.Equal(t, "&lt;b&gt;<mark>John</mark>ny&lt;/b&gt;", user.Highlights["display_name"])
+	assert.NotContains(t, user.Highlights, "email")
+}
+
+func TestSearchUsersQueryHandler_FuzzyMatchHighlightsWholeField(t *testing.T) {
+	ctx := context.Background()
+	readModel := new(MockUserSearchReadModel)
+	handler := NewSearchUsersQueryHandler(readModel)
+
+	hits := []domain.UserSearchHit{
+		{
+			UserView:        domain.UserView{ID: "user-1", Name: "John Doe", Email: "john@example.com"},
+			EmailSimilarity: 0.5,
+		},
+	}
+
+	readModel.On("Search", ctx, "tenant-1", "jhon@exmaple.com", "jhon:* & exmaple:* & com:*", DefaultSearchLimit).Return(hits, nil)
+
+	result, err := handler.Handle(ctx, SearchUsersQuery{TenantID: "tenant-1", Text: "jhon@exmaple.com"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "<mark>john@example.com</mark>", result.Users[0].Highlights["email"])
+	assert.NotContains(t, result.Users[0].Highlights, "name")
+}
+
+func TestSearchUsersQueryHandler_TooShort(t *testing.T) {
+	handler := NewSearchUsersQueryHandler(new(MockUserSearchReadModel))
+
+	_, err := handler.Handle(context.Background(), SearchUsersQuery{TenantID: "tenant-1", Text: " a "})
+
+	assert.Equal(t, ErrSearchTooShort, err)
+}
+
+func TestBuildPrefixTSQuery_StripsOperators(t *testing.T) {
+	assert.Equal(t, "john:* & doe:*", buildPrefixTSQuery("John & | !Doe:*"))
+	assert.Equal(t, "", buildPrefixTSQuery("&|!"))
+}
@@ -3,8 +3,22 @@ package domain
 import "errors"
 
 var (
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrInvalidUserName   = errors.New("invalid user name")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-)
\ No newline at end of file
+	ErrUserNotFound            = errors.New("user not found")
+	ErrUserAlreadyExists       = errors.New("user already exists")
+	ErrInvalidUserName         = errors.New("invalid user name")
+	ErrInvalidCredentials      = errors.New("invalid credentials")
+	ErrTooManyAttempts         = errors.New("too many failed password attempts")
+	ErrInvalidStatusTransition = errors.New("invalid user status transition")
+	ErrRestoreWindowExpired    = errors.New("user can no longer be restored")
+	ErrInvalidToken            = errors.New("invalid or expired token")
+	ErrEmailUnchanged          = errors.New("new email is the same as the current one")
+	ErrEmailAlreadyVerified    = errors.New("email is already verified")
+	ErrRoleNotFound            = errors.New("role not found")
+	ErrInvalidRoleName         = errors.New("invalid role name")
+	ErrBuiltinRole             = errors.New("built-in roles cannot be modified")
+	ErrUnknownPermission       = errors.New("unknown permission")
+	ErrRoleInUse               = errors.New("role is assigned to users")
+	ErrLastAdmin               = errors.New("tenant must keep at least one admin")
+	ErrPermissionEscalation    = errors.New("cannot grant permissions you do not have")
+	ErrTenantHasAdmin          = errors.New("tenant already has an admin")
+)
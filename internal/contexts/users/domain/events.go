@@ -1,11 +1,31 @@
 package domain
 
 import (
+	"time"
+
 	shared "backend-challenge-guinea/internal/shared/domain"
 )
 
 const (
 	UserCreatedEventType = "user.created"
+	UserUpdatedEventType = "user.updated"
+
+	UserEmailChangeRequestedEventType = "user.email_change_requested"
+	UserEmailChangedEventType         = "user.email_changed"
+
+	UserEmailVerificationRequestedEventType = "user.email_verification_requested"
+	UserEmailVerifiedEventType              = "user.email_verified"
+
+	UserPasswordResetRequestedEventType = "user.password_reset_requested"
+	UserPasswordChangedEventType        = "user.password_changed"
+
+	UserDeactivatedEventType = "user.deactivated"
+	UserReactivatedEventType = "user.reactivated"
+	UserDeletedEventType     = "user.deleted"
+	UserRestoredEventType    = "user.restored"
+	UserPurgedEventType      = "user.purged"
+
+	UserRoleAssignedEventType = "user.role_assigned"
 )
 
 
@@ -25,4 +45,184 @@ func NewUserCreatedEvent(userID, name, email, tenantID, correlationID string, di
 		Email:       email,
 		DisplayName: displayName,
 	}
-}
\ No newline at end of file
+}
+
+// UserUpdatedEvent lleva el estado completo de los campos editables, asi la
+// proyeccion no depende de haber recibido los updates anteriores
+type UserUpdatedEvent struct {
+	shared.BaseEvent
+	UserID        string    `json:"user_id"`
+	Name          string    `json:"name"`
+	DisplayName   *string   `json:"display_name,omitempty"`
+	ChangedFields []string  `json:"changed_fields"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewUserUpdatedEvent(user *User, correlationID string, changedFields []string) UserUpdatedEvent {
+	return UserUpdatedEvent{
+		BaseEvent:     shared.NewBaseEvent(UserUpdatedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:        user.ID(),
+		Name:          user.Name(),
+		DisplayName:   user.DisplayName(),
+		ChangedFields: changedFields,
+		UpdatedAt:     user.UpdatedAt(),
+	}
+}
+
+// UserStatusChangedEvent se usa para todos los cambios de estado del ciclo de
+// vida (deactivated, reactivated, deleted, restored, purged), cambia solo el tipo
+type UserStatusChangedEvent struct {
+	shared.BaseEvent
+	UserID    string     `json:"user_id"`
+	Status    string     `json:"status"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+func NewUserStatusChangedEvent(eventType string, user *User, correlationID string) UserStatusChangedEvent {
+	return UserStatusChangedEvent{
+		BaseEvent: shared.NewBaseEvent(eventType, user.ID(), user.TenantID(), correlationID),
+		UserID:    user.ID(),
+		Status:    string(user.Status()),
+		DeletedAt: user.DeletedAt(),
+		UpdatedAt: user.UpdatedAt(),
+	}
+}
+
+// UserEmailChangeRequestedEvent no lleva el token: el link al nuevo email lo
+// manda TokenNotifier sin pasar por el outbox
+type UserEmailChangeRequestedEvent struct {
+	shared.BaseEvent
+	UserID    string    `json:"user_id"`
+	NewEmail  string    `json:"new_email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewUserEmailChangeRequestedEvent(token *UserToken, correlationID string) UserEmailChangeRequestedEvent {
+	return UserEmailChangeRequestedEvent{
+		BaseEvent: shared.NewBaseEvent(UserEmailChangeRequestedEventType, token.UserID(), token.TenantID(), correlationID),
+		UserID:    token.UserID(),
+		NewEmail:  token.NewEmail(),
+		ExpiresAt: token.ExpiresAt(),
+	}
+}
+
+type UserEmailChangedEvent struct {
+	shared.BaseEvent
+	UserID    string    `json:"user_id"`
+	OldEmail  string    `json:"old_email"`
+	NewEmail        string     `json:"new_email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func NewUserEmailChangedEvent(user *User, oldEmail, correlationID string) UserEmailChangedEvent {
+	return UserEmailChangedEvent{
+		BaseEvent: shared.NewBaseEvent(UserEmailChangedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:    user.ID(),
+		OldEmail:  oldEmail,
+		NewEmail:        user.Email().Value(),
+		EmailVerifiedAt: user.EmailVerifiedAt(),
+		UpdatedAt:       user.UpdatedAt(),
+	}
+}
+
+// UserEmailVerificationRequestedEvent se publica al crear el usuario y en cada
+// reenvio; el token no va en el evento
+type UserEmailVerificationRequestedEvent struct {
+	shared.BaseEvent
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewUserEmailVerificationRequestedEvent(user *User, token *UserToken, correlationID string) UserEmailVerificationRequestedEvent {
+	return UserEmailVerificationRequestedEvent{
+		BaseEvent: shared.NewBaseEvent(UserEmailVerificationRequestedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:    user.ID(),
+		Email:     user.Email().Value(),
+		ExpiresAt: token.ExpiresAt(),
+	}
+}
+
+type UserEmailVerifiedEvent struct {
+	shared.BaseEvent
+	UserID     string    `json:"user_id"`
+	Email      string    `json:"email"`
+	VerifiedAt time.Time `json:"verified_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func NewUserEmailVerifiedEvent(user *User, correlationID string) UserEmailVerifiedEvent {
+	return UserEmailVerifiedEvent{
+		BaseEvent:  shared.NewBaseEvent(UserEmailVerifiedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:     user.ID(),
+		Email:      user.Email().Value(),
+		VerifiedAt: *user.EmailVerifiedAt(),
+		UpdatedAt:  user.UpdatedAt(),
+	}
+}
+
+// UserPasswordResetRequestedEvent no lleva el token, igual que el pedido de verificacion
+type UserPasswordResetRequestedEvent struct {
+	shared.BaseEvent
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewUserPasswordResetRequestedEvent(user *User, token *UserToken, correlationID string) UserPasswordResetRequestedEvent {
+	return UserPasswordResetRequestedEvent{
+		BaseEvent: shared.NewBaseEvent(UserPasswordResetRequestedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:    user.ID(),
+		Email:     user.Email().Value(),
+		ExpiresAt: token.ExpiresAt(),
+	}
+}
+
+const (
+	PasswordChangeReasonChanged = "changed"
+	PasswordChangeReasonReset   = "reset"
+)
+
+// UserPasswordChangedEvent dispara la revocacion de sesiones; KeepSessionID es
+// la sesion desde la que el usuario cambio su password, que sigue abierta
+type UserPasswordChangedEvent struct {
+	shared.BaseEvent
+	UserID        string    `json:"user_id"`
+	Email         string    `json:"email"`
+	Reason        string    `json:"reason"`
+	KeepSessionID string    `json:"keep_session_id,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func NewUserPasswordChangedEvent(user *User, reason, keepSessionID, correlationID string) UserPasswordChangedEvent {
+	return UserPasswordChangedEvent{
+		BaseEvent:     shared.NewBaseEvent(UserPasswordChangedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:        user.ID(),
+		Email:         user.Email().Value(),
+		Reason:        reason,
+		KeepSessionID: keepSessionID,
+		UpdatedAt:     user.UpdatedAt(),
+	}
+}
+
+type UserRoleAssignedEvent struct {
+	shared.BaseEvent
+	UserID       string    `json:"user_id"`
+	Role         string    `json:"role"`
+	PreviousRole string    `json:"previous_role"`
+	AssignedBy   string    `json:"assigned_by"`
+	AssignedAt   time.Time `json:"assigned_at"`
+}
+
+func NewUserRoleAssignedEvent(user *User, role, previousRole, assignedBy, correlationID string) UserRoleAssignedEvent {
+	return UserRoleAssignedEvent{
+		BaseEvent:    shared.NewBaseEvent(UserRoleAssignedEventType, user.ID(), user.TenantID(), correlationID),
+		UserID:       user.ID(),
+		Role:         role,
+		PreviousRole: previousRole,
+		AssignedBy:   assignedBy,
+		AssignedAt:   time.Now().UTC(),
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "context"
+
+// PasswordScreener dice si una password aparece en una lista de passwords
+// comunes o filtradas
+type PasswordScreener interface {
+	IsCompromised(ctx context.Context, plainPassword string) (bool, error)
+}
@@ -1,24 +1,105 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+)
 
 type UserRepository interface {
 	Save(ctx context.Context, user *User) error
 	FindByID(ctx context.Context, id, tenantID string) (*User, error)
 	FindByEmail(ctx context.Context, email, tenantID string) (*User, error)
 	ExistsByEmail(ctx context.Context, email, tenantID string) (bool, error)
+	// UpdatePasswordHash reemplaza el hash solo si sigue siendo currentHash, para
+	// que un re-hash al loguearse no pise un cambio de password en paralelo
+	UpdatePasswordHash(ctx context.Context, id, tenantID, currentHash, newHash string) error
+}
+
+// UserTokenRepository guarda los tokens de un solo uso (cambio de email, verificacion, reset)
+type UserTokenRepository interface {
+	Save(ctx context.Context, token *UserToken) error
+	FindByHash(ctx context.Context, tokenHash string, purpose TokenPurpose) (*UserToken, error)
+	InvalidateForUser(ctx context.Context, userID, tenantID string, purpose TokenPurpose) error
+	// LastIssuedAt devuelve cuando se emitio el ultimo token del usuario (nil si nunca)
+	LastIssuedAt(ctx context.Context, userID, tenantID string, purpose TokenPurpose) (*time.Time, error)
+}
+
+// PasswordPolicyRepository devuelve la politica por defecto si el tenant no configuro una
+type PasswordPolicyRepository interface {
+	FindByTenant(ctx context.Context, tenantID string) (vo.PasswordPolicy, error)
+}
+
+// PasswordHistoryRepository guarda las passwords anteriores de cada usuario
+type PasswordHistoryRepository interface {
+	// Recent devuelve las ultimas limit, de la mas nueva a la mas vieja
+	Recent(ctx context.Context, userID, tenantID string, limit int) ([]vo.Password, error)
+	// Add agrega la password reemplazada y borra las que exceden keep
+	Add(ctx context.Context, userID, tenantID string, password vo.Password, keep int) error
+}
+
+// RoleRepository guarda solo los roles custom; los predefinidos viven en codigo
+type RoleRepository interface {
+	Save(ctx context.Context, role *Role) error
+	FindByName(ctx context.Context, tenantID, name string) (*Role, error)
+	FindAll(ctx context.Context, tenantID string) ([]*Role, error)
+	Delete(ctx context.Context, tenantID, name string) error
+}
+
+// RoleAssignmentRepository guarda el rol de cada usuario (uno por usuario)
+type RoleAssignmentRepository interface {
+	// RoleOf devuelve DefaultRole si al usuario nunca se le asigno uno
+	RoleOf(ctx context.Context, userID, tenantID string) (string, error)
+	Assign(ctx context.Context, userID, tenantID, role, assignedBy string) error
+	CountByRole(ctx context.Context, tenantID, role string) (int, error)
 }
 
 type UserReadModel interface {
 	FindByID(ctx context.Context, id, tenantID string) (*UserView, error)
-	FindAll(ctx context.Context, tenantID string) ([]UserView, error)
+	FindAll(ctx context.Context, criteria UserListCriteria) ([]UserView, error)
+}
+
+const (
+	SortByCreatedAt = "created_at"
+	SortByName      = "name"
+)
+
+// UserListCriteria es un listado por keyset: After es la ultima fila de la
+// pagina anterior, el orden siempre desempata por id
+type UserListCriteria struct {
+	TenantID    string
+	EmailPrefix string
+	NamePrefix  string
+	Status      string // vacio = todos menos los borrados
+	SortField   string
+	Descending  bool
+	After       *ListCursor
+	Limit       int
+}
+
+type ListCursor struct {
+	SortValue string
+	ID        string
 }
 
 type UserView struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	Email       string  `json:"email"`
-	DisplayName *string `json:"display_name,omitempty"`
-	TenantID    string  `json:"tenant_id"`
-	CreatedAt   string  `json:"created_at"`
-}
\ No newline at end of file
+	ID            string  `json:"id"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	DisplayName   *string `json:"display_name,omitempty"`
+	TenantID      string  `json:"tenant_id"`
+	Status        string  `json:"status"`
+	EmailVerified bool    `json:"email_verified"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// UserSearchHit es un resultado de busqueda con la similitud de cada campo,
+// que se usa para decidir que resaltar
+type UserSearchHit struct {
+	UserView
+	Rank                  float64
+	NameSimilarity        float64
+	DisplayNameSimilarity float64
+	EmailSimilarity       float64
+}
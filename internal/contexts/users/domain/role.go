@@ -0,0 +1,203 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"time"
+)
+
+// permisos que chequea el middleware de autorizacion; formato recurso:accion
+const (
+	PermissionUsersCreate    = "users:create"
+	PermissionUsersRead      = "users:read"
+	PermissionUsersUpdate    = "users:update"
+	PermissionUsersDelete    = "users:delete"
+	PermissionUsersEmail     = "users:email"
+	PermissionProfileUpdate  = "profile:update"
+	PermissionRolesRead      = "roles:read"
+	PermissionRolesManage    = "roles:manage"
+	PermissionAccountsUnlock = "accounts:unlock"
+)
+
+// roles predefinidos, existen en todos los tenants y no se pueden redefinir
+const (
+	RoleAdmin    = "admin"
+	RoleMember   = "member"
+	RoleReadOnly = "read_only"
+)
+
+// DefaultRole es el rol de un usuario al que nunca se le asigno uno
+const DefaultRole = RoleMember
+
+var allPermissions = []string{
+	PermissionUsersCreate,
+	PermissionUsersRead,
+	PermissionUsersUpdate,
+	PermissionUsersDelete,
+	PermissionUsersEmail,
+	PermissionProfileUpdate,
+	PermissionRolesRead,
+	PermissionRolesManage,
+	PermissionAccountsUnlock,
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+// Role es un conjunto de permisos con nombre dentro de un tenant
+type Role struct {
+	name        string
+	tenantID    string
+	description string
+	permissions []string
+	builtin     bool
+	createdAt   time.Time
+	updatedAt   time.Time
+}
+
+// NewCustomRole valida el nombre y que todos los permisos existan
+func NewCustomRole(tenantID, name, description string, permissions []string) (*Role, error) {
+	if !roleNamePattern.MatchString(name) {
+		return nil, ErrInvalidRoleName
+	}
+	if IsBuiltinRole(name) {
+		return nil, ErrBuiltinRole
+	}
+
+	normalized, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	return &Role{
+		name:        name,
+		tenantID:    tenantID,
+		description: description,
+		permissions: normalized,
+		createdAt:   now,
+		updatedAt:   now,
+	}, nil
+}
+
+// RebuildRole reconstruye un rol custom desde persistencia
+func RebuildRole(tenantID, name, description string, permissions []string, createdAt, updatedAt time.Time) *Role {
+	return &Role{
+		name:        name,
+		tenantID:    tenantID,
+		description: description,
+		permissions: permissions,
+		createdAt:   createdAt,
+		updatedAt:   updatedAt,
+	}
+}
+
+// BuiltinRoles devuelve los roles predefinidos para un tenant
+func BuiltinRoles(tenantID string) []*Role {
+	return []*Role{
+		builtinRole(tenantID, RoleAdmin, "Full access to the tenant", allPermissions),
+		builtinRole(tenantID, RoleMember, "Reads users and manages their own profile", []string{
+			PermissionUsersRead,
+			PermissionProfileUpdate,
+		}),
+		builtinRole(tenantID, RoleReadOnly, "Reads users, cannot change anything", []string{
+			PermissionUsersRead,
+		}),
+	}
+}
+
+// BuiltinRole devuelve el rol predefinido con ese nombre, nil si no es uno
+func BuiltinRole(tenantID, name string) *Role {
+	for _, role := range BuiltinRoles(tenantID) {
+		if role.name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+func IsBuiltinRole(name string) bool {
+	return name == RoleAdmin || name == RoleMember || name == RoleReadOnly
+}
+
+// IsKnownPermission dice si el permiso es uno de los que chequea la API
+func IsKnownPermission(permission string) bool {
+	for _, known := range allPermissions {
+		if known == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func builtinRole(tenantID, name, description string, permissions []string) *Role {
+	return &Role{
+		name:        name,
+		tenantID:    tenantID,
+		description: description,
+		permissions: append([]string(nil), permissions...),
+		builtin:     true,
+	}
+}
+
+// Redefine reemplaza descripcion y permisos de un rol custom
+func (r *Role) Redefine(description string, permissions []string) error {
+	if r.builtin {
+		return ErrBuiltinRole
+	}
+
+	normalized, err := normalizePermissions(permissions)
+	if err != nil {
+		return err
+	}
+
+	r.description = description
+	r.permissions = normalized
+	r.updatedAt = time.Now().UTC()
+	return nil
+}
+
+func (r *Role) HasPermission(permission string) bool {
+	for _, granted := range r.permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers dice si el rol tiene todos los permisos de other; quien asigna o
+// define roles no puede dar permisos que no tiene
+func (r *Role) Covers(other *Role) bool {
+	for _, permission := range other.permissions {
+		if !r.HasPermission(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePermissions rechaza permisos desconocidos y saca duplicados
+func normalizePermissions(permissions []string) ([]string, error) {
+	seen := make(map[string]bool, len(permissions))
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !IsKnownPermission(permission) {
+			return nil, ErrUnknownPermission
+		}
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		normalized = append(normalized, permission)
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func (r *Role) Name() string          { return r.name }
+func (r *Role) TenantID() string      { return r.tenantID }
+func (r *Role) Description() string   { return r.description }
+func (r *Role) Permissions() []string { return append([]string(nil), r.permissions...) }
+func (r *Role) IsBuiltin() bool       { return r.builtin }
+func (r *Role) CreatedAt() time.Time  { return r.createdAt }
+func (r *Role) UpdatedAt() time.Time  { return r.updatedAt }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinRoles_Permissions(t *testing.T) {
+	admin := BuiltinRole("tenant-1", RoleAdmin)
+	member := BuiltinRole("tenant-1", RoleMember)
+	readOnly := BuiltinRole("tenant-1", RoleReadOnly)
+
+	assert.True(t, admin.HasPermission(PermissionRolesManage))
+	assert.True(t, admin.HasPermission(PermissionUsersCreate))
+
+	assert.True(t, member.HasPermission(PermissionUsersRead))
+	assert.True(t, member.HasPermission(PermissionProfileUpdate))
+	assert.False(t, member.HasPermission(PermissionUsersCreate))
+
+	assert.True(t, readOnly.HasPermission(PermissionUsersRead))
+	assert.False(t, readOnly.HasPermission(PermissionProfileUpdate))
+
+	assert.Nil(t, BuiltinRole("tenant-1", "auditor"))
+}
+
+func TestNewCustomRole_Validation(t *testing.T) {
+	_, err := NewCustomRole("tenant-1", "Bad Name", "", []string{PermissionUsersRead})
+	assert.ErrorIs(t, err, ErrInvalidRoleName)
+
+	_, err = NewCustomRole("tenant-1", RoleMember, "", []string{PermissionUsersRead})
+	assert.ErrorIs(t, err, ErrBuiltinRole)
+
+	_, err = NewCustomRole("tenant-1", "auditor", "", []string{"users:everything"})
+	assert.ErrorIs(t, err, ErrUnknownPermission)
+
+	role, err := NewCustomRole("tenant-1", "auditor", "reads users", []string{PermissionUsersRead})
+	assert.NoError(t, err)
+	assert.False(t, role.IsBuiltin())
+	assert.True(t, role.HasPermission(PermissionUsersRead))
+}
+
+func TestRole_RedefineBuiltin(t *testing.T) {
+	err := BuiltinRole("tenant-1", RoleAdmin).Redefine("", []string{PermissionUsersRead})
+
+	assert.ErrorIs(t, err, ErrBuiltinRole)
+}
+
+func TestRole_Covers(t *testing.T) {
+	admin := BuiltinRole("tenant-1", RoleAdmin)
+	member := BuiltinRole("tenant-1", RoleMember)
+	manager, _ := NewCustomRole("tenant-1", "manager", "", []string{PermissionRolesManage, PermissionUsersRead})
+
+	assert.True(t, admin.Covers(manager))
+	assert.True(t, manager.Covers(BuiltinRole("tenant-1", RoleReadOnly)))
+	// roles:manage no alcanza para darse admin
+	assert.False(t, manager.Covers(admin))
+	assert.False(t, manager.Covers(member))
+}
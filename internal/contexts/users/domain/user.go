@@ -7,15 +7,26 @@ import (
 	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 )
 
+type UserStatus string
+
+const (
+	StatusActive    UserStatus = "active"
+	StatusSuspended UserStatus = "suspended"
+	StatusDeleted   UserStatus = "deleted"
+)
+
 type User struct {
-	id          string      
-	name        string      
-	email       vo.Email    
-	password    vo.Password 
-	displayName *string     
-	tenantID    string      
-	createdAt   time.Time   
-	updatedAt   time.Time   
+	id              string
+	name            string
+	email           vo.Email
+	password        vo.Password
+	displayName     *string
+	tenantID        string
+	status          UserStatus
+	deletedAt       *time.Time
+	emailVerifiedAt *time.Time
+	createdAt       time.Time
+	updatedAt       time.Time
 }
 
 
@@ -34,21 +45,25 @@ func NewUser(name string, email vo.Email, password vo.Password, tenantID string,
 		password:    password,
 		displayName: displayName,
 		tenantID:    tenantID,
+		status:      StatusActive,
 		createdAt:   now,
 		updatedAt:   now,
 	}, nil
 }
 
-func Reconstitute(id, name string, email vo.Email, password vo.Password, tenantID string, displayName *string, createdAt, updatedAt time.Time) *User {
+func Reconstitute(id, name string, email vo.Email, password vo.Password, tenantID string, displayName *string, status UserStatus, deletedAt, emailVerifiedAt *time.Time, createdAt, updatedAt time.Time) *User {
 	return &User{
-		id:          id,
-		name:        name,
-		email:       email,
-		password:    password,
-		displayName: displayName,
-		tenantID:    tenantID,
-		createdAt:   createdAt,
-		updatedAt:   updatedAt,
+		id:              id,
+		name:            name,
+		email:           email,
+		password:        password,
+		displayName:     displayName,
+		tenantID:        tenantID,
+		status:          status,
+		deletedAt:       deletedAt,
+		emailVerifiedAt: emailVerifiedAt,
+		createdAt:       createdAt,
+		updatedAt:       updatedAt,
 	}
 }
 
@@ -58,5 +73,114 @@ func (u *User) Email() vo.Email       { return u.email }
 func (u *User) Password() vo.Password { return u.password }
 func (u *User) DisplayName() *string  { return u.displayName }
 func (u *User) TenantID() string      { return u.tenantID }
+func (u *User) Status() UserStatus    { return u.status }
+func (u *User) DeletedAt() *time.Time { return u.deletedAt }
+func (u *User) IsActive() bool        { return u.status == StatusActive }
+func (u *User) IsDeleted() bool       { return u.status == StatusDeleted }
 func (u *User) CreatedAt() time.Time  { return u.createdAt }
-func (u *User) UpdatedAt() time.Time  { return u.updatedAt }
\ No newline at end of file
+func (u *User) UpdatedAt() time.Time  { return u.updatedAt }
+
+func (u *User) EmailVerifiedAt() *time.Time { return u.emailVerifiedAt }
+func (u *User) IsEmailVerified() bool       { return u.emailVerifiedAt != nil }
+
+// Rename cambia el nombre validando igual que en la creacion
+func (u *User) Rename(name string) error {
+	if name == "" {
+		return ErrInvalidUserName
+	}
+
+	u.name = name
+	u.touch()
+	return nil
+}
+
+// ChangeDisplayName con nil borra el display name
+func (u *User) ChangeDisplayName(displayName *string) {
+	u.displayName = displayName
+	u.touch()
+}
+
+// ChangeEmail reemplaza el email; se llama recien cuando se confirmo el nuevo,
+// asi que el email nuevo queda verificado
+func (u *User) ChangeEmail(email vo.Email) error {
+	if email.Value() == u.email.Value() {
+		return ErrEmailUnchanged
+	}
+
+	u.email = email
+	u.touch()
+	verifiedAt := u.updatedAt
+	u.emailVerifiedAt = &verifiedAt
+	return nil
+}
+
+// ChangePassword reemplaza el hash; la validacion de la password nueva la hace vo.NewPassword
+func (u *User) ChangePassword(password vo.Password) {
+	u.password = password
+	u.touch()
+}
+
+// VerifyEmail marca el email actual como confirmado por el usuario
+func (u *User) VerifyEmail() error {
+	if u.emailVerifiedAt != nil {
+		return ErrEmailAlreadyVerified
+	}
+
+	u.touch()
+	verifiedAt := u.updatedAt
+	u.emailVerifiedAt = &verifiedAt
+	return nil
+}
+
+// Deactivate suspende al usuario: no puede loguearse pero sus datos siguen visibles
+func (u *User) Deactivate() error {
+	if u.status != StatusActive {
+		return ErrInvalidStatusTransition
+	}
+
+	u.status = StatusSuspended
+	u.touch()
+	return nil
+}
+
+func (u *User) Reactivate() error {
+	if u.status != StatusSuspended {
+		return ErrInvalidStatusTransition
+	}
+
+	u.status = StatusActive
+	u.touch()
+	return nil
+}
+
+// Delete es un borrado logico, la fila se purga cuando vence la ventana de restauracion
+func (u *User) Delete() error {
+	if u.status == StatusDeleted {
+		return ErrInvalidStatusTransition
+	}
+
+	now := time.Now().UTC()
+	u.status = StatusDeleted
+	u.deletedAt = &now
+	u.touch()
+	return nil
+}
+
+// Restore solo se permite dentro de la ventana de purga
+func (u *User) Restore(purgeAfter time.Duration) error {
+	if u.status != StatusDeleted {
+		return ErrInvalidStatusTransition
+	}
+	if u.deletedAt != nil && time.Now().UTC().After(u.deletedAt.Add(purgeAfter)) {
+		return ErrRestoreWindowExpired
+	}
+
+	u.status = StatusActive
+	u.deletedAt = nil
+	u.touch()
+	return nil
+}
+
+func (u *User) touch() {
+	u.updatedAt = time.Now().UTC()
+}
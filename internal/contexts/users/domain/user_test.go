@@ -52,6 +52,9 @@ func TestReconstitute(t *testing.T) {
 		password,
 		"tenant-1",
 		nil,
+		StatusActive,
+		nil,
+		nil,
 		time.Now(),
 		time.Now(),
 	)
@@ -59,4 +62,72 @@ func TestReconstitute(t *testing.T) {
 	assert.NotNil(t, user)
 	assert.Equal(t, "user-123", user.ID())
 	assert.Equal(t, "John Doe", user.Name())
-}
\ No newline at end of file
+}
+// Rename y ChangeDisplayName actualizan updatedAt
+func TestUser_Rename(t *testing.T) {
+	email, _ := vo.NewEmail("test@example.com")
+	password := vo.FromHash("$2a$10$...")
+	before := time.Now().Add(-time.Hour)
+
+	user := Reconstitute("user-123", "John Doe", email, password, "tenant-1", nil, StatusActive, nil, nil, before, before)
+
+	assert.NoError(t, user.Rename("John Smith"))
+	assert.Equal(t, "John Smith", user.Name())
+	assert.True(t, user.UpdatedAt().After(before))
+
+	assert.Equal(t, ErrInvalidUserName, user.Rename(""))
+	assert.Equal(t, "John Smith", user.Name())
+
+	displayName := "Johnny"
+	user.ChangeDisplayName(&displayName)
+	assert.Equal(t, "Johnny", *user.DisplayName())
+	user.ChangeDisplayName(nil)
+	assert.Nil(t, user.DisplayName())
+}
+
+func TestUser_LifecycleTransitions(t *testing.T) {
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := NewUser("John Doe", email, password, "tenant-1", nil)
+
+	assert.True(t, user.IsActive())
+	assert.Equal(t, ErrInvalidStatusTransition, user.Reactivate())
+
+	assert.NoError(t, user.Deactivate())
+	assert.Equal(t, StatusSuspended, user.Status())
+	assert.Equal(t, ErrInvalidStatusTransition, user.Deactivate())
+
+	assert.NoError(t, user.Reactivate())
+	assert.True(t, user.IsActive())
+
+	assert.NoError(t, user.Delete())
+	assert.True(t, user.IsDeleted())
+	assert.NotNil(t, user.DeletedAt())
+	assert.Equal(t, ErrInvalidStatusTransition, user.Delete())
+
+	assert.NoError(t, user.Restore(time.Hour))
+	assert.True(t, user.IsActive())
+	assert.Nil(t, user.DeletedAt())
+}
+
+func TestUser_RestoreAfterPurgeWindow(t *testing.T) {
+	email, _ := vo.NewEmail("test@example.com")
+	password := vo.FromHash("$2a$10$...")
+	deletedAt := time.Now().Add(-48 * time.Hour)
+
+	user := Reconstitute("user-123", "John Doe", email, password, "tenant-1", nil, StatusDeleted, &deletedAt, nil, deletedAt, deletedAt)
+
+	assert.Equal(t, ErrRestoreWindowExpired, user.Restore(24*time.Hour))
+	assert.True(t, user.IsDeleted())
+}
+
+func TestUser_VerifyEmail(t *testing.T) {
+	email, _ := vo.NewEmail("test@example.com")
+	password, _ := vo.NewPassword("SecurePass123!")
+	user, _ := NewUser("John Doe", email, password, "tenant-1", nil)
+
+	assert.False(t, user.IsEmailVerified())
+	assert.NoError(t, user.VerifyEmail())
+	assert.True(t, user.IsEmailVerified())
+	assert.Equal(t, ErrEmailAlreadyVerified, user.VerifyEmail())
+}
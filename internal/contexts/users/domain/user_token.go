@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TokenPurpose separa los tokens de un solo uso que se mandan por email
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailChange       TokenPurpose = "email_change"
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+)
+
+// UserToken es un token de un solo uso con vencimiento. En la base solo se
+// guarda el hash; el valor en claro existe en memoria al crearlo
+type UserToken struct {
+	id        string
+	userID    string
+	tenantID  string
+	purpose   TokenPurpose
+	token     string
+	tokenHash string
+	newEmail  string
+	expiresAt time.Time
+	usedAt    *time.Time
+	createdAt time.Time
+}
+
+func NewUserToken(userID, tenantID string, purpose TokenPurpose, ttl time.Duration) (*UserToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now().UTC()
+	return &UserToken{
+		id:        uuid.New().String(),
+		userID:    userID,
+		tenantID:  tenantID,
+		purpose:   purpose,
+		token:     token,
+		tokenHash: HashUserToken(token),
+		expiresAt: now.Add(ttl),
+		createdAt: now,
+	}, nil
+}
+
+// NewEmailChangeToken guarda el email pendiente junto con el token
+func NewEmailChangeToken(userID, tenantID string, newEmail string, ttl time.Duration) (*UserToken, error) {
+	token, err := NewUserToken(userID, tenantID, TokenPurposeEmailChange, ttl)
+	if err != nil {
+		return nil, err
+	}
+	token.newEmail = newEmail
+	return token, nil
+}
+
+func ReconstituteUserToken(id, userID, tenantID string, purpose TokenPurpose, tokenHash, newEmail string, expiresAt time.Time, usedAt *time.Time, createdAt time.Time) *UserToken {
+	return &UserToken{
+		id:        id,
+		userID:    userID,
+		tenantID:  tenantID,
+		purpose:   purpose,
+		tokenHash: tokenHash,
+		newEmail:  newEmail,
+		expiresAt: expiresAt,
+		usedAt:    usedAt,
+		createdAt: createdAt,
+	}
+}
+
+// Use consume el token; falla si ya se uso o vencio
+func (t *UserToken) Use() error {
+	now := time.Now().UTC()
+	if t.usedAt != nil || !now.Before(t.expiresAt) {
+		return ErrInvalidToken
+	}
+
+	t.usedAt = &now
+	return nil
+}
+
+func HashUserToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (t *UserToken) ID() string            { return t.id }
+func (t *UserToken) UserID() string        { return t.userID }
+func (t *UserToken) TenantID() string      { return t.tenantID }
+func (t *UserToken) Purpose() TokenPurpose { return t.purpose }
+func (t *UserToken) Token() string         { return t.token }
+func (t *UserToken) TokenHash() string     { return t.tokenHash }
+func (t *UserToken) NewEmail() string      { return t.newEmail }
+func (t *UserToken) ExpiresAt() time.Time  { return t.expiresAt }
+func (t *UserToken) UsedAt() *time.Time    { return t.usedAt }
+func (t *UserToken) CreatedAt() time.Time  { return t.createdAt }
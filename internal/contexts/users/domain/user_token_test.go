@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserToken_SingleUse(t *testing.T) {
+	token, err := NewEmailChangeToken("user-1", "tenant-1", "new@example.com", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, HashUserToken(token.Token()), token.TokenHash())
+
+	assert.NoError(t, token.Use())
+	assert.ErrorIs(t, token.Use(), ErrInvalidToken)
+}
+
+func TestUserToken_Expired(t *testing.T) {
+	expired := ReconstituteUserToken("id", "user-1", "tenant-1", TokenPurposeEmailChange, "hash", "new@example.com",
+		time.Now().UTC().Add(-time.Minute), nil, time.Now().UTC().Add(-time.Hour))
+
+	assert.ErrorIs(t, expired.Use(), ErrInvalidToken)
+}
@@ -1,31 +1,58 @@
 package http
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	
 	"backend-challenge-guinea/internal/contexts/users/application/commands"
 	"backend-challenge-guinea/internal/contexts/users/application/queries"
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
 )
 
 // Agrupa todos los handlers de usuarios
 type UserHandlers struct {
-	createUserHandler *commands.CreateUserCommandHandler
-	getUserHandler    *queries.GetUserQueryHandler
-	featureFlags      *middleware.FeatureFlags
+	createUserHandler   *commands.CreateUserCommandHandler
+	updateUserHandler   *commands.UpdateUserCommandHandler
+	lifecycleHandler    *commands.UserLifecycleCommandHandler
+	changeEmailHandler  *commands.ChangeEmailCommandHandler
+	verificationHandler *commands.EmailVerificationCommandHandler
+	passwordHandler     *commands.PasswordCommandHandler
+	getUserHandler      *queries.GetUserQueryHandler
+	listUsersHandler    *queries.ListUsersQueryHandler
+	searchHandler       *queries.SearchUsersQueryHandler
+	featureFlags        *middleware.FeatureFlags
 }
 
 func NewUserHandlers(
 	createUserHandler *commands.CreateUserCommandHandler,
+	updateUserHandler *commands.UpdateUserCommandHandler,
+	lifecycleHandler *commands.UserLifecycleCommandHandler,
+	changeEmailHandler *commands.ChangeEmailCommandHandler,
+	verificationHandler *commands.EmailVerificationCommandHandler,
+	passwordHandler *commands.PasswordCommandHandler,
 	getUserHandler *queries.GetUserQueryHandler,
+	listUsersHandler *queries.ListUsersQueryHandler,
+	searchHandler *queries.SearchUsersQueryHandler,
 	featureFlags *middleware.FeatureFlags,
 ) *UserHandlers {
 	return &UserHandlers{
-		createUserHandler: createUserHandler,
-		getUserHandler:    getUserHandler,
-		featureFlags:      featureFlags,
+		createUserHandler:   createUserHandler,
+		updateUserHandler:   updateUserHandler,
+		lifecycleHandler:    lifecycleHandler,
+		changeEmailHandler:  changeEmailHandler,
+		verificationHandler: verificationHandler,
+		passwordHandler:     passwordHandler,
+		getUserHandler:      getUserHandler,
+		listUsersHandler:    listUsersHandler,
+		searchHandler:       searchHandler,
+		featureFlags:        featureFlags,
 	}
 }
 
@@ -79,6 +106,11 @@ func (h *UserHandlers) CreateUser(c *gin.Context) {
 			return
 		}
 
+		if errors.Is(err, vo.ErrWeakPassword) {
+			respondUserError(c, err)
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -91,6 +123,434 @@ func (h *UserHandlers) CreateUser(c *gin.Context) {
 	})
 }
 
+// display_name como RawMessage para distinguir "no vino" de "vino null"
+type UpdateUserRequest struct {
+	Name        *string         `json:"name"`
+	DisplayName json.RawMessage `json:"display_name"`
+}
+
+// actualizacion parcial: solo se tocan los campos presentes en el body
+func (h *UserHandlers) UpdateUser(c *gin.Context) {
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+
+	cmd := commands.UpdateUserCommand{
+		UserID:        c.Param("id"),
+		TenantID:      tenantID,
+		Name:          req.Name,
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if req.DisplayName != nil && h.featureFlags.IsEnabled(tenantID, "user_display_name") {
+		if err := json.Unmarshal(req.DisplayName, &cmd.DisplayName); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "display_name must be a string or null",
+			})
+			return
+		}
+		cmd.SetDisplayName = true
+	}
+
+	if err := h.updateUserHandler.Handle(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// pide el cambio de email; se aplica cuando se confirma el token enviado al nuevo email
+func (h *UserHandlers) RequestEmailChange(c *gin.Context) {
+	var req ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.RequestEmailChangeCommand{
+		UserID:        c.Param("id"),
+		TenantID:      middleware.GetTenantID(c),
+		NewEmail:      req.Email,
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.changeEmailHandler.Request(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+type ConfirmEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *UserHandlers) ConfirmEmailChange(c *gin.Context) {
+	var req ConfirmEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.ConfirmEmailChangeCommand{
+		Token:         req.Token,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.changeEmailHandler.Confirm(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (h *UserHandlers) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.VerifyEmailCommand{
+		Token:         req.Token,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.verificationHandler.Verify(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// responde 202 aunque el email no exista, para no filtrar cuentas
+func (h *UserHandlers) ResendVerification(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.ResendEmailVerificationCommand{
+		Email:         req.Email,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.verificationHandler.Resend(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// cambio de password del usuario logueado; la sesion actual sigue abierta
+func (h *UserHandlers) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.ChangePasswordCommand{
+		UserID:          c.Param("id"),
+		TenantID:        middleware.GetTenantID(c),
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+		IPAddress:       c.ClientIP(),
+		CorrelationID:   middleware.GetCorrelationID(c),
+	}
+	if cmd.UserID == middleware.GetUserID(c) {
+		cmd.CurrentSessionID = middleware.GetSessionID(c)
+	}
+
+	if err := h.passwordHandler.Change(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// responde 202 aunque el email no exista, para no filtrar cuentas
+func (h *UserHandlers) RequestPasswordReset(c *gin.Context) {
+	var req PasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.RequestPasswordResetCommand{
+		Email:         req.Email,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.passwordHandler.RequestReset(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+func (h *UserHandlers) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.ResetPasswordCommand{
+		Token:         req.Token,
+		NewPassword:   req.NewPassword,
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.passwordHandler.Reset(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *UserHandlers) DeactivateUser(c *gin.Context) {
+	h.changeStatus(c, h.lifecycleHandler.Deactivate)
+}
+
+func (h *UserHandlers) ReactivateUser(c *gin.Context) {
+	h.changeStatus(c, h.lifecycleHandler.Reactivate)
+}
+
+// borrado logico, se puede restaurar hasta que se purga
+func (h *UserHandlers) DeleteUser(c *gin.Context) {
+	h.changeStatus(c, h.lifecycleHandler.Delete)
+}
+
+func (h *UserHandlers) RestoreUser(c *gin.Context) {
+	h.changeStatus(c, h.lifecycleHandler.Restore)
+}
+
+func (h *UserHandlers) changeStatus(c *gin.Context, handle func(context.Context, commands.UserLifecycleCommand) error) {
+	cmd := commands.UserLifecycleCommand{
+		UserID:        c.Param("id"),
+		TenantID:      middleware.GetTenantID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := handle(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+	case errors.Is(err, domain.ErrInvalidUserName):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrEmailUnchanged), errors.Is(err, domain.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrUserAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "user with this email already exists",
+		})
+	case errors.Is(err, vo.ErrWeakPassword):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      err.Error(),
+			"violations": passwordViolations(err),
+		})
+	case errors.Is(err, domain.ErrInvalidCredentials):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "current password is incorrect",
+		})
+	case errors.Is(err, domain.ErrTooManyAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": "too many failed password attempts, try again later",
+		})
+	case errors.Is(err, domain.ErrEmailAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrRestoreWindowExpired):
+		c.JSON(http.StatusGone, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrInvalidRoleName), errors.Is(err, domain.ErrUnknownPermission), errors.Is(err, domain.ErrBuiltinRole):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrRoleInUse), errors.Is(err, domain.ErrLastAdmin):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+		})
+	case errors.Is(err, domain.ErrPermissionEscalation):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+	}
+}
+
+// listado paginado: ?cursor=&limit=&email=&name=&status=&sort=
+func (h *UserHandlers) ListUsers(c *gin.Context) {
+	limit, ok := queryLimit(c)
+	if !ok {
+		return
+	}
+
+	query := queries.ListUsersQuery{
+		TenantID:    middleware.GetTenantID(c),
+		Cursor:      c.Query("cursor"),
+		Limit:       limit,
+		EmailPrefix: c.Query("email"),
+		NamePrefix:  c.Query("name"),
+		Status:      c.Query("status"),
+		Sort:        c.Query("sort"),
+	}
+
+	result, err := h.listUsersHandler.Handle(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, queries.ErrInvalidCursor) || errors.Is(err, queries.ErrInvalidSort) || errors.Is(err, queries.ErrInvalidStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// busqueda por nombre, display name o email, tolerante a typos: ?q=&limit=
+func (h *UserHandlers) SearchUsers(c *gin.Context) {
+	limit, ok := queryLimit(c)
+	if !ok {
+		return
+	}
+
+	query := queries.SearchUsersQuery{
+		TenantID: middleware.GetTenantID(c),
+		Text:     c.Query("q"),
+		Limit:    limit,
+	}
+
+	result, err := h.searchHandler.Handle(c.Request.Context(), query)
+	if err != nil {
+		if errors.Is(err, queries.ErrSearchTooShort) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "q must have at least 2 characters",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// queryLimit lee ?limit=; sin limit devuelve 0 (el default del handler) y
+// con un valor invalido responde 400
+func queryLimit(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, true
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be a positive number",
+		})
+		return 0, false
+	}
+	return limit, true
+}
+
 func (h *UserHandlers) GetUser(c *gin.Context) {
 
 	userID := c.Param("id")
@@ -120,15 +580,69 @@ func (h *UserHandlers) GetUser(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// registra las rutas en el router de Gin
-func (h *UserHandlers) RegisterRoutes(router *gin.Engine, rateLimiter *middleware.RateLimiter) {
+// registra las rutas en el router de Gin. Sobre otro usuario hace falta el
+// permiso del rol; sobre uno mismo alcanza con profile:update
+func (h *UserHandlers) RegisterRoutes(router *gin.Engine, tenantMiddleware gin.HandlerFunc, rateLimiter *middleware.RateLimiter, authMiddleware gin.HandlerFunc, permissions middleware.PermissionChecker) {
 
 	users := router.Group("/api/v1/users")
 	
-	users.Use(middleware.TenantMiddleware())
+	users.Use(tenantMiddleware)
 	users.Use(middleware.CorrelationIDMiddleware())
+
+	require := func(permission string) gin.HandlerFunc {
+		return middleware.RequirePermission(permissions, permission)
+	}
+	requireTarget := func(ownPermission, permission string) gin.HandlerFunc {
+		return middleware.RequireTargetPermission(permissions, "id", ownPermission, permission)
+	}
 	
 	// Rutas
-	users.POST("", rateLimiter.Middleware(), h.CreateUser)  
-	users.GET("/:id", h.GetUser)                             
-}
\ No newline at end of file
+	users.POST("", rateLimiter.Middleware(), h.selfSignup, authMiddleware, require(domain.PermissionUsersCreate), h.CreateUser)
+	users.GET("", authMiddleware, require(domain.PermissionUsersRead), h.ListUsers)
+	users.GET("/search", authMiddleware, require(domain.PermissionUsersRead), h.SearchUsers)
+	users.GET("/:id", authMiddleware, requireTarget("", domain.PermissionUsersRead), h.GetUser)
+	users.PATCH("/:id", authMiddleware, requireTarget(domain.PermissionProfileUpdate, domain.PermissionUsersUpdate), h.UpdateUser)
+	// cada uno puede suspender o borrar su propia cuenta; volver atras lo hace
+	// otro, porque una cuenta suspendida o borrada no tiene sesion
+	users.DELETE("/:id", authMiddleware, requireTarget(domain.PermissionProfileUpdate, domain.PermissionUsersDelete), h.DeleteUser)
+	users.POST("/:id/deactivate", authMiddleware, requireTarget(domain.PermissionProfileUpdate, domain.PermissionUsersUpdate), h.DeactivateUser)
+	users.POST("/:id/reactivate", authMiddleware, require(domain.PermissionUsersUpdate), h.ReactivateUser)
+	users.POST("/:id/restore", authMiddleware, require(domain.PermissionUsersDelete), h.RestoreUser)
+	// el link de confirmacion va al email nuevo: cambiar el de otro es quedarse con su cuenta
+	users.POST("/:id/email", authMiddleware, requireTarget(domain.PermissionProfileUpdate, domain.PermissionUsersEmail), h.RequestEmailChange)
+	users.POST("/confirm-email", h.ConfirmEmailChange)
+	users.POST("/verify-email", h.VerifyEmail)
+	users.POST("/verify-email/resend", rateLimiter.Middleware(), h.ResendVerification)
+	users.POST("/:id/password", authMiddleware, requireTarget(domain.PermissionProfileUpdate, domain.PermissionUsersUpdate), h.ChangePassword)
+	users.POST("/password-reset", rateLimiter.Middleware(), h.RequestPasswordReset)
+	users.POST("/password-reset/confirm", h.ResetPassword)                             
+}
+
+// selfSignup atiende el alta sin sesion si el tenant tiene el registro
+// abierto. Con token, o con el registro cerrado, sigue la cadena normal que
+// exige users:create
+func (h *UserHandlers) selfSignup(c *gin.Context) {
+	if c.GetHeader("Authorization") != "" || !h.featureFlags.IsEnabled(middleware.GetTenantID(c), middleware.FeatureSelfSignup) {
+		return
+	}
+
+	h.CreateUser(c)
+	c.Abort()
+}
+
+// passwordViolations devuelve cada regla de la politica que no se cumple
+func passwordViolations(err error) []gin.H {
+	var policyErr *vo.PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return []gin.H{}
+	}
+
+	violations := make([]gin.H, len(policyErr.Violations))
+	for i, violation := range policyErr.Violations {
+		violations[i] = gin.H{
+			"code":    violation.Code,
+			"message": violation.Message,
+		}
+	}
+	return violations
+}
@@ -0,0 +1,26 @@
+package http
+
+import (
+	"context"
+
+	"backend-challenge-guinea/internal/contexts/users/application/queries"
+)
+
+// RolePermissionChecker adapta el chequeo de permisos por rol al middleware de autorizacion
+type RolePermissionChecker struct {
+	checkPermissionHandler *queries.CheckPermissionQueryHandler
+}
+
+func NewRolePermissionChecker(checkPermissionHandler *queries.CheckPermissionQueryHandler) *RolePermissionChecker {
+	return &RolePermissionChecker{
+		checkPermissionHandler: checkPermissionHandler,
+	}
+}
+
+func (r *RolePermissionChecker) HasPermission(ctx context.Context, tenantID, userID, permission string) (bool, error) {
+	return r.checkPermissionHandler.Handle(ctx, queries.CheckPermissionQuery{
+		UserID:     userID,
+		TenantID:   tenantID,
+		Permission: permission,
+	})
+}
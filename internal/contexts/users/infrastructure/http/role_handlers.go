@@ -0,0 +1,158 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"backend-challenge-guinea/internal/contexts/users/application/commands"
+	"backend-challenge-guinea/internal/contexts/users/application/queries"
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/middleware"
+)
+
+// RoleHandlers expone los roles del tenant y la asignacion de roles a usuarios
+type RoleHandlers struct {
+	roleHandler      *commands.RoleCommandHandler
+	listRolesHandler *queries.ListRolesQueryHandler
+}
+
+func NewRoleHandlers(roleHandler *commands.RoleCommandHandler, listRolesHandler *queries.ListRolesQueryHandler) *RoleHandlers {
+	return &RoleHandlers{
+		roleHandler:      roleHandler,
+		listRolesHandler: listRolesHandler,
+	}
+}
+
+type RoleResponse struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Permissions []string   `json:"permissions"`
+	Builtin     bool       `json:"builtin"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+func newRoleResponse(role *domain.Role) RoleResponse {
+	response := RoleResponse{
+		Name:        role.Name(),
+		Description: role.Description(),
+		Permissions: role.Permissions(),
+		Builtin:     role.IsBuiltin(),
+	}
+	if !role.IsBuiltin() {
+		updatedAt := role.UpdatedAt()
+		response.UpdatedAt = &updatedAt
+	}
+	return response
+}
+
+func (h *RoleHandlers) ListRoles(c *gin.Context) {
+	roles, err := h.listRolesHandler.Handle(c.Request.Context(), queries.ListRolesQuery{
+		TenantID: middleware.GetTenantID(c),
+	})
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	response := make([]RoleResponse, len(roles))
+	for i, role := range roles {
+		response[i] = newRoleResponse(role)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roles": response,
+	})
+}
+
+type DefineRoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+// crea o reemplaza un rol custom
+func (h *RoleHandlers) DefineRole(c *gin.Context) {
+	var req DefineRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	role, err := h.roleHandler.Define(c.Request.Context(), commands.DefineRoleCommand{
+		TenantID:    middleware.GetTenantID(c),
+		Name:        c.Param("name"),
+		Description: req.Description,
+		Permissions: req.Permissions,
+		DefinedBy:   middleware.GetUserID(c),
+	})
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRoleResponse(role))
+}
+
+func (h *RoleHandlers) DeleteRole(c *gin.Context) {
+	err := h.roleHandler.Delete(c.Request.Context(), commands.DeleteRoleCommand{
+		TenantID: middleware.GetTenantID(c),
+		Name:     c.Param("name"),
+	})
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func (h *RoleHandlers) AssignRole(c *gin.Context) {
+	var req AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	cmd := commands.AssignRoleCommand{
+		UserID:        c.Param("id"),
+		TenantID:      middleware.GetTenantID(c),
+		Role:          req.Role,
+		AssignedBy:    middleware.GetUserID(c),
+		CorrelationID: middleware.GetCorrelationID(c),
+	}
+
+	if err := h.roleHandler.Assign(c.Request.Context(), cmd); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *RoleHandlers) RegisterRoutes(router *gin.Engine, tenantMiddleware gin.HandlerFunc, authMiddleware gin.HandlerFunc, permissions middleware.PermissionChecker) {
+	roles := router.Group("/api/v1/roles")
+
+	roles.Use(tenantMiddleware)
+	roles.Use(middleware.CorrelationIDMiddleware())
+	roles.Use(authMiddleware)
+
+	roles.GET("", middleware.RequirePermission(permissions, domain.PermissionRolesRead), h.ListRoles)
+	roles.PUT("/:name", middleware.RequirePermission(permissions, domain.PermissionRolesManage), h.DefineRole)
+	roles.DELETE("/:name", middleware.RequirePermission(permissions, domain.PermissionRolesManage), h.DeleteRole)
+
+	users := router.Group("/api/v1/users")
+
+	users.Use(tenantMiddleware)
+	users.Use(middleware.CorrelationIDMiddleware())
+
+	users.PUT("/:id/role", authMiddleware, middleware.RequirePermission(permissions, domain.PermissionRolesManage), h.AssignRole)
+}
@@ -0,0 +1,36 @@
+package lockout
+
+import (
+	"context"
+	"errors"
+
+	authCommands "backend-challenge-guinea/internal/contexts/auth/application/commands"
+	authDomain "backend-challenge-guinea/internal/contexts/auth/domain"
+	"backend-challenge-guinea/internal/contexts/users/domain"
+)
+
+// LoginGuard usa el LoginGuard de auth para el cambio de password: una
+// password actual errada cuenta como un login fallido y el bloqueo es el mismo
+type LoginGuard struct {
+	guard *authCommands.LoginGuard
+}
+
+func NewLoginGuard(guard *authCommands.LoginGuard) *LoginGuard {
+	return &LoginGuard{guard: guard}
+}
+
+func (g *LoginGuard) Check(ctx context.Context, tenantID, email, ip string) error {
+	err := g.guard.Check(ctx, tenantID, email, ip)
+	if errors.Is(err, authDomain.ErrAccountLocked) {
+		return domain.ErrTooManyAttempts
+	}
+	return err
+}
+
+func (g *LoginGuard) RecordFailure(ctx context.Context, tenantID, email, ip, userID, correlationID string) error {
+	return g.guard.RecordFailure(ctx, tenantID, email, ip, userID, correlationID)
+}
+
+func (g *LoginGuard) RecordSuccess(ctx context.Context, tenantID, email string) error {
+	return g.guard.RecordSuccess(ctx, tenantID, email)
+}
@@ -0,0 +1,147 @@
+package passwords
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// largo del prefijo en los archivos por rango (mismo formato que la API de rangos de HIBP)
+const rangePrefixLength = 5
+
+// DenylistScreener chequea la password contra una lista que viene con la app
+// y, opcionalmente, una que provee el operador. Funciona sin red.
+//
+// La del operador puede ser:
+//   - un archivo con un SHA-1 por linea (HASH o HASH:COUNT), que se carga en memoria
+//   - un directorio con un archivo por prefijo de 5 caracteres (ABCDE o ABCDE.txt)
+//     con lineas SUFIJO:COUNT, que se lee en cada consulta sin cargar todo
+type DenylistScreener struct {
+	common   map[string]struct{}
+	hashes   map[[sha1.Size]byte]struct{}
+	rangeDir string
+}
+
+// NewDenylistScreener con path vacio usa solo la lista de la app
+func NewDenylistScreener(path string) (*DenylistScreener, error) {
+	screener := &DenylistScreener{
+		common: loadCommonPasswords(),
+		hashes: map[[sha1.Size]byte]struct{}{},
+	}
+	if path == "" {
+		return screener, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		screener.rangeDir = path
+		return screener, nil
+	}
+
+	if err := screener.loadHashFile(path); err != nil {
+		return nil, err
+	}
+	return screener, nil
+}
+
+func (s *DenylistScreener) IsCompromised(ctx context.Context, plainPassword string) (bool, error) {
+	if _, ok := s.common[strings.ToLower(plainPassword)]; ok {
+		return true, nil
+	}
+
+	sum := sha1.Sum([]byte(plainPassword))
+	if _, ok := s.hashes[sum]; ok {
+		return true, nil
+	}
+
+	if s.rangeDir != "" {
+		return s.inRangeFile(strings.ToUpper(hex.EncodeToString(sum[:])))
+	}
+	return false, nil
+}
+
+func loadCommonPasswords() map[string]struct{} {
+	common := map[string]struct{}{}
+	for _, line := range strings.Split(commonPasswords, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		common[strings.ToLower(line)] = struct{}{}
+	}
+	return common
+}
+
+func (s *DenylistScreener) loadHashFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		hash, _, _ := strings.Cut(line, ":")
+		decoded, err := hex.DecodeString(hash)
+		if err != nil || len(decoded) != sha1.Size {
+			return fmt.Errorf("invalid SHA-1 at %s:%d", path, lineNumber)
+		}
+
+		var key [sha1.Size]byte
+		copy(key[:], decoded)
+		s.hashes[key] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// inRangeFile busca el sufijo del hash en el archivo de su prefijo; si no hay
+// archivo para el prefijo la password no esta en la lista
+func (s *DenylistScreener) inRangeFile(hash string) (bool, error) {
+	prefix, suffix := hash[:rangePrefixLength], hash[rangePrefixLength:]
+
+	var file *os.File
+	for _, name := range []string{prefix + ".txt", prefix} {
+		f, err := os.Open(filepath.Join(s.rangeDir, name))
+		if err == nil {
+			file = f
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, err
+		}
+	}
+	if file == nil {
+		return false, nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineSuffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if strings.EqualFold(lineSuffix, suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
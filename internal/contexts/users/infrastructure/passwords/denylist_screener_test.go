@@ -0,0 +1,79 @@
+package passwords
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestDenylistScreener_Bundled(t *testing.T) {
+	screener, err := NewDenylistScreener("")
+	require.NoError(t, err)
+
+	compromised, err := screener.IsCompromised(context.Background(), "Password1!")
+	assert.NoError(t, err)
+	assert.True(t, compromised)
+
+	compromised, err = screener.IsCompromised(context.Background(), "SecurePass123!")
+	assert.NoError(t, err)
+	assert.False(t, compromised)
+}
+
+func TestDenylistScreener_HashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "# lista del operador\n" + sha1Hex("Tr0ub4dor&3") + ":42\n" + strings.ToLower(sha1Hex("Corr3ct-Horse")) + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	screener, err := NewDenylistScreener(path)
+	require.NoError(t, err)
+
+	for _, password := range []string{"Tr0ub4dor&3", "Corr3ct-Horse"} {
+		compromised, err := screener.IsCompromised(context.Background(), password)
+		assert.NoError(t, err)
+		assert.True(t, compromised, password)
+	}
+
+	compromised, err := screener.IsCompromised(context.Background(), "SecurePass123!")
+	assert.NoError(t, err)
+	assert.False(t, compromised)
+}
+
+func TestDenylistScreener_InvalidHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not-a-hash\n"), 0o644))
+
+	_, err := NewDenylistScreener(path)
+
+	assert.ErrorContains(t, err, "breached.txt:1")
+}
+
+func TestDenylistScreener_RangeDir(t *testing.T) {
+	dir := t.TempDir()
+	hash := sha1Hex("Tr0ub4dor&3")
+	content := "0000000000000000000000000000000000A:1\n" + hash[5:] + ":42\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:5]+".txt"), []byte(content), 0o644))
+
+	screener, err := NewDenylistScreener(dir)
+	require.NoError(t, err)
+
+	compromised, err := screener.IsCompromised(context.Background(), "Tr0ub4dor&3")
+	assert.NoError(t, err)
+	assert.True(t, compromised)
+
+	// sin archivo para el prefijo
+	compromised, err = screener.IsCompromised(context.Background(), "SecurePass123!")
+	assert.NoError(t, err)
+	assert.False(t, compromised)
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
 )
 
 type PostgresIdempotencyRepository struct {
@@ -19,7 +21,9 @@ func (r *PostgresIdempotencyRepository) IsProcessed(ctx context.Context, key, te
 	query := `SELECT result FROM idempotency_keys WHERE key = $1 AND tenant_id = $2`
 
 	var result string
-	err := r.db.QueryRowContext(ctx, query, key, tenantID).Scan(&result)
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		return executor.QueryRowContext(ctx, query, key, tenantID).Scan(&result)
+	})
 	
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -38,6 +42,8 @@ func (r *PostgresIdempotencyRepository) Store(ctx context.Context, key, tenantID
 		ON CONFLICT (key, tenant_id) DO NOTHING
 	`
 
-	_, err := r.db.ExecContext(ctx, query, key, tenantID, result, time.Now())
-	return err
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		_, err := executor.ExecContext(ctx, query, key, tenantID, result, time.Now())
+		return err
+	})
 }
\ No newline at end of file
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresPasswordHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPasswordHistoryRepository(db *sql.DB) *PostgresPasswordHistoryRepository {
+	return &PostgresPasswordHistoryRepository{db: db}
+}
+
+func (r *PostgresPasswordHistoryRepository) Recent(ctx context.Context, userID, tenantID string, limit int) ([]vo.Password, error) {
+	query := `
+		SELECT password_hash FROM password_history
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query, userID, tenantID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	passwords := make([]vo.Password, 0, limit)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		passwords = append(passwords, vo.FromHash(hash))
+	}
+
+	return passwords, rows.Err()
+}
+
+func (r *PostgresPasswordHistoryRepository) Add(ctx context.Context, userID, tenantID string, password vo.Password, keep int) error {
+	executor := persistence.GetExecutor(ctx, r.db)
+
+	insert := `
+		INSERT INTO password_history (id, user_id, tenant_id, password_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if _, err := executor.ExecContext(ctx, insert, uuid.New().String(), userID, tenantID, password.Hash(), time.Now().UTC()); err != nil {
+		return err
+	}
+
+	prune := `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND tenant_id = $2 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1 AND tenant_id = $2
+			ORDER BY created_at DESC
+			LIMIT $3
+		)
+	`
+	_, err := executor.ExecContext(ctx, prune, userID, tenantID, keep)
+	return err
+}
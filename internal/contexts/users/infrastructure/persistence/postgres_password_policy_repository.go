@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresPasswordPolicyRepository lee password_policies; un tenant sin fila
+// usa vo.DefaultPasswordPolicy
+type PostgresPasswordPolicyRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresPasswordPolicyRepository(db *sql.DB) *PostgresPasswordPolicyRepository {
+	return &PostgresPasswordPolicyRepository{db: db}
+}
+
+func (r *PostgresPasswordPolicyRepository) FindByTenant(ctx context.Context, tenantID string) (vo.PasswordPolicy, error) {
+	query := `
+		SELECT min_length, max_length, require_character_classes, deny_personal_info, deny_breached, history_size
+		FROM password_policies
+		WHERE tenant_id = $1
+	`
+
+	var policy vo.PasswordPolicy
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tenantID).Scan(
+		&policy.MinLength,
+		&policy.MaxLength,
+		&policy.RequireCharacterClasses,
+		&policy.DenyPersonalInfo,
+		&policy.DenyBreached,
+		&policy.HistorySize,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return vo.DefaultPasswordPolicy(), nil
+		}
+		return vo.PasswordPolicy{}, err
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,75 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresRoleAssignmentRepository guarda en user_roles el rol de cada usuario;
+// un usuario sin fila tiene domain.DefaultRole
+type PostgresRoleAssignmentRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRoleAssignmentRepository(db *sql.DB) *PostgresRoleAssignmentRepository {
+	return &PostgresRoleAssignmentRepository{db: db}
+}
+
+func (r *PostgresRoleAssignmentRepository) RoleOf(ctx context.Context, userID, tenantID string) (string, error) {
+	query := `SELECT role FROM user_roles WHERE user_id = $1 AND tenant_id = $2`
+
+	var role string
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, userID, tenantID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.DefaultRole, nil
+		}
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *PostgresRoleAssignmentRepository) Assign(ctx context.Context, userID, tenantID, role, assignedBy string) error {
+	query := `
+		INSERT INTO user_roles (user_id, tenant_id, role, assigned_by, assigned_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			assigned_by = EXCLUDED.assigned_by,
+			assigned_at = EXCLUDED.assigned_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query, userID, tenantID, role, assignedBy, time.Now().UTC())
+	return err
+}
+
+// CountByRole cuenta los usuarios activos con el rol. Bloquea esas filas para
+// que dos bajas de admin en paralelo no dejen al tenant sin ninguno
+func (r *PostgresRoleAssignmentRepository) CountByRole(ctx context.Context, tenantID, role string) (int, error) {
+	query := `
+		SELECT ur.user_id
+		FROM user_roles ur
+		JOIN users_write u ON u.id = ur.user_id
+		WHERE ur.tenant_id = $1 AND ur.role = $2 AND u.status = 'active'
+		FOR UPDATE OF ur
+	`
+
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query, tenantID, role)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+
+	return count, rows.Err()
+}
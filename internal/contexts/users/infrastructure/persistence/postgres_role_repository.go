@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// PostgresRoleRepository guarda los roles custom de cada tenant en roles
+type PostgresRoleRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRoleRepository(db *sql.DB) *PostgresRoleRepository {
+	return &PostgresRoleRepository{db: db}
+}
+
+func (r *PostgresRoleRepository) Save(ctx context.Context, role *domain.Role) error {
+	query := `
+		INSERT INTO roles (tenant_id, name, description, permissions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id, name) DO UPDATE SET
+			description = EXCLUDED.description,
+			permissions = EXCLUDED.permissions,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query,
+		role.TenantID(),
+		role.Name(),
+		role.Description(),
+		pq.Array(role.Permissions()),
+		role.CreatedAt(),
+		role.UpdatedAt(),
+	)
+	return err
+}
+
+func (r *PostgresRoleRepository) FindByName(ctx context.Context, tenantID, name string) (*domain.Role, error) {
+	query := `
+		SELECT description, permissions, created_at, updated_at
+		FROM roles
+		WHERE tenant_id = $1 AND name = $2
+	`
+
+	var (
+		description          string
+		permissions          []string
+		createdAt, updatedAt time.Time
+	)
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tenantID, name).Scan(
+		&description, pq.Array(&permissions), &createdAt, &updatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	return domain.RebuildRole(tenantID, name, description, permissions, createdAt, updatedAt), nil
+}
+
+func (r *PostgresRoleRepository) FindAll(ctx context.Context, tenantID string) ([]*domain.Role, error) {
+	query := `
+		SELECT name, description, permissions, created_at, updated_at
+		FROM roles
+		WHERE tenant_id = $1
+		ORDER BY name
+	`
+
+	rows, err := persistence.GetExecutor(ctx, r.db).QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := make([]*domain.Role, 0)
+	for rows.Next() {
+		var (
+			name, description    string
+			permissions          []string
+			createdAt, updatedAt time.Time
+		)
+		if err := rows.Scan(&name, &description, pq.Array(&permissions), &createdAt, &updatedAt); err != nil {
+			return nil, err
+		}
+		roles = append(roles, domain.RebuildRole(tenantID, name, description, permissions, createdAt, updatedAt))
+	}
+
+	return roles, rows.Err()
+}
+
+func (r *PostgresRoleRepository) Delete(ctx context.Context, tenantID, name string) error {
+	query := `DELETE FROM roles WHERE tenant_id = $1 AND name = $2`
+
+	result, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query, tenantID, name)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrRoleNotFound
+	}
+
+	return nil
+}
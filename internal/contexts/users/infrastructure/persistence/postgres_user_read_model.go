@@ -4,8 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
 )
 
 
@@ -25,38 +29,145 @@ func (r *PostgresUserReadModel) Save(ctx context.Context, view *domain.UserView)
 			name = EXCLUDED.name,
 			email = EXCLUDED.email,
 			display_name = EXCLUDED.display_name
+		WHERE users_read.updated_at IS NULL
 	`
 
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
-		view.ID,
-		view.Name,
-		view.Email,
-		view.DisplayName,
-		view.TenantID,
-		view.CreatedAt,
-	)
-
-	return err
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		_, err := executor.ExecContext(
+			ctx,
+			query,
+			view.ID,
+			view.Name,
+			view.Email,
+			view.DisplayName,
+			view.TenantID,
+			view.CreatedAt,
+		)
+		return err
+	})
+}
+
+// UpdateProfile aplica un user.updated. Si la fila ya tiene un update mas nuevo
+// no se toca; si todavia no existe (llego antes que el user.created) devuelve
+// ErrUserNotFound para que el mensaje se reintente
+func (r *PostgresUserReadModel) UpdateProfile(ctx context.Context, id, tenantID, name string, displayName *string, updatedAt time.Time) error {
+	query := `
+		UPDATE users_read SET
+			name = $3,
+			display_name = $4,
+			updated_at = $5
+		WHERE id = $1 AND tenant_id = $2
+			AND (updated_at IS NULL OR updated_at < $5)
+	`
+
+	return r.update(ctx, id, tenantID, query, id, tenantID, name, displayName, updatedAt)
+}
+
+// UpdateEmail aplica un user.email_changed con el mismo control de orden que UpdateProfile
+func (r *PostgresUserReadModel) UpdateEmail(ctx context.Context, id, tenantID, email string, verifiedAt *time.Time, updatedAt time.Time) error {
+	query := `
+		UPDATE users_read SET
+			email = $3,
+			email_verified_at = $4,
+			updated_at = $5
+		WHERE id = $1 AND tenant_id = $2
+			AND (updated_at IS NULL OR updated_at < $5)
+	`
+
+	return r.update(ctx, id, tenantID, query, id, tenantID, email, verifiedAt, updatedAt)
+}
+
+// MarkEmailVerified aplica un user.email_verified con el mismo control de orden que UpdateProfile
+func (r *PostgresUserReadModel) MarkEmailVerified(ctx context.Context, id, tenantID string, verifiedAt, updatedAt time.Time) error {
+	query := `
+		UPDATE users_read SET
+			email_verified_at = $3,
+			updated_at = $4
+		WHERE id = $1 AND tenant_id = $2
+			AND (updated_at IS NULL OR updated_at < $4)
+	`
+
+	return r.update(ctx, id, tenantID, query, id, tenantID, verifiedAt, updatedAt)
+}
+
+// UpdateStatus aplica los eventos de ciclo de vida con el mismo control de orden que UpdateProfile
+func (r *PostgresUserReadModel) UpdateStatus(ctx context.Context, id, tenantID, status string, deletedAt *time.Time, updatedAt time.Time) error {
+	query := `
+		UPDATE users_read SET
+			status = $3,
+			deleted_at = $4,
+			updated_at = $5
+		WHERE id = $1 AND tenant_id = $2
+			AND (updated_at IS NULL OR updated_at < $5)
+	`
+
+	return r.update(ctx, id, tenantID, query, id, tenantID, status, deletedAt, updatedAt)
+}
+
+// Delete borra la proyeccion de un usuario purgado
+func (r *PostgresUserReadModel) Delete(ctx context.Context, id, tenantID string) error {
+	query := `DELETE FROM users_read WHERE id = $1 AND tenant_id = $2`
+
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		_, err := executor.ExecContext(ctx, query, id, tenantID)
+		return err
+	})
+}
+
+// update corre el UPDATE condicionado y, si no toco ninguna fila, distingue
+// entre un evento viejo (nil) y una fila que todavia no existe
+func (r *PostgresUserReadModel) update(ctx context.Context, id, tenantID, query string, args ...interface{}) error {
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		result, err := executor.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected > 0 {
+			return nil
+		}
+
+		return ensureExists(ctx, executor, id, tenantID)
+	})
+}
+
+func ensureExists(ctx context.Context, executor persistence.Executor, id, tenantID string) error {
+	query := `SELECT EXISTS(SELECT 1 FROM users_read WHERE id = $1 AND tenant_id = $2)`
+
+	var exists bool
+	if err := executor.QueryRowContext(ctx, query, id, tenantID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return nil
 }
 
 func (r *PostgresUserReadModel) FindByID(ctx context.Context, id, tenantID string) (*domain.UserView, error) {
 	query := `
-		SELECT id, name, email, display_name, tenant_id, created_at
+		SELECT id, name, email, display_name, tenant_id, status, email_verified_at IS NOT NULL, created_at
 		FROM users_read
-		WHERE id = $1 AND tenant_id = $2
+		WHERE id = $1 AND tenant_id = $2 AND status <> 'deleted'
 	`
 
 	var view domain.UserView
-	err := r.db.QueryRowContext(ctx, query, id, tenantID).Scan(
-		&view.ID,
-		&view.Name,
-		&view.Email,
-		&view.DisplayName,
-		&view.TenantID,
-		&view.CreatedAt,
-	)
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		return executor.QueryRowContext(ctx, query, id, tenantID).Scan(
+			&view.ID,
+			&view.Name,
+			&view.Email,
+			&view.DisplayName,
+			&view.TenantID,
+			&view.Status,
+			&view.EmailVerified,
+			&view.CreatedAt,
+		)
+	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -68,35 +179,141 @@ func (r *PostgresUserReadModel) FindByID(ctx context.Context, id, tenantID strin
 	return &view, nil
 }
 
-func (r *PostgresUserReadModel) FindAll(ctx context.Context, tenantID string) ([]domain.UserView, error) {
-	query := `
-		SELECT id, name, email, display_name, tenant_id, created_at
-		FROM users_read
-		WHERE tenant_id = $1
-		ORDER BY created_at DESC
-	`
+func (r *PostgresUserReadModel) FindAll(ctx context.Context, criteria domain.UserListCriteria) ([]domain.UserView, error) {
+	// la columna de orden sale de una lista cerrada, nunca del request
+	sortColumn := "created_at"
+	if criteria.SortField == domain.SortByName {
+		sortColumn = "name"
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
-	if err != nil {
-		return nil, err
+	direction, comparison := "ASC", ">"
+	if criteria.Descending {
+		direction, comparison = "DESC", "<"
 	}
-	defer rows.Close()
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{criteria.TenantID}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if criteria.Status != "" {
+		addCondition("status = $%d", criteria.Status)
+	} else {
+		addCondition("status <> $%d", string(domain.StatusDeleted))
+	}
+	if criteria.EmailPrefix != "" {
+		addCondition("email LIKE $%d", escapeLike(strings.ToLower(criteria.EmailPrefix))+"%")
+	}
+	if criteria.NamePrefix != "" {
+		addCondition("lower(name) LIKE $%d", escapeLike(strings.ToLower(criteria.NamePrefix))+"%")
+	}
+	if criteria.After != nil {
+		args = append(args, criteria.After.SortValue, criteria.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d::uuid)", sortColumn, comparison, len(args)-1, len(args)))
+	}
+
+	args = append(args, criteria.Limit)
+	query := fmt.Sprintf(`
+		SELECT id, name, email, display_name, tenant_id, status, email_verified_at IS NOT NULL, created_at
+		FROM users_read
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), sortColumn, direction, direction, len(args))
 
 	var views []domain.UserView
-	for rows.Next() {
-		var view domain.UserView
-		if err := rows.Scan(
-			&view.ID,
-			&view.Name,
-			&view.Email,
-			&view.DisplayName,
-			&view.TenantID,
-			&view.CreatedAt,
-		); err != nil {
-			return nil, err
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		rows, err := executor.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
 		}
-		views = append(views, view)
-	}
+		defer rows.Close()
 
-	return views, rows.Err()
-}
\ No newline at end of file
+		for rows.Next() {
+			var view domain.UserView
+			if err := rows.Scan(
+				&view.ID,
+				&view.Name,
+				&view.Email,
+				&view.DisplayName,
+				&view.TenantID,
+				&view.Status,
+				&view.EmailVerified,
+				&view.CreatedAt,
+			); err != nil {
+				return err
+			}
+			views = append(views, view)
+		}
+		return rows.Err()
+	})
+
+	return views, err
+}
+
+// Search combina full-text (prefijos de cada termino) con similitud de
+// trigramas para tolerar typos. El filtro de tenant va siempre primero
+func (r *PostgresUserReadModel) Search(ctx context.Context, tenantID, text, tsQuery string, limit int) ([]domain.UserSearchHit, error) {
+	query := `
+		SELECT id, name, email, display_name, tenant_id, status, email_verified_at IS NOT NULL, created_at,
+			rank, name_sim, display_name_sim, email_sim
+		FROM (
+			SELECT id, name, email, display_name, tenant_id, status, email_verified_at, created_at,
+				CASE WHEN $3 <> '' THEN ts_rank(search_vector, to_tsquery('simple', $3)) ELSE 0 END AS rank,
+				similarity(name, $2) AS name_sim,
+				similarity(coalesce(display_name, ''), $2) AS display_name_sim,
+				similarity(email, $2) AS email_sim
+			FROM users_read
+			WHERE tenant_id = $1 AND status <> 'deleted'
+				AND (
+					($3 <> '' AND search_vector @@ to_tsquery('simple', $3))
+					OR name % $2 OR display_name % $2 OR email % $2
+					OR name ILIKE $4 OR display_name ILIKE $4 OR email ILIKE $4
+				)
+		) matches
+		ORDER BY rank + GREATEST(name_sim, display_name_sim, email_sim) DESC, id
+		LIMIT $5
+	`
+
+	contains := "%" + escapeLike(text) + "%"
+
+	var hits []domain.UserSearchHit
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		rows, err := executor.QueryContext(ctx, query, tenantID, text, tsQuery, contains, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var hit domain.UserSearchHit
+			if err := rows.Scan(
+				&hit.ID,
+				&hit.Name,
+				&hit.Email,
+				&hit.DisplayName,
+				&hit.TenantID,
+				&hit.Status,
+				&hit.EmailVerified,
+				&hit.CreatedAt,
+				&hit.Rank,
+				&hit.NameSimilarity,
+				&hit.DisplayNameSimilarity,
+				&hit.EmailSimilarity,
+			); err != nil {
+				return err
+			}
+			hits = append(hits, hit)
+		}
+		return rows.Err()
+	})
+
+	return hits, err
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
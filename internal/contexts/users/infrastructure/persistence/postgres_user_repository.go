@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
 	vo "backend-challenge-guinea/internal/shared/domain/value_objects"
 )
 
+const userColumns = `id, name, email, password_hash, display_name, tenant_id, status, deleted_at, email_verified_at, created_at, updated_at`
+
 type PostgresUserRepository struct {
 	db *sql.DB
 }
@@ -20,93 +24,188 @@ func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
 
 func (r *PostgresUserRepository) Save(ctx context.Context, user *domain.User) error {
 	query := `
-		INSERT INTO users_write (id, name, email, password_hash, display_name, tenant_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO users_write (id, name, email, password_hash, display_name, tenant_id, status, deleted_at, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
+			email = EXCLUDED.email,
+			password_hash = EXCLUDED.password_hash,
+			display_name = EXCLUDED.display_name,
+			status = EXCLUDED.status,
+			deleted_at = EXCLUDED.deleted_at,
+			email_verified_at = EXCLUDED.email_verified_at,
 			updated_at = EXCLUDED.updated_at
 	`
 
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
-		user.ID(),
-		user.Name(),
-		user.Email().Value(),
-		user.Password().Hash(),
-		user.DisplayName(),
-		user.TenantID(),
-		user.CreatedAt(),
-		user.UpdatedAt(),
-	)
-
-	return err
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		_, err := executor.ExecContext(
+			ctx,
+			query,
+			user.ID(),
+			user.Name(),
+			user.Email().Value(),
+			user.Password().Hash(),
+			user.DisplayName(),
+			user.TenantID(),
+			string(user.Status()),
+			user.DeletedAt(),
+			user.EmailVerifiedAt(),
+			user.CreatedAt(),
+			user.UpdatedAt(),
+		)
+		return err
+	})
 }
 
 func (r *PostgresUserRepository) FindByID(ctx context.Context, id, tenantID string) (*domain.User, error) {
 	query := `
-		SELECT id, name, email, password_hash, display_name, tenant_id, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users_write
 		WHERE id = $1 AND tenant_id = $2
 	`
 
-	var (
-		userID       string
-		name         string
-		email        string
-		passwordHash string
-		displayName  *string
-		tenantId     string
-		createdAt    time.Time
-		updatedAt    time.Time
-	)
+	return r.findOne(ctx, query, id, tenantID)
+}
 
-	err := r.db.QueryRowContext(ctx, query, id, tenantID).Scan(
-		&userID, &name, &email, &passwordHash, &displayName, &tenantId, &createdAt, &updatedAt,
-	)
+func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email, tenantID string) (*domain.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users_write
+		WHERE email = $1 AND tenant_id = $2
+	`
 
+	return r.findOne(ctx, query, email, tenantID)
+}
+
+// ExistsByEmail cuenta tambien a los usuarios borrados: el email queda
+// reservado hasta que se purgan, asi se pueden restaurar
+func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email, tenantID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM users_write WHERE email = $1 AND tenant_id = $2)`
+
+	var exists bool
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		return executor.QueryRowContext(ctx, query, email, tenantID).Scan(&exists)
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, domain.ErrUserNotFound
-		}
-		return nil, err
+		return false, err
 	}
 
-	emailVO, _ := vo.NewEmail(email)
-	passwordVO := vo.FromHash(passwordHash)
+	return exists, nil
+}
 
-	return domain.Reconstitute(
-		userID,
-		name,
-		emailVO,
-		passwordVO,
-		tenantId,
-		displayName,
-		createdAt,
-		updatedAt,
-	), nil
+// UpdatePasswordHash no toca updated_at: es el mismo password con otro algoritmo
+func (r *PostgresUserRepository) UpdatePasswordHash(ctx context.Context, id, tenantID, currentHash, newHash string) error {
+	query := `
+		UPDATE users_write SET password_hash = $4
+		WHERE id = $1 AND tenant_id = $2 AND password_hash = $3
+	`
+
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		_, err := executor.ExecContext(ctx, query, id, tenantID, currentHash, newHash)
+		return err
+	})
 }
 
-func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email, tenantID string) (*domain.User, error) {
+// FindDeletedBefore devuelve usuarios borrados antes de cutoff, de todos los
+// tenants: el context tiene que venir con persistence.WithAllTenants
+func (r *PostgresUserRepository) FindDeletedBefore(ctx context.Context, cutoff time.Time, limit int) ([]*domain.User, error) {
 	query := `
-		SELECT id, name, email, password_hash, display_name, tenant_id, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users_write
-		WHERE email = $1 AND tenant_id = $2
+		WHERE status = $1 AND deleted_at < $2
+		ORDER BY deleted_at
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
 	`
 
+	var users []*domain.User
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		rows, err := executor.QueryContext(ctx, query, string(domain.StatusDeleted), cutoff, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			user, err := scanUser(rows)
+			if err != nil {
+				return err
+			}
+			users = append(users, user)
+		}
+		return rows.Err()
+	})
+
+	return users, err
+}
+
+// userDataTables tienen filas del usuario sin FK a users_write (password_history
+// y user_roles se borran en cascada). Una tabla nueva con user_id va aca
+var userDataTables = []string{
+	"refresh_tokens",
+	"sessions",
+	"mfa_challenges",
+	"mfa_enrollments",
+	"user_tokens",
+	"notifications",
+}
+
+// Delete borra al usuario y todo lo suyo: sesiones, MFA, tokens pendientes,
+// intentos de login y notificaciones. Lo usa la purga
+func (r *PostgresUserRepository) Delete(ctx context.Context, id, tenantID string) error {
+	return persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		// los intentos de login van por email, se borran antes que el usuario
+		loginAttempts := `
+			DELETE FROM login_attempts
+			WHERE tenant_id = $2 AND subject = (SELECT 'email:' || lower(email) FROM users_write WHERE id = $1 AND tenant_id = $2)
+		`
+		if _, err := executor.ExecContext(ctx, loginAttempts, id, tenantID); err != nil {
+			return err
+		}
+
+		for _, table := range userDataTables {
+			query := fmt.Sprintf(`DELETE FROM %s WHERE user_id = $1 AND tenant_id = $2`, table)
+			if _, err := executor.ExecContext(ctx, query, id, tenantID); err != nil {
+				return err
+			}
+		}
+
+		_, err := executor.ExecContext(ctx, `DELETE FROM users_write WHERE id = $1 AND tenant_id = $2`, id, tenantID)
+		return err
+	})
+}
+
+func (r *PostgresUserRepository) findOne(ctx context.Context, query string, args ...interface{}) (*domain.User, error) {
+	var user *domain.User
+	err := persistence.WithinTenantScope(ctx, r.db, func(executor persistence.Executor) error {
+		var err error
+		user, err = scanUser(executor.QueryRowContext(ctx, query, args...))
+		return err
+	})
+	return user, err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (*domain.User, error) {
 	var (
 		userID       string
 		name         string
-		emailStr     string
+		email        string
 		passwordHash string
 		displayName  *string
 		tenantId     string
+		status       string
+		deletedAt    *time.Time
+		verifiedAt   *time.Time
 		createdAt    time.Time
 		updatedAt    time.Time
 	)
 
-	err := r.db.QueryRowContext(ctx, query, email, tenantID).Scan(
-		&userID, &name, &emailStr, &passwordHash, &displayName, &tenantId, &createdAt, &updatedAt,
+	err := row.Scan(
+		&userID, &name, &email, &passwordHash, &displayName, &tenantId, &status, &deletedAt, &verifiedAt, &createdAt, &updatedAt,
 	)
 
 	if err != nil {
@@ -116,7 +215,7 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email, tenantI
 		return nil, err
 	}
 
-	emailVO, _ := vo.NewEmail(emailStr)
+	emailVO, _ := vo.NewEmail(email)
 	passwordVO := vo.FromHash(passwordHash)
 
 	return domain.Reconstitute(
@@ -126,20 +225,10 @@ func (r *PostgresUserRepository) FindByEmail(ctx context.Context, email, tenantI
 		passwordVO,
 		tenantId,
 		displayName,
+		domain.UserStatus(status),
+		deletedAt,
+		verifiedAt,
 		createdAt,
 		updatedAt,
 	), nil
 }
-
-
-func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email, tenantID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users_write WHERE email = $1 AND tenant_id = $2)`
-
-	var exists bool
-	err := r.db.QueryRowContext(ctx, query, email, tenantID).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-
-	return exists, nil
-}
\ No newline at end of file
@@ -0,0 +1,91 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder es un driver de database/sql que no habla con Postgres: anota cada
+// sentencia, para ver que borra la purga
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{rec: r}, nil
+}
+func (r *recorder) Driver() driver.Driver                 { return r }
+func (r *recorder) Open(name string) (driver.Conn, error) { return &recordingConn{rec: r}, nil }
+
+func (r *recorder) record(statement string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, strings.Join(strings.Fields(statement), " "))
+}
+
+type recordingConn struct {
+	rec *recorder
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c *recordingConn) Close() error { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.rec.record("BEGIN")
+	return recordingTx{rec: c.rec}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.rec.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.rec.record(query)
+	return emptyRows{}, nil
+}
+
+type recordingTx struct {
+	rec *recorder
+}
+
+func (t recordingTx) Commit() error   { t.rec.record("COMMIT"); return nil }
+func (t recordingTx) Rollback() error { t.rec.record("ROLLBACK"); return nil }
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func TestPostgresUserRepository_DeleteRemovesUserData(t *testing.T) {
+	rec := &recorder{}
+	repo := NewPostgresUserRepository(sql.OpenDB(rec))
+
+	require.NoError(t, repo.Delete(context.Background(), "user-1", "tenant-1"))
+
+	// todo en una transaccion y el usuario al final, despues de sus datos
+	assert.Equal(t, []string{
+		"BEGIN",
+		"DELETE FROM login_attempts WHERE tenant_id = $2 AND subject = (SELECT 'email:' || lower(email) FROM users_write WHERE id = $1 AND tenant_id = $2)",
+		"DELETE FROM refresh_tokens WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM sessions WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM mfa_challenges WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM mfa_enrollments WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM user_tokens WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM notifications WHERE user_id = $1 AND tenant_id = $2",
+		"DELETE FROM users_write WHERE id = $1 AND tenant_id = $2",
+		"COMMIT",
+	}, rec.statements)
+}
@@ -0,0 +1,108 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/contexts/users/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+type PostgresUserTokenRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresUserTokenRepository(db *sql.DB) *PostgresUserTokenRepository {
+	return &PostgresUserTokenRepository{db: db}
+}
+
+func (r *PostgresUserTokenRepository) Save(ctx context.Context, token *domain.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (id, user_id, tenant_id, purpose, token_hash, new_email, created_at, expires_at, used_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8, $9)
+		ON CONFLICT (id) DO UPDATE SET
+			used_at = EXCLUDED.used_at
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(
+		ctx,
+		query,
+		token.ID(),
+		token.UserID(),
+		token.TenantID(),
+		string(token.Purpose()),
+		token.TokenHash(),
+		token.NewEmail(),
+		token.CreatedAt(),
+		token.ExpiresAt(),
+		token.UsedAt(),
+	)
+
+	return err
+}
+
+// FindByHash bloquea la fila para que el token no se pueda usar dos veces en paralelo
+func (r *PostgresUserTokenRepository) FindByHash(ctx context.Context, tokenHash string, purpose domain.TokenPurpose) (*domain.UserToken, error) {
+	query := `
+		SELECT id, user_id, tenant_id, purpose, token_hash, COALESCE(new_email, ''), expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2
+		FOR UPDATE
+	`
+
+	var (
+		id        string
+		userID    string
+		tenantID  string
+		purposeDB string
+		hash      string
+		newEmail  string
+		expiresAt time.Time
+		usedAt    *time.Time
+		createdAt time.Time
+	)
+
+	err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, tokenHash, string(purpose)).Scan(
+		&id, &userID, &tenantID, &purposeDB, &hash, &newEmail, &expiresAt, &usedAt, &createdAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return domain.ReconstituteUserToken(id, userID, tenantID, domain.TokenPurpose(purposeDB), hash, newEmail, expiresAt.UTC(), usedAt, createdAt.UTC()), nil
+}
+
+func (r *PostgresUserTokenRepository) LastIssuedAt(ctx context.Context, userID, tenantID string, purpose domain.TokenPurpose) (*time.Time, error) {
+	query := `
+		SELECT MAX(created_at) FROM user_tokens
+		WHERE user_id = $1 AND tenant_id = $2 AND purpose = $3
+	`
+
+	var issuedAt *time.Time
+	if err := persistence.GetExecutor(ctx, r.db).QueryRowContext(ctx, query, userID, tenantID, string(purpose)).Scan(&issuedAt); err != nil {
+		return nil, err
+	}
+	if issuedAt != nil {
+		utc := issuedAt.UTC()
+		issuedAt = &utc
+	}
+
+	return issuedAt, nil
+}
+
+// InvalidateForUser marca como usados los tokens pendientes, al pedir uno nuevo el anterior deja de servir
+func (r *PostgresUserTokenRepository) InvalidateForUser(ctx context.Context, userID, tenantID string, purpose domain.TokenPurpose) error {
+	query := `
+		UPDATE user_tokens SET used_at = $4
+		WHERE user_id = $1 AND tenant_id = $2 AND purpose = $3 AND used_at IS NULL
+	`
+
+	_, err := persistence.GetExecutor(ctx, r.db).ExecContext(ctx, query, userID, tenantID, string(purpose), time.Now().UTC())
+	return err
+}
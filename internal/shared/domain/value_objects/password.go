@@ -2,31 +2,31 @@ package valueobjects
 
 import (
 	"errors"
-	"unicode"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 const (
 	MinPasswordLength = 8
-	BcryptCost       = 10 
+	// maximo por defecto: bcrypt no acepta mas de 72 bytes
+	MaxPasswordLength = 72
+	BcryptCost        = 10
 )
 
+// ErrWeakPassword envuelve todos los errores de validacion de la password
+var ErrWeakPassword = errors.New("weak password")
+
+// Password guarda solo el hash. El algoritmo se reconoce por el prefijo
+// ($2a$/$2b$/$2y$ bcrypt, $argon2id$ argon2id), asi conviven hashes viejos y nuevos
 type Password struct {
 	hashedValue string
 }
 
+// NewPassword valida con la politica y el hasher por defecto; para la del
+// tenant y los parametros de la config usar PasswordPolicy.NewPassword
 func NewPassword(plainPassword string) (Password, error) {
-	if err := validatePassword(plainPassword); err != nil {
-		return Password{}, err
-	}
-	
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), BcryptCost)
-	if err != nil {
-		return Password{}, err
-	}
-	
-	return Password{hashedValue: string(hashedBytes)}, nil
+	return DefaultPasswordPolicy().NewPassword(DefaultPasswordHasher(), plainPassword)
 }
 
 func FromHash(hash string) Password {
@@ -37,48 +37,26 @@ func (p Password) Hash() string {
 	return p.hashedValue
 }
 
-func (p Password) Compare(plainPassword string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(p.hashedValue), []byte(plainPassword))
-	return err == nil
+// Algorithm devuelve el algoritmo del hash, vacio si no se reconoce
+func (p Password) Algorithm() string {
+	switch {
+	case strings.HasPrefix(p.hashedValue, "$argon2id$"):
+		return AlgorithmArgon2id
+	case strings.HasPrefix(p.hashedValue, "$2a$"), strings.HasPrefix(p.hashedValue, "$2b$"), strings.HasPrefix(p.hashedValue, "$2y$"):
+		return AlgorithmBcrypt
+	default:
+		return ""
+	}
 }
 
-func validatePassword(password string) error {
-	if len(password) < MinPasswordLength {
-		return errors.New("password must be at least 8 characters long")
-	}
-	
-	var (
-		hasUpper   bool
-		hasLower   bool
-		hasNumber  bool
-		hasSpecial bool
-	)
-	
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-	
-	if !hasUpper {
-		return errors.New("password must contain at least one uppercase letter")
-	}
-	if !hasLower {
-		return errors.New("password must contain at least one lowercase letter")
-	}
-	if !hasNumber {
-		return errors.New("password must contain at least one number")
-	}
-	if !hasSpecial {
-		return errors.New("password must contain at least one special character")
+func (p Password) Compare(plainPassword string) bool {
+	switch p.Algorithm() {
+	case AlgorithmArgon2id:
+		return compareArgon2id(p.hashedValue, plainPassword)
+	case AlgorithmBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(p.hashedValue), []byte(plainPassword))
+		return err == nil
+	default:
+		return false
 	}
-	
-	return nil
-}
\ No newline at end of file
+}
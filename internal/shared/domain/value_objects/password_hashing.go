@@ -0,0 +1,183 @@
+package valueobjects
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+var errInvalidArgon2Hash = errors.New("invalid argon2id hash")
+
+// Argon2Params son los parametros de argon2id. Memory va en KiB
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// HashingParams es el algoritmo con el que se hashean las passwords nuevas
+// y contra el que se comparan las existentes para decidir si re-hashear
+type HashingParams struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+// DefaultHashingParams usa argon2id con los parametros minimos que recomienda OWASP
+func DefaultHashingParams() HashingParams {
+	return HashingParams{
+		Algorithm:  AlgorithmArgon2id,
+		BcryptCost: BcryptCost,
+		Argon2: Argon2Params{
+			Memory:      19 * 1024,
+			Iterations:  2,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	}
+}
+
+// PasswordHasher hashea con los parametros de la config y decide si un hash
+// existente hay que rehacerlo. Se arma una vez al arrancar y se inyecta
+type PasswordHasher struct {
+	params HashingParams
+}
+
+func NewPasswordHasher(params HashingParams) (*PasswordHasher, error) {
+	switch params.Algorithm {
+	case AlgorithmBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case AlgorithmArgon2id:
+		a := params.Argon2
+		if a.Memory == 0 || a.Iterations == 0 || a.Parallelism == 0 || a.SaltLength == 0 || a.KeyLength == 0 {
+			return nil, errors.New("argon2id parameters must be greater than zero")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", params.Algorithm)
+	}
+
+	return &PasswordHasher{params: params}, nil
+}
+
+// DefaultPasswordHasher usa DefaultHashingParams, que siempre son validos
+func DefaultPasswordHasher() *PasswordHasher {
+	return &PasswordHasher{params: DefaultHashingParams()}
+}
+
+// Hash hashea sin validar la politica; para passwords nuevas usar
+// PasswordPolicy.NewPassword
+func (h *PasswordHasher) Hash(plainPassword string) (Password, error) {
+	if h.params.Algorithm == AlgorithmArgon2id {
+		hash, err := hashArgon2id(plainPassword, h.params.Argon2)
+		if err != nil {
+			return Password{}, err
+		}
+		return Password{hashedValue: hash}, nil
+	}
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(plainPassword), h.params.BcryptCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		// menos de 72 caracteres pero mas de 72 bytes (acentos, emojis)
+		return Password{}, &PasswordPolicyError{Violations: []PasswordViolation{{
+			Code:    ViolationTooLong,
+			Message: fmt.Sprintf("password must be at most %d bytes long", MaxPasswordLength),
+		}}}
+	}
+	if err != nil {
+		return Password{}, err
+	}
+
+	return Password{hashedValue: string(hashedBytes)}, nil
+}
+
+// NeedsRehash indica si el hash se hizo con otro algoritmo o con parametros
+// distintos a los configurados; se re-hashea cuando el usuario se loguea
+func (h *PasswordHasher) NeedsRehash(p Password) bool {
+	if p.Algorithm() != h.params.Algorithm {
+		return true
+	}
+
+	if h.params.Algorithm == AlgorithmArgon2id {
+		stored, _, _, err := decodeArgon2id(p.hashedValue)
+		return err != nil || stored != h.params.Argon2
+	}
+
+	cost, err := bcrypt.Cost([]byte(p.hashedValue))
+	return err != nil || cost != h.params.BcryptCost
+}
+
+// hashArgon2id arma el formato PHC: $argon2id$v=19$m=...,t=...,p=...$salt$hash
+func hashArgon2id(plainPassword string, params Argon2Params) (string, error) {
+	salt := make([]byte, params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(plainPassword), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		params.Memory,
+		params.Iterations,
+		params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func compareArgon2id(hash, plainPassword string) bool {
+	params, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return false
+	}
+
+	candidate := argon2.IDKey([]byte(plainPassword), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, candidate) == 1
+}
+
+func decodeArgon2id(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != AlgorithmArgon2id {
+		return Argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	var params Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2Params{}, nil, nil, errInvalidArgon2Hash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
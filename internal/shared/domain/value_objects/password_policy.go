@@ -0,0 +1,194 @@
+package valueobjects
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// codigos de PasswordViolation, estables para que el cliente pueda traducirlos
+const (
+	ViolationTooShort             = "too_short"
+	ViolationTooLong              = "too_long"
+	ViolationMissingUppercase     = "missing_uppercase"
+	ViolationMissingLowercase     = "missing_lowercase"
+	ViolationMissingNumber        = "missing_number"
+	ViolationMissingSpecial       = "missing_special"
+	ViolationContainsPersonalInfo = "contains_personal_info"
+	ViolationBreached             = "breached"
+	ViolationReused               = "reused"
+)
+
+// partes de nombre o email mas cortas que esto no se buscan en la password
+const minPersonalInfoLength = 3
+
+// MaxPasswordHistorySize es el tope de HistorySize (CHECK en password_policies):
+// cada password del historial es una comparacion argon2id/bcrypt por cambio
+const MaxPasswordHistorySize = 24
+
+// PasswordPolicy son las reglas de password de un tenant. Con
+// RequireCharacterClasses en false (modo passphrase) solo se mira el largo.
+// DenyBreached y HistorySize no se chequean aca: necesitan la lista de
+// passwords filtradas y el historial del usuario, que consulta quien usa la politica
+//
+// MaxLength 0 no pone un maximo propio, pero con bcrypt el hash igual rechaza
+// las passwords de mas de MaxPasswordLength bytes (violacion too_long); con
+// argon2id no hay limite
+type PasswordPolicy struct {
+	MinLength               int
+	MaxLength               int // 0 = sin maximo propio (ver arriba)
+	RequireCharacterClasses bool
+	DenyPersonalInfo        bool
+	DenyBreached            bool
+	HistorySize             int // cuantas passwords recientes (incluida la actual) no se pueden repetir; 0 = sin control
+}
+
+// DefaultPasswordPolicy es la politica de los tenants que no configuraron la suya
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:               MinPasswordLength,
+		MaxLength:               MaxPasswordLength,
+		RequireCharacterClasses: true,
+		DenyBreached:            true,
+	}
+}
+
+type PasswordViolation struct {
+	Code    string
+	Message string
+}
+
+// BreachedPasswordViolation es la violacion de una password que aparece en
+// una lista de passwords comunes o filtradas
+func BreachedPasswordViolation() PasswordViolation {
+	return PasswordViolation{
+		Code:    ViolationBreached,
+		Message: "password is too common or has appeared in a data breach",
+	}
+}
+
+func ReusedPasswordViolation(historySize int) PasswordViolation {
+	return PasswordViolation{
+		Code:    ViolationReused,
+		Message: fmt.Sprintf("password must not match any of your last %d passwords", historySize),
+	}
+}
+
+// PasswordPolicyError lista todas las reglas que no se cumplen, no solo la primera
+type PasswordPolicyError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrWeakPassword, strings.Join(messages, "; "))
+}
+
+func (e *PasswordPolicyError) Is(target error) bool {
+	return target == ErrWeakPassword
+}
+
+// NewPassword valida contra la politica y hashea. personalInfo son los datos
+// del usuario (nombre, email) que no pueden aparecer en la password
+func (p PasswordPolicy) NewPassword(hasher *PasswordHasher, plainPassword string, personalInfo ...string) (Password, error) {
+	if violations := p.Validate(plainPassword, personalInfo...); len(violations) > 0 {
+		return Password{}, &PasswordPolicyError{Violations: violations}
+	}
+	return hasher.Hash(plainPassword)
+}
+
+func (p PasswordPolicy) Validate(password string, personalInfo ...string) []PasswordViolation {
+	var violations []PasswordViolation
+
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, PasswordViolation{
+			Code:    ViolationTooShort,
+			Message: fmt.Sprintf("password must be at least %d characters long", p.MinLength),
+		})
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, PasswordViolation{
+			Code:    ViolationTooLong,
+			Message: fmt.Sprintf("password must be at most %d characters long", p.MaxLength),
+		})
+	}
+
+	if p.RequireCharacterClasses {
+		violations = append(violations, characterClassViolations(password)...)
+	}
+
+	if p.DenyPersonalInfo && containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, PasswordViolation{
+			Code:    ViolationContainsPersonalInfo,
+			Message: "password must not contain your name or email",
+		})
+	}
+
+	return violations
+}
+
+func characterClassViolations(password string) []PasswordViolation {
+	var (
+		hasUpper   bool
+		hasLower   bool
+		hasNumber  bool
+		hasSpecial bool
+	)
+
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSpecial = true
+		}
+	}
+
+	var violations []PasswordViolation
+	if !hasUpper {
+		violations = append(violations, PasswordViolation{ViolationMissingUppercase, "password must contain at least one uppercase letter"})
+	}
+	if !hasLower {
+		violations = append(violations, PasswordViolation{ViolationMissingLowercase, "password must contain at least one lowercase letter"})
+	}
+	if !hasNumber {
+		violations = append(violations, PasswordViolation{ViolationMissingNumber, "password must contain at least one number"})
+	}
+	if !hasSpecial {
+		violations = append(violations, PasswordViolation{ViolationMissingSpecial, "password must contain at least one special character"})
+	}
+	return violations
+}
+
+// containsPersonalInfo busca cada palabra del nombre y la parte local del
+// email (partida en . _ - +), sin distinguir mayusculas
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lowered := strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(info)
+		if at := strings.Index(info, "@"); at >= 0 {
+			info = info[:at]
+		}
+
+		parts := strings.FieldsFunc(info, func(r rune) bool {
+			return unicode.IsSpace(r) || strings.ContainsRune("._-+", r)
+		})
+		for _, part := range parts {
+			if utf8.RuneCountInString(part) >= minPersonalInfoLength && strings.Contains(lowered, part) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -1,9 +1,11 @@
 package valueobjects
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewPassword_ValidPassword(t *testing.T) {
@@ -70,4 +72,140 @@ func TestFromHash(t *testing.T) {
 	reconstructed := FromHash(hash)
 	
 	assert.True(t, reconstructed.Compare("SecurePass123!"))
-}
\ No newline at end of file
+}
+func TestNewPassword_ReportsAllViolations(t *testing.T) {
+	_, err := NewPassword("short")
+
+	assert.ErrorIs(t, err, ErrWeakPassword)
+
+	var policyErr *PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+
+	codes := make([]string, 0, len(policyErr.Violations))
+	for _, violation := range policyErr.Violations {
+		codes = append(codes, violation.Code)
+	}
+	assert.Equal(t, []string{ViolationTooShort, ViolationMissingUppercase, ViolationMissingNumber, ViolationMissingSpecial}, codes)
+}
+
+func TestPasswordPolicy_Passphrase(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 15, MaxLength: 64}
+
+	assert.Empty(t, policy.Validate("correct horse battery staple"))
+
+	violations := policy.Validate("two words")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, ViolationTooShort, violations[0].Code)
+
+	violations = policy.Validate(string(make([]byte, 65)))
+	assert.Len(t, violations, 1)
+	assert.Equal(t, ViolationTooLong, violations[0].Code)
+}
+
+func TestPasswordPolicy_DenyPersonalInfo(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8, DenyPersonalInfo: true}
+
+	violations := policy.Validate("Johnny2024!", "John Doe", "j.doe@example.com")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, ViolationContainsPersonalInfo, violations[0].Code)
+
+	assert.Len(t, policy.Validate("xxDOExx2024", "John Doe", "jd@example.com"), 1)
+	// las partes cortas (menos de 3 letras) no cuentan
+	assert.Empty(t, policy.Validate("jdjdjdjdjd", "J D", "jd@example.com"))
+	assert.Empty(t, policy.Validate("unrelated-passphrase", "John Doe", "j.doe@example.com"))
+}
+
+func TestNewPassword_TooLongInBytes(t *testing.T) {
+	hasher := newHasher(t, bcryptParams())
+
+	// 40 caracteres pero 80 bytes: bcrypt no lo acepta
+	_, err := DefaultPasswordPolicy().NewPassword(hasher, "Aa1!"+strings.Repeat("ñ", 36))
+
+	var policyErr *PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, ViolationTooLong, policyErr.Violations[0].Code)
+}
+
+func TestPasswordPolicy_NoMaxLength(t *testing.T) {
+	policy := PasswordPolicy{MinLength: 8}
+	long := strings.Repeat("correct horse ", 8)
+
+	// sin maximo propio argon2id la acepta, bcrypt no pasa de 72 bytes
+	_, err := policy.NewPassword(DefaultPasswordHasher(), long)
+	assert.NoError(t, err)
+
+	_, err = policy.NewPassword(newHasher(t, bcryptParams()), long)
+	var policyErr *PasswordPolicyError
+	assert.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, ViolationTooLong, policyErr.Violations[0].Code)
+}
+
+func newHasher(t *testing.T, params HashingParams) *PasswordHasher {
+	hasher, err := NewPasswordHasher(params)
+	require.NoError(t, err)
+	return hasher
+}
+
+func bcryptParams() HashingParams {
+	params := DefaultHashingParams()
+	params.Algorithm = AlgorithmBcrypt
+	return params
+}
+
+func TestNewPassword_Argon2id(t *testing.T) {
+	password, err := NewPassword("SecurePass123!")
+
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmArgon2id, password.Algorithm())
+	assert.True(t, strings.HasPrefix(password.Hash(), "$argon2id$v=19$m=19456,t=2,p=1$"))
+	assert.True(t, password.Compare("SecurePass123!"))
+	assert.False(t, password.Compare("SecurePass123?"))
+	assert.False(t, DefaultPasswordHasher().NeedsRehash(password))
+}
+
+// los hashes bcrypt existentes se siguen aceptando y se marcan para re-hashear
+func TestPasswordHasher_BcryptHashNeedsRehash(t *testing.T) {
+	hasher := newHasher(t, bcryptParams())
+	password, err := hasher.Hash("SecurePass123!")
+	assert.NoError(t, err)
+	assert.Equal(t, AlgorithmBcrypt, password.Algorithm())
+	assert.False(t, hasher.NeedsRehash(password))
+
+	// otro costo de bcrypt
+	stronger := bcryptParams()
+	stronger.BcryptCost = BcryptCost + 1
+	assert.True(t, newHasher(t, stronger).NeedsRehash(password))
+
+	// otro algoritmo
+	assert.True(t, password.Compare("SecurePass123!"))
+	assert.True(t, DefaultPasswordHasher().NeedsRehash(password))
+}
+
+func TestPasswordHasher_Argon2ParamsChanged(t *testing.T) {
+	password, _ := NewPassword("SecurePass123!")
+
+	params := DefaultHashingParams()
+	params.Argon2.Iterations = 3
+
+	assert.True(t, password.Compare("SecurePass123!"))
+	assert.True(t, newHasher(t, params).NeedsRehash(password))
+}
+
+func TestPassword_UnknownHash(t *testing.T) {
+	password := FromHash("plaintext")
+
+	assert.Empty(t, password.Algorithm())
+	assert.False(t, password.Compare("plaintext"))
+	assert.True(t, DefaultPasswordHasher().NeedsRehash(password))
+}
+
+func TestNewPasswordHasher_Invalid(t *testing.T) {
+	for _, params := range []HashingParams{
+		{Algorithm: "md5"},
+		{Algorithm: AlgorithmBcrypt, BcryptCost: 50},
+		{Algorithm: AlgorithmArgon2id},
+	} {
+		_, err := NewPasswordHasher(params)
+		assert.Error(t, err)
+	}
+}
@@ -3,18 +3,29 @@ package bus
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// ErrUnavailable es una publicacion que fallo porque la conexion con el broker
+// esta cerrada: el evento no tiene nada de malo
+var ErrUnavailable = errors.New("event bus unavailable")
+
 type RabbitMQBus struct {
 	conn     *amqp.Connection
 	channel  *amqp.Channel
 	exchange string                     
 	handlers map[string][]EventHandler   
 	log      Logger
+
+	defaultPolicy RetryPolicy
+	policies      map[string]RetryPolicy
+
+	// suscripciones que reciben todas las instancias (ej. invalidar caches)
+	broadcast map[string][]EventHandler
 }
 
 func NewRabbitMQBus(url, exchange string, log Logger) (*RabbitMQBus, error) {
@@ -45,15 +56,45 @@ func NewRabbitMQBus(url, exchange string, log Logger) (*RabbitMQBus, error) {
 		return nil, fmt.Errorf("failed to declare exchange: %w", err)
 	}
 
+	// modo confirm: Publish espera el ack del broker, lo necesita el relay del outbox
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
 	return &RabbitMQBus{
 		conn:     conn,
 		channel:  channel,
 		exchange: exchange,
 		handlers: make(map[string][]EventHandler),
 		log:      log,
+
+		defaultPolicy: DefaultRetryPolicy(),
+		policies:      make(map[string]RetryPolicy),
+
+		broadcast: make(map[string][]EventHandler),
 	}, nil
 }
 
+// SetDefaultRetryPolicy cambia la politica de los event types sin una propia.
+// Se tiene que llamar antes de Start
+func (b *RabbitMQBus) SetDefaultRetryPolicy(policy RetryPolicy) {
+	b.defaultPolicy = policy
+}
+
+// SetRetryPolicy configura reintentos para un event type. Se tiene que llamar antes de Start
+func (b *RabbitMQBus) SetRetryPolicy(eventType string, policy RetryPolicy) {
+	b.policies[eventType] = policy
+}
+
+func (b *RabbitMQBus) retryPolicy(eventType string) RetryPolicy {
+	if policy, ok := b.policies[eventType]; ok {
+		return policy
+	}
+	return b.defaultPolicy
+}
+
 
 func (b *RabbitMQBus) Publish(ctx context.Context, event interface{}) error {
 
@@ -66,7 +107,7 @@ func (b *RabbitMQBus) Publish(ctx context.Context, event interface{}) error {
 
 	correlationID := extractCorrelationID(ctx)
 
-	err = b.channel.PublishWithContext(
+	confirmation, err := b.channel.PublishWithDeferredConfirmWithContext(
 		ctx,
 		b.exchange,
 		eventType,  
@@ -81,12 +122,23 @@ func (b *RabbitMQBus) Publish(ctx context.Context, event interface{}) error {
 		},
 	)
 
+	if err == nil {
+		var acked bool
+		acked, err = confirmation.WaitContext(ctx)
+		if err == nil && !acked {
+			err = fmt.Errorf("event %s was nacked by the broker", eventType)
+		}
+	}
+
 	if err != nil {
 		b.log.Error("failed to publish event", map[string]interface{}{
 			"error":          err.Error(),
 			"event_type":     eventType,
 			"correlation_id": correlationID,
 		})
+		if errors.Is(err, amqp.ErrClosed) {
+			return fmt.Errorf("%w: %v", ErrUnavailable, err)
+		}
 		return err
 	}
 
@@ -106,7 +158,28 @@ func (b *RabbitMQBus) Subscribe(eventType string, handler EventHandler) error {
 	return nil
 }
 
+// SubscribeBroadcast registra un handler que corre en todas las instancias y no
+// solo en una: cada proceso consume de su propia cola exclusiva, que se borra al
+// desconectarse. El pattern es una routing key de topic (ej. "tenant.*"). No hay
+// reintentos ni DLQ, un error solo se loguea; sirve para caches que tambien vencen solos
+func (b *RabbitMQBus) SubscribeBroadcast(pattern string, handler EventHandler) error {
+	b.broadcast[pattern] = append(b.broadcast[pattern], handler)
+	return nil
+}
+
 func (b *RabbitMQBus) Start(ctx context.Context) error {
+	if err := b.channel.ExchangeDeclare(
+		b.deadLetterExchange(),
+		"direct",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	); err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
 	for eventType := range b.handlers {
 		queueName := fmt.Sprintf("%s_queue", eventType)
 		
@@ -133,6 +206,9 @@ func (b *RabbitMQBus) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to bind queue: %w", err)
 		}
 
+		if err := b.declareRetryTopology(queue.Name, b.retryPolicy(eventType)); err != nil {
+			return err
+		}
 
 		msgs, err := b.channel.Consume(
 			queue.Name,
@@ -147,7 +223,7 @@ func (b *RabbitMQBus) Start(ctx context.Context) error {
 			return fmt.Errorf("failed to register consumer: %w", err)
 		}
 
-		go b.handleMessages(ctx, eventType, msgs)
+		go b.handleMessages(ctx, eventType, queue.Name, msgs)
 		
 		b.log.Info("started consuming", map[string]interface{}{
 			"event_type": eventType,
@@ -155,10 +231,104 @@ func (b *RabbitMQBus) Start(ctx context.Context) error {
 		})
 	}
 
+	for pattern := range b.broadcast {
+		if err := b.startBroadcast(ctx, pattern); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *RabbitMQBus) startBroadcast(ctx context.Context, pattern string) error {
+	// nombre generado por el broker, no durable, exclusiva y auto-delete
+	queue, err := b.channel.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare broadcast queue: %w", err)
+	}
+
+	if err := b.channel.QueueBind(queue.Name, pattern, b.exchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind broadcast queue: %w", err)
+	}
+
+	msgs, err := b.channel.Consume(queue.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to register broadcast consumer: %w", err)
+	}
+
+	go func() {
+		for msg := range msgs {
+			msgCtx := context.WithValue(ctx, "correlation_id", msg.CorrelationId)
+
+			var event map[string]interface{}
+			if err := json.Unmarshal(msg.Body, &event); err != nil {
+				b.log.Error("failed to unmarshal event", map[string]interface{}{
+					"error":          err.Error(),
+					"correlation_id": msg.CorrelationId,
+				})
+				continue
+			}
+
+			for _, handler := range b.broadcast[pattern] {
+				if err := handler(msgCtx, event); err != nil {
+					b.log.Error("broadcast handler failed", map[string]interface{}{
+						"error":          err.Error(),
+						"routing_key":    msg.RoutingKey,
+						"correlation_id": msg.CorrelationId,
+					})
+				}
+			}
+		}
+	}()
+
+	b.log.Info("started broadcast consumer", map[string]interface{}{
+		"pattern": pattern,
+		"queue":   queue.Name,
+	})
+
+	return nil
+}
+
+// declareRetryTopology crea la DLQ de la suscripcion y una cola de espera por
+// cada espera distinta de la politica. Las colas de espera tienen TTL fijo y al
+// expirar devuelven el mensaje a la cola original por el default exchange (solo
+// a esta suscripcion). El TTL va en el nombre: RabbitMQ no deja redeclarar una
+// cola con otros argumentos, asi que cambiar la politica crea colas nuevas y
+// las viejas se vacian solas
+func (b *RabbitMQBus) declareRetryTopology(queueName string, policy RetryPolicy) error {
+	dlq, err := b.channel.QueueDeclare(deadLetterQueueName(queueName), true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	if err := b.channel.QueueBind(dlq.Name, queueName, b.deadLetterExchange(), false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	for attempt := 1; attempt <= policy.Retries(); attempt++ {
+		_, err := b.channel.QueueDeclare(
+			retryQueueName(queueName, policy.Backoff(attempt)),
+			true,
+			false,
+			false,
+			false,
+			amqp.Table{
+				"x-message-ttl":             policy.Backoff(attempt).Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to declare retry queue: %w", err)
+		}
+	}
+
 	return nil
 }
 
-func (b *RabbitMQBus) handleMessages(ctx context.Context, eventType string, msgs <-chan amqp.Delivery) {
+func (b *RabbitMQBus) handleMessages(ctx context.Context, eventType, queueName string, msgs <-chan amqp.Delivery) {
+	policy := b.retryPolicy(eventType)
+
 	for msg := range msgs {
 
 		msgCtx := context.WithValue(ctx, "correlation_id", msg.CorrelationId)
@@ -169,12 +339,13 @@ func (b *RabbitMQBus) handleMessages(ctx context.Context, eventType string, msgs
 				"error":          err.Error(),
 				"correlation_id": msg.CorrelationId,
 			})
-			msg.Nack(false, false) 
+			// un mensaje mal formado no se arregla reintentando
+			b.deadLetter(msgCtx, msg, queueName, err)
 			continue
 		}
 
 		handlers := b.handlers[eventType]
-		success := true
+		var handlerErr error
 		
 		for _, handler := range handlers {
 			if err := handler(msgCtx, event); err != nil {
@@ -182,23 +353,135 @@ func (b *RabbitMQBus) handleMessages(ctx context.Context, eventType string, msgs
 					"error":          err.Error(),
 					"event_type":     eventType,
 					"correlation_id": msg.CorrelationId,
+					"attempt":        attemptFromHeaders(msg.Headers),
 				})
-				success = false
+				handlerErr = err
 				break
 			}
 		}
 
 
-		if success {
+		if handlerErr == nil {
 			msg.Ack(false) 
 			b.log.Debug("message processed", map[string]interface{}{
 				"event_type":     eventType,
 				"correlation_id": msg.CorrelationId,
 			})
-		} else {
-			msg.Nack(false, true) 
+			continue
 		}
+
+		attempt := attemptFromHeaders(msg.Headers)
+		if attempt > policy.Retries() {
+			b.deadLetter(msgCtx, msg, queueName, handlerErr)
+			continue
+		}
+
+		b.retry(msgCtx, msg, queueName, attempt, policy.Backoff(attempt), handlerErr)
+	}
+}
+
+// retry manda el mensaje a la cola de espera de delay. Si no se puede publicar
+// se hace Nack con requeue para no perderlo
+func (b *RabbitMQBus) retry(ctx context.Context, msg amqp.Delivery, queueName string, attempt int, delay time.Duration, cause error) {
+	headers := copyHeaders(msg.Headers)
+	headers[headerAttempt] = int32(attempt + 1)
+	headers[headerLastError] = cause.Error()
+
+	if err := b.republish(ctx, "", retryQueueName(queueName, delay), msg, headers); err != nil {
+		b.log.Error("failed to schedule retry", map[string]interface{}{
+			"error":          err.Error(),
+			"queue":          queueName,
+			"correlation_id": msg.CorrelationId,
+		})
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	b.log.Info("message scheduled for retry", map[string]interface{}{
+		"queue":          queueName,
+		"next_attempt":   attempt + 1,
+		"correlation_id": msg.CorrelationId,
+	})
+}
+
+// deadLetter estaciona el mensaje en la DLQ de la suscripcion con el motivo en los headers
+func (b *RabbitMQBus) deadLetter(ctx context.Context, msg amqp.Delivery, queueName string, cause error) {
+	headers := copyHeaders(msg.Headers)
+	headers[headerAttempt] = int32(attemptFromHeaders(msg.Headers))
+	headers[headerLastError] = cause.Error()
+	headers[headerOriginalQueue] = queueName
+	headers[headerOriginalRouteKey] = msg.RoutingKey
+	headers[headerFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	if err := b.republish(ctx, b.deadLetterExchange(), queueName, msg, headers); err != nil {
+		b.log.Error("failed to dead-letter message", map[string]interface{}{
+			"error":          err.Error(),
+			"queue":          queueName,
+			"correlation_id": msg.CorrelationId,
+		})
+		msg.Nack(false, true)
+		return
+	}
+
+	msg.Ack(false)
+	b.log.Error("message moved to dead-letter queue", map[string]interface{}{
+		"queue":          queueName,
+		"dlq":            deadLetterQueueName(queueName),
+		"attempt":        headers[headerAttempt],
+		"reason":         cause.Error(),
+		"correlation_id": msg.CorrelationId,
+	})
+}
+
+func (b *RabbitMQBus) republish(ctx context.Context, exchange, key string, msg amqp.Delivery, headers amqp.Table) error {
+	confirmation, err := b.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,
+		key,
+		false,
+		false,
+		amqp.Publishing{
+			Headers:       headers,
+			ContentType:   msg.ContentType,
+			Body:          msg.Body,
+			DeliveryMode:  amqp.Persistent,
+			Timestamp:     msg.Timestamp,
+			CorrelationId: msg.CorrelationId,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !acked {
+		return fmt.Errorf("message to %s was nacked by the broker", key)
+	}
+	return nil
+}
+
+func (b *RabbitMQBus) deadLetterExchange() string {
+	return b.exchange + ".dlx"
+}
+
+func retryQueueName(queueName string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%dms", queueName, delay.Milliseconds())
+}
+
+func deadLetterQueueName(queueName string) string {
+	return queueName + ".dlq"
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := amqp.Table{}
+	for k, v := range headers {
+		copied[k] = v
 	}
+	return copied
 }
 
 func (b *RabbitMQBus) Close() error {
@@ -0,0 +1,65 @@
+package bus
+
+import (
+	"time"
+)
+
+const (
+	headerAttempt          = "x-attempt"
+	headerLastError        = "x-last-error"
+	headerOriginalQueue    = "x-original-queue"
+	headerOriginalRouteKey = "x-original-routing-key"
+	headerFailedAt         = "x-failed-at"
+)
+
+// RetryPolicy define cuantas veces se reintenta un evento y cuanto se espera
+// entre intentos antes de mandarlo a la dead-letter queue
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// DefaultRetryPolicy: 5 intentos con 1s, 2s, 4s, 8s de espera
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+		Multiplier:     2,
+	}
+}
+
+// Backoff devuelve la espera antes del reintento numero attempt (empieza en 1)
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		delay *= p.Multiplier
+		if p.MaxBackoff > 0 && time.Duration(delay) >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(delay)
+}
+
+// Retries es la cantidad de reintentos (el primer intento no cuenta)
+func (p RetryPolicy) Retries() int {
+	if p.MaxAttempts <= 1 {
+		return 0
+	}
+	return p.MaxAttempts - 1
+}
+
+// attemptFromHeaders lee el numero de intento que viaja en los headers del mensaje
+func attemptFromHeaders(headers map[string]interface{}) int {
+	switch v := headers[headerAttempt].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 1
+}
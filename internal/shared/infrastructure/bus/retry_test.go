@@ -0,0 +1,62 @@
+package bus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+	assert.Equal(t, 5*time.Second, policy.Backoff(10))
+}
+
+func TestRetryPolicy_Backoff_WithoutMax(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 3}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 900*time.Millisecond, policy.Backoff(3))
+}
+
+func TestRetryPolicy_Backoff_ConstantMultiplier(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 1}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, time.Second, policy.Backoff(5))
+}
+
+func TestDefaultRetryPolicy(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	assert.Equal(t, 4, policy.Retries())
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}, []time.Duration{
+		policy.Backoff(1), policy.Backoff(2), policy.Backoff(3), policy.Backoff(4),
+	})
+}
+
+func TestRetryPolicy_Retries(t *testing.T) {
+	assert.Equal(t, 0, RetryPolicy{MaxAttempts: 0}.Retries())
+	assert.Equal(t, 0, RetryPolicy{MaxAttempts: 1}.Retries())
+	assert.Equal(t, 2, RetryPolicy{MaxAttempts: 3}.Retries())
+}
+
+func TestAttemptFromHeaders(t *testing.T) {
+	assert.Equal(t, 1, attemptFromHeaders(nil))
+	assert.Equal(t, 1, attemptFromHeaders(map[string]interface{}{headerAttempt: "3"}))
+	assert.Equal(t, 3, attemptFromHeaders(map[string]interface{}{headerAttempt: int32(3)}))
+	assert.Equal(t, 4, attemptFromHeaders(map[string]interface{}{headerAttempt: int64(4)}))
+	assert.Equal(t, 5, attemptFromHeaders(map[string]interface{}{headerAttempt: 5}))
+}
+
+func TestRetryQueueName(t *testing.T) {
+	// la espera va en el nombre: otra politica declara otra cola
+	assert.Equal(t, "users.created.retry.2000ms", retryQueueName("users.created", 2*time.Second))
+	assert.NotEqual(t, retryQueueName("users.created", time.Second), retryQueueName("users.created", 2*time.Second))
+	assert.Equal(t, "users.created.dlq", deadLetterQueueName("users.created"))
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -10,6 +12,12 @@ type Config struct {
 	Database DatabaseConfig 
 	RabbitMQ RabbitMQConfig 
 	Log      LogConfig      
+	Outbox        OutboxConfig
+	Auth          AuthConfig
+	Users         UsersConfig
+	Notifications NotificationsConfig
+	Passwords     PasswordsConfig
+	Tenants       TenantsConfig
 }
 
 type DatabaseConfig struct {
@@ -19,6 +27,24 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	SSLMode  string
+
+	// las migraciones corren con un rol dueño de las tablas; la app con uno
+	// sin BYPASSRLS para que apliquen las politicas por tenant
+	MigrationsUser     string
+	MigrationsPassword string
+}
+
+// ForMigrations es la config con las credenciales del rol de migraciones, o
+// las de la app si no se configuraron aparte
+func (c DatabaseConfig) ForMigrations() DatabaseConfig {
+	if c.MigrationsUser == "" {
+		return c
+	}
+
+	migrations := c
+	migrations.User = c.MigrationsUser
+	migrations.Password = c.MigrationsPassword
+	return migrations
 }
 
 type RabbitMQConfig struct {
@@ -26,6 +52,13 @@ type RabbitMQConfig struct {
 	Exchange     string 
 	QueueUsers   string 
 	PrefetchCount int   
+	Retry         RetryConfig
+}
+
+type RetryConfig struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
 }
 
 type LogConfig struct {
@@ -33,6 +66,104 @@ type LogConfig struct {
 	Format string 
 }
 
+// OutboxConfig: un evento que el broker rechaza MaxAttempts veces queda
+// apartado en el outbox
+type OutboxConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+}
+
+// UsersConfig: un usuario borrado se puede restaurar durante PurgeAfter,
+// despues el consumer lo elimina y el email queda libre
+type UsersConfig struct {
+	PurgeAfter     time.Duration
+	PurgeInterval  time.Duration
+	PurgeBatchSize int
+	EmailChangeTTL time.Duration
+	// EmailVerificationTTL es la vigencia del link de verificacion y
+	// VerificationResendInterval el minimo entre reenvios
+	EmailVerificationTTL       time.Duration
+	VerificationResendInterval time.Duration
+	PasswordResetTTL           time.Duration
+	// PasswordResetInterval el minimo entre links de reset para un mismo usuario
+	PasswordResetInterval time.Duration
+	// PasswordDenylistPath es la lista de passwords filtradas del operador
+	// (archivo de SHA-1 o directorio por prefijo); vacio = solo la que viene con la app
+	PasswordDenylistPath string
+}
+
+// NotificationsConfig: Sender es log (archivo o log, para desarrollo) o smtp.
+// From* y DefaultLocale se usan si el tenant no tiene los suyos
+type NotificationsConfig struct {
+	Sender         string
+	OutputDir      string
+	SMTP           SMTPConfig
+	FromAddress    string
+	FromName       string
+	DefaultLocale  string
+	AppURL         string // base de los links que van en los mensajes
+	Interval       time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// PasswordsConfig es el hash de las passwords nuevas; los hashes con otro
+// algoritmo o parametros se actualizan cuando el usuario se loguea
+type PasswordsConfig struct {
+	HashAlgorithm     string // argon2id o bcrypt
+	BcryptCost        int
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism int // 1 a 255, se valida al arrancar
+}
+
+// TenantsConfig: AdminAPIKey protege /api/v1/admin (vacio = deshabilitada),
+// CacheTTL es cuanto se confia en la validacion cacheada de X-Tenant-Id,
+// DedicatedPoolSize el maximo de conexiones de cada tenant con schema propio,
+// DefaultRateLimit los requests por minuto con que se provisiona un tenant y
+// ExportDir donde quedan los exports de los tenants dados de baja
+type TenantsConfig struct {
+	AdminAPIKey       string
+	CacheTTL          time.Duration
+	DedicatedPoolSize int
+	DefaultRateLimit  int
+	ExportDir         string
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+}
+
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	TokenFormat     string // opaque o jwt
+	Lockout         LockoutConfig
+	JWTKeysDir      string
+	JWTSigningKeyID string
+	JWTAlgorithm    string // se usa solo si no hay JWTKeysDir (clave generada en memoria)
+	JWTIssuer       string
+	MFAIssuer       string // nombre que muestra la app autenticadora
+	MFAChallengeTTL time.Duration
+	// clave AES-256 en base64 con la que se cifran los secretos TOTP
+	MFAEncryptionKey string
+}
+
+// bloqueo por logins fallidos, por email y por IP
+type LockoutConfig struct {
+	MaxFailures   int
+	IPMaxFailures int
+	Window        time.Duration
+	Duration      time.Duration
+	MaxDuration   time.Duration
+}
+
 // Aca uso viper como pedia el pdf
 
 func Load() (*Config, error) {
@@ -47,6 +178,53 @@ func Load() (*Config, error) {
 	viper.SetDefault("RABBITMQ_EXCHANGE", "backend_events")
 	viper.SetDefault("RABBITMQ_QUEUE_USERS", "users_commands")
 	viper.SetDefault("RABBITMQ_PREFETCH_COUNT", 10)
+	viper.SetDefault("RABBITMQ_RETRY_MAX_ATTEMPTS", 5)
+	viper.SetDefault("RABBITMQ_RETRY_INITIAL_BACKOFF", "1s")
+	viper.SetDefault("RABBITMQ_RETRY_MAX_BACKOFF", "5m")
+	viper.SetDefault("AUTH_ACCESS_TOKEN_TTL", "15m")
+	viper.SetDefault("AUTH_REFRESH_TOKEN_TTL", "720h")
+	viper.SetDefault("AUTH_TOKEN_FORMAT", "opaque")
+	viper.SetDefault("AUTH_LOCKOUT_MAX_FAILURES", 5)
+	viper.SetDefault("AUTH_LOCKOUT_IP_MAX_FAILURES", 50)
+	viper.SetDefault("AUTH_LOCKOUT_WINDOW", "15m")
+	viper.SetDefault("AUTH_LOCKOUT_DURATION", "5m")
+	viper.SetDefault("AUTH_LOCKOUT_MAX_DURATION", "24h")
+	viper.SetDefault("AUTH_JWT_SIGNING_KEY_ID", "dev")
+	viper.SetDefault("AUTH_JWT_ALGORITHM", "EdDSA")
+	viper.SetDefault("AUTH_JWT_ISSUER", "backend-challenge-guinea")
+	viper.SetDefault("AUTH_MFA_ISSUER", "Backend Challenge")
+	viper.SetDefault("AUTH_MFA_CHALLENGE_TTL", "5m")
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", "1s")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 10)
+	viper.SetDefault("USERS_PURGE_AFTER", "720h")
+	viper.SetDefault("USERS_PURGE_INTERVAL", "1h")
+	viper.SetDefault("USERS_PURGE_BATCH_SIZE", 100)
+	viper.SetDefault("USERS_EMAIL_CHANGE_TTL", "24h")
+	viper.SetDefault("USERS_EMAIL_VERIFICATION_TTL", "48h")
+	viper.SetDefault("USERS_VERIFICATION_RESEND_INTERVAL", "1m")
+	viper.SetDefault("USERS_PASSWORD_RESET_TTL", "1h")
+	viper.SetDefault("USERS_PASSWORD_RESET_INTERVAL", "1m")
+	viper.SetDefault("NOTIFICATIONS_SENDER", "log")
+	viper.SetDefault("NOTIFICATIONS_FROM_ADDRESS", "no-reply@localhost")
+	viper.SetDefault("NOTIFICATIONS_FROM_NAME", "Backend Challenge")
+	viper.SetDefault("NOTIFICATIONS_DEFAULT_LOCALE", "es")
+	viper.SetDefault("NOTIFICATIONS_APP_URL", "http://localhost:3000")
+	viper.SetDefault("NOTIFICATIONS_INTERVAL", "5s")
+	viper.SetDefault("NOTIFICATIONS_BATCH_SIZE", 50)
+	viper.SetDefault("NOTIFICATIONS_MAX_ATTEMPTS", 8)
+	viper.SetDefault("NOTIFICATIONS_INITIAL_BACKOFF", "30s")
+	viper.SetDefault("NOTIFICATIONS_MAX_BACKOFF", "1h")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("PASSWORD_HASH_ALGORITHM", "argon2id")
+	viper.SetDefault("BCRYPT_COST", 10)
+	viper.SetDefault("ARGON2_MEMORY_KB", 19456)
+	viper.SetDefault("ARGON2_ITERATIONS", 2)
+	viper.SetDefault("ARGON2_PARALLELISM", 1)
+	viper.SetDefault("TENANTS_CACHE_TTL", "1m")
+	viper.SetDefault("TENANTS_DEDICATED_POOL_SIZE", 5)
+	viper.SetDefault("TENANTS_DEFAULT_RATE_LIMIT", 100)
+	viper.SetDefault("TENANTS_EXPORT_DIR", "exports")
 
 	_ = viper.ReadInConfig()
 
@@ -60,16 +238,92 @@ func Load() (*Config, error) {
 			User:     viper.GetString("DB_USER"),
 			Password: viper.GetString("DB_PASSWORD"),
 			SSLMode:  viper.GetString("DB_SSL_MODE"),
+
+			MigrationsUser:     viper.GetString("DB_MIGRATIONS_USER"),
+			MigrationsPassword: viper.GetString("DB_MIGRATIONS_PASSWORD"),
 		},
 		RabbitMQ: RabbitMQConfig{
 			URL:          viper.GetString("RABBITMQ_URL"),
 			Exchange:     viper.GetString("RABBITMQ_EXCHANGE"),
 			QueueUsers:   viper.GetString("RABBITMQ_QUEUE_USERS"),
 			PrefetchCount: viper.GetInt("RABBITMQ_PREFETCH_COUNT"),
+			Retry: RetryConfig{
+				MaxAttempts:    viper.GetInt("RABBITMQ_RETRY_MAX_ATTEMPTS"),
+				InitialBackoff: viper.GetDuration("RABBITMQ_RETRY_INITIAL_BACKOFF"),
+				MaxBackoff:     viper.GetDuration("RABBITMQ_RETRY_MAX_BACKOFF"),
+			},
 		},
 		Log: LogConfig{
 			Level:  viper.GetString("LOG_LEVEL"),
 			Format: viper.GetString("LOG_FORMAT"),
 		},
+		Outbox: OutboxConfig{
+			PollInterval: viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+			BatchSize:    viper.GetInt("OUTBOX_BATCH_SIZE"),
+			MaxAttempts:  viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+		},
+		Users: UsersConfig{
+			PurgeAfter:                 viper.GetDuration("USERS_PURGE_AFTER"),
+			PurgeInterval:              viper.GetDuration("USERS_PURGE_INTERVAL"),
+			PurgeBatchSize:             viper.GetInt("USERS_PURGE_BATCH_SIZE"),
+			EmailChangeTTL:             viper.GetDuration("USERS_EMAIL_CHANGE_TTL"),
+			EmailVerificationTTL:       viper.GetDuration("USERS_EMAIL_VERIFICATION_TTL"),
+			VerificationResendInterval: viper.GetDuration("USERS_VERIFICATION_RESEND_INTERVAL"),
+			PasswordResetTTL:           viper.GetDuration("USERS_PASSWORD_RESET_TTL"),
+			PasswordResetInterval:      viper.GetDuration("USERS_PASSWORD_RESET_INTERVAL"),
+			PasswordDenylistPath:       viper.GetString("USERS_PASSWORD_DENYLIST_PATH"),
+		},
+		Notifications: NotificationsConfig{
+			Sender:    viper.GetString("NOTIFICATIONS_SENDER"),
+			OutputDir: viper.GetString("NOTIFICATIONS_OUTPUT_DIR"),
+			SMTP: SMTPConfig{
+				Host:     viper.GetString("SMTP_HOST"),
+				Port:     viper.GetString("SMTP_PORT"),
+				Username: viper.GetString("SMTP_USERNAME"),
+				Password: viper.GetString("SMTP_PASSWORD"),
+			},
+			FromAddress:    viper.GetString("NOTIFICATIONS_FROM_ADDRESS"),
+			FromName:       viper.GetString("NOTIFICATIONS_FROM_NAME"),
+			DefaultLocale:  viper.GetString("NOTIFICATIONS_DEFAULT_LOCALE"),
+			AppURL:         viper.GetString("NOTIFICATIONS_APP_URL"),
+			Interval:       viper.GetDuration("NOTIFICATIONS_INTERVAL"),
+			BatchSize:      viper.GetInt("NOTIFICATIONS_BATCH_SIZE"),
+			MaxAttempts:    viper.GetInt("NOTIFICATIONS_MAX_ATTEMPTS"),
+			InitialBackoff: viper.GetDuration("NOTIFICATIONS_INITIAL_BACKOFF"),
+			MaxBackoff:     viper.GetDuration("NOTIFICATIONS_MAX_BACKOFF"),
+		},
+		Passwords: PasswordsConfig{
+			HashAlgorithm:     viper.GetString("PASSWORD_HASH_ALGORITHM"),
+			BcryptCost:        viper.GetInt("BCRYPT_COST"),
+			Argon2Memory:      viper.GetUint32("ARGON2_MEMORY_KB"),
+			Argon2Iterations:  viper.GetUint32("ARGON2_ITERATIONS"),
+			Argon2Parallelism: viper.GetInt("ARGON2_PARALLELISM"),
+		},
+		Tenants: TenantsConfig{
+			AdminAPIKey:       viper.GetString("TENANTS_ADMIN_API_KEY"),
+			CacheTTL:          viper.GetDuration("TENANTS_CACHE_TTL"),
+			DedicatedPoolSize: viper.GetInt("TENANTS_DEDICATED_POOL_SIZE"),
+			DefaultRateLimit:  viper.GetInt("TENANTS_DEFAULT_RATE_LIMIT"),
+			ExportDir:         viper.GetString("TENANTS_EXPORT_DIR"),
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:  viper.GetDuration("AUTH_ACCESS_TOKEN_TTL"),
+			RefreshTokenTTL: viper.GetDuration("AUTH_REFRESH_TOKEN_TTL"),
+			TokenFormat:     viper.GetString("AUTH_TOKEN_FORMAT"),
+			Lockout: LockoutConfig{
+				MaxFailures:   viper.GetInt("AUTH_LOCKOUT_MAX_FAILURES"),
+				IPMaxFailures: viper.GetInt("AUTH_LOCKOUT_IP_MAX_FAILURES"),
+				Window:        viper.GetDuration("AUTH_LOCKOUT_WINDOW"),
+				Duration:      viper.GetDuration("AUTH_LOCKOUT_DURATION"),
+				MaxDuration:   viper.GetDuration("AUTH_LOCKOUT_MAX_DURATION"),
+			},
+			JWTKeysDir:       viper.GetString("AUTH_JWT_KEYS_DIR"),
+			JWTSigningKeyID:  viper.GetString("AUTH_JWT_SIGNING_KEY_ID"),
+			JWTAlgorithm:     viper.GetString("AUTH_JWT_ALGORITHM"),
+			JWTIssuer:        viper.GetString("AUTH_JWT_ISSUER"),
+			MFAIssuer:        viper.GetString("AUTH_MFA_ISSUER"),
+			MFAChallengeTTL:  viper.GetDuration("AUTH_MFA_CHALLENGE_TTL"),
+			MFAEncryptionKey: viper.GetString("AUTH_MFA_ENCRYPTION_KEY"),
+		},
 	}, nil
 }
\ No newline at end of file
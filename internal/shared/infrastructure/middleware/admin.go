@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminKeyMiddleware protege la API de administracion de la plataforma (no
+// de un tenant) con el header X-Admin-Key. Sin clave configurada la API queda
+// deshabilitada
+func AdminKeyMiddleware(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key == "" {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "admin api is disabled",
+			})
+			c.Abort()
+			return
+		}
+
+		provided := c.GetHeader("X-Admin-Key")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid admin key",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Principal es el usuario autenticado de la request
+type Principal struct {
+	UserID    string
+	TenantID  string
+	SessionID string
+}
+
+// TokenResolver traduce un bearer token a un Principal. Lo implementa el contexto de auth
+type TokenResolver interface {
+	Resolve(ctx context.Context, token string) (*Principal, error)
+}
+
+// AuthMiddleware exige un header Authorization: Bearer <token> valido y deja
+// usuario, tenant y sesion en el contexto. Si viene X-Tenant-Id tiene que
+// coincidir con el tenant de la sesion
+func AuthMiddleware(resolver TokenResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearerToken(c.GetHeader("Authorization"))
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "missing bearer token",
+			})
+			c.Abort()
+			return
+		}
+
+		principal, err := resolver.Resolve(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid or expired token",
+			})
+			c.Abort()
+			return
+		}
+
+		if tenantID := c.GetHeader("X-Tenant-Id"); tenantID != "" && tenantID != principal.TenantID {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "token does not belong to this tenant",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("tenant_id", principal.TenantID)
+		c.Set("user_id", principal.UserID)
+		c.Set("session_id", principal.SessionID)
+		c.Next()
+	}
+}
+
+// user id autenticado del context
+func GetUserID(c *gin.Context) string {
+	if userID, exists := c.Get("user_id"); exists {
+		return userID.(string)
+	}
+	return ""
+}
+
+// session id autenticada del context
+func GetSessionID(c *gin.Context) string {
+	if sessionID, exists := c.Get("session_id"); exists {
+		return sessionID.(string)
+	}
+	return ""
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionChecker dice si un usuario tiene un permiso en su tenant. Lo
+// implementa el contexto de users, que es donde viven los roles
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, tenantID, userID, permission string) (bool, error)
+}
+
+// RequirePermission corta con 403 si el usuario autenticado no tiene el
+// permiso. Va despues de AuthMiddleware
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorize(c, checker, permission) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTargetPermission es para rutas /:param sobre un usuario: si apunta al
+// propio usuario alcanza con ownPermission (vacio = siempre permitido), si no
+// se exige permission
+func RequireTargetPermission(checker PermissionChecker, param, ownPermission, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required := permission
+		if c.Param(param) == GetUserID(c) {
+			required = ownPermission
+		}
+
+		if required != "" && !authorize(c, checker, required) {
+			return
+		}
+		c.Next()
+	}
+}
+
+func authorize(c *gin.Context, checker PermissionChecker, permission string) bool {
+	userID := GetUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "authentication required",
+		})
+		c.Abort()
+		return false
+	}
+
+	allowed, err := checker.HasPermission(c.Request.Context(), GetTenantID(c), userID, permission)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		c.Abort()
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":      "insufficient permissions",
+			"permission": permission,
+		})
+		c.Abort()
+		return false
+	}
+
+	return true
+}
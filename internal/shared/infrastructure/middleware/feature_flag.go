@@ -6,15 +6,31 @@ import (
 
 // feature flags por tenant. Habilitar/deshabilitar funcionalidades sin deployar. tenant_id -> feature -> enabled
 type FeatureFlags struct {
-	flags map[string]map[string]bool
-	mu    sync.RWMutex
+	flags    map[string]map[string]bool
+	defaults map[string]bool
+	mu       sync.RWMutex
 }
 
+const (
+	// FeatureMFARequired obliga a todos los usuarios del tenant a usar segundo factor
+	FeatureMFARequired = "mfa_required"
+	// FeatureEmailVerificationRequired no deja loguearse a quien no confirmo su email
+	FeatureEmailVerificationRequired = "email_verification_required"
+	// FeatureSelfSignup deja crear usuarios sin sesion (registro abierto); quedan como member
+	FeatureSelfSignup = "self_signup"
+)
+
 //crea gestor de feature flags
 func NewFeatureFlags() *FeatureFlags {
 	ff := &FeatureFlags{
-		flags: make(map[string]map[string]bool),
+		flags:    make(map[string]map[string]bool),
+		defaults: make(map[string]bool),
 	}
+
+	// los features que restringen (como exigir MFA) arrancan apagados
+	ff.SetDefault(FeatureMFARequired, false)
+	ff.SetDefault(FeatureEmailVerificationRequired, false)
+	ff.SetDefault(FeatureSelfSignup, false)
 	
 	// config inicial (en producción vendría de DB)
 	ff.SetFeature("tenant-1", "user_display_name", true)
@@ -29,7 +45,13 @@ func (ff *FeatureFlags) IsEnabled(tenantID, feature string) bool {
 	defer ff.mu.RUnlock()
 
 	if tenantFlags, ok := ff.flags[tenantID]; ok {
-		return tenantFlags[feature]
+		if enabled, ok := tenantFlags[feature]; ok {
+			return enabled
+		}
+	}
+
+	if enabled, ok := ff.defaults[feature]; ok {
+		return enabled
 	}
 
 	// Por defecto, todos los features están habilitados
@@ -46,4 +68,25 @@ func (ff *FeatureFlags) SetFeature(tenantID, feature string, enabled bool) {
 	}
 
 	ff.flags[tenantID][feature] = enabled
-}
\ No newline at end of file
+}
+
+// SetDefault define el valor de un feature para los tenants que no lo configuran
+func (ff *FeatureFlags) SetDefault(feature string, enabled bool) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	ff.defaults[feature] = enabled
+}
+
+// Defaults devuelve una copia de los valores por defecto; es lo que recibe un
+// tenant nuevo al provisionarlo
+func (ff *FeatureFlags) Defaults() map[string]bool {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	defaults := make(map[string]bool, len(ff.defaults))
+	for feature, enabled := range ff.defaults {
+		defaults[feature] = enabled
+	}
+	return defaults
+}
@@ -15,6 +15,7 @@ type RateLimiter struct {
 	requests int           // número de requests permitidos
 	window   time.Duration // ventana de tiempo
 	buckets  map[string]*bucket
+	limits   map[string]int // limite propio de cada tenant, si tiene
 	mu       sync.RWMutex
 }
 
@@ -31,6 +32,7 @@ func NewRateLimiter(requests int, window time.Duration) *RateLimiter {
 		requests: requests,
 		window:   window,
 		buckets:  make(map[string]*bucket),
+		limits:   make(map[string]int),
 	}
 
 	// goroutine para limpiar buckets viejos
@@ -51,7 +53,7 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 		// Clave: tenant_id + path (ej: "tenant-1:/users")
 		key := fmt.Sprintf("%s:%s", tenantID, c.FullPath())
 
-		if !rl.allow(key) {
+		if !rl.allow(key, rl.limitFor(tenantID)) {
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "rate limit exceeded",
 			})
@@ -63,8 +65,31 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	}
 }
 
+// SetTenantLimit fija los requests por ventana de un tenant; 0 lo deja con el
+// limite global
+func (rl *RateLimiter) SetTenantLimit(tenantID string, requests int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if requests <= 0 {
+		delete(rl.limits, tenantID)
+		return
+	}
+	rl.limits[tenantID] = requests
+}
+
+func (rl *RateLimiter) limitFor(tenantID string) int {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	if requests, ok := rl.limits[tenantID]; ok {
+		return requests
+	}
+	return rl.requests
+}
+
 // allow verifica si la request puede pasar
-func (rl *RateLimiter) allow(key string) bool {
+func (rl *RateLimiter) allow(key string, limit int) bool {
 	rl.mu.RLock()
 	b, exists := rl.buckets[key]
 	rl.mu.RUnlock()
@@ -92,7 +117,7 @@ func (rl *RateLimiter) allow(key string) bool {
 	}
 
 	// Verificar si llegó al límite
-	if b.count >= rl.requests {
+	if b.count >= limit {
 		return false
 	}
 
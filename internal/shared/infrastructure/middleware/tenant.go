@@ -1,17 +1,36 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+var (
+	ErrUnknownTenant   = errors.New("unknown tenant")
+	ErrTenantSuspended = errors.New("tenant is suspended")
+)
+
+// TenantValidator dice si el tenant existe y esta activo; devuelve
+// ErrUnknownTenant o ErrTenantSuspended. Lo implementa el contexto de tenants
+type TenantValidator interface {
+	ValidateTenant(ctx context.Context, tenantID string) error
+}
+
+// TenantRouter deja en el context el tenant y la conexion a sus datos (tablas
+// compartidas o schema dedicado). Lo implementa persistence.TenantRouter
+type TenantRouter interface {
+	Route(ctx context.Context, tenantID string) (context.Context, error)
+}
+
 // TenantMiddleware extrae el tenant ID del header X-Tenant-Id
 // Todas las operaciones deben incluir el tenant para multi-tenancy
-func TenantMiddleware() gin.HandlerFunc {
+func TenantMiddleware(validator TenantValidator, router TenantRouter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tenantID := c.GetHeader("X-Tenant-Id")
-		
+
 		// Si no hay tenant ID, rechazamos la request
 		if tenantID == "" {
 			c.JSON(http.StatusBadRequest, gin.H{
@@ -21,7 +40,39 @@ func TenantMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// un id mal escrito no tiene que terminar guardando datos de un tenant nuevo
+		if err := validator.ValidateTenant(c.Request.Context(), tenantID); err != nil {
+			switch {
+			case errors.Is(err, ErrUnknownTenant):
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "unknown tenant",
+				})
+			case errors.Is(err, ErrTenantSuspended):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error": "tenant is suspended",
+				})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": err.Error(),
+				})
+			}
+			c.Abort()
+			return
+		}
+
+		// los repositorios toman del context el tenant (para las politicas RLS)
+		// y el pool de su schema si tiene uno dedicado
+		ctx, err := router.Route(c.Request.Context(), tenantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
 		c.Set("tenant_id", tenantID)
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
@@ -32,4 +83,4 @@ func GetTenantID(c *gin.Context) string {
 		return tenantID.(string)
 	}
 	return ""
-}
\ No newline at end of file
+}
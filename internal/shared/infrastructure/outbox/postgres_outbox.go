@@ -0,0 +1,138 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	shared "backend-challenge-guinea/internal/shared/domain"
+	"backend-challenge-guinea/internal/shared/infrastructure/persistence"
+)
+
+// Message es una fila pendiente del outbox. Implementa EventType y MarshalJSON
+// para que el bus la publique tal cual se guardo
+type Message struct {
+	ID            string
+	Type          string
+	TenantID      string
+	CorrelationID string
+	Payload       json.RawMessage
+	Attempts      int
+}
+
+func (m Message) EventType() string { return m.Type }
+
+func (m Message) MarshalJSON() ([]byte, error) { return m.Payload, nil }
+
+// PostgresOutbox guarda los eventos en la tabla outbox usando la misma
+// transaccion que el repositorio (si hay una en el context)
+type PostgresOutbox struct {
+	db *sql.DB
+}
+
+func NewPostgresOutbox(db *sql.DB) *PostgresOutbox {
+	return &PostgresOutbox{db: db}
+}
+
+// Publish no publica en el broker, encola el evento para que lo publique el Relay
+func (o *PostgresOutbox) Publish(ctx context.Context, event interface{}) error {
+	domainEvent, ok := event.(shared.DomainEvent)
+	if !ok {
+		return fmt.Errorf("outbox: unsupported event type %T", event)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	id := domainEvent.EventID()
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	query := `
+		INSERT INTO outbox (id, event_type, aggregate_id, tenant_id, correlation_id, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err = persistence.GetExecutor(ctx, o.db).ExecContext(
+		ctx,
+		query,
+		id,
+		domainEvent.EventType(),
+		domainEvent.AggregateID(),
+		domainEvent.TenantID(),
+		domainEvent.CorrelationID(),
+		payload,
+		time.Now().UTC(),
+	)
+
+	return err
+}
+
+// FetchPending bloquea hasta limit filas sin publicar. Tiene que llamarse dentro
+// de una transaccion para que el lock dure hasta el commit
+func (o *PostgresOutbox) FetchPending(ctx context.Context, limit int) ([]Message, error) {
+	query := `
+		SELECT id, event_type, tenant_id, COALESCE(correlation_id, ''), payload, attempts
+		FROM outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY created_at
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`
+
+	rows, err := persistence.GetExecutor(ctx, o.db).QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var msg Message
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.Type,
+			&msg.TenantID,
+			&msg.CorrelationID,
+			&msg.Payload,
+			&msg.Attempts,
+		); err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, rows.Err()
+}
+
+func (o *PostgresOutbox) MarkPublished(ctx context.Context, id string) error {
+	query := `UPDATE outbox SET published_at = $2, attempts = attempts + 1, last_error = NULL WHERE id = $1`
+
+	_, err := persistence.GetExecutor(ctx, o.db).ExecContext(ctx, query, id, time.Now().UTC())
+	return err
+}
+
+// MarkFailed suma el intento y, si llego a maxAttempts, aparta la fila: deja
+// de salir en FetchPending hasta que alguien le borre failed_at. Devuelve si
+// la aparto
+func (o *PostgresOutbox) MarkFailed(ctx context.Context, id string, cause error, maxAttempts int) (bool, error) {
+	query := `
+		UPDATE outbox SET
+			attempts = attempts + 1,
+			last_error = $2,
+			failed_at = CASE WHEN attempts + 1 >= $3 THEN $4::timestamp END
+		WHERE id = $1
+		RETURNING failed_at IS NOT NULL
+	`
+
+	var parked bool
+	err := persistence.GetExecutor(ctx, o.db).QueryRowContext(ctx, query, id, cause.Error(), maxAttempts, time.Now().UTC()).Scan(&parked)
+	return parked, err
+}
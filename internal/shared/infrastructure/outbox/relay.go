@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"backend-challenge-guinea/internal/shared/infrastructure/bus"
+)
+
+// Relay lee el outbox y publica los eventos pendientes en el bus.
+// Garantia at-least-once: la fila se marca como publicada despues de que el
+// broker confirma, si el commit falla el evento se vuelve a publicar. Un evento
+// que el broker rechaza maxAttempts veces queda apartado (failed_at) para no
+// trabar a los que vienen detras
+type Relay struct {
+	outbox      *PostgresOutbox
+	txManager   TransactionManager
+	publisher   Publisher
+	log         Logger
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+}
+
+func NewRelay(outbox *PostgresOutbox, txManager TransactionManager, publisher Publisher, log Logger, interval time.Duration, batchSize, maxAttempts int) *Relay {
+	return &Relay{
+		outbox:      outbox,
+		txManager:   txManager,
+		publisher:   publisher,
+		log:         log,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Run hace polling hasta que se cancele el context
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// si el batch vino lleno seguimos sin esperar al ticker
+		for {
+			published, err := r.ProcessBatch(ctx)
+			if err != nil {
+				r.log.Error("outbox relay batch failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+				break
+			}
+			if published < r.batchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessBatch publica un lote de eventos y devuelve cuantos salieron bien
+func (r *Relay) ProcessBatch(ctx context.Context) (int, error) {
+	published := 0
+
+	err := r.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		messages, err := r.outbox.FetchPending(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, msg := range messages {
+			msgCtx := context.WithValue(ctx, "correlation_id", msg.CorrelationID)
+
+			if err := r.publisher.Publish(msgCtx, msg); err != nil {
+				r.log.Error("failed to relay outbox event", map[string]interface{}{
+					"error":          err.Error(),
+					"outbox_id":      msg.ID,
+					"event_type":     msg.Type,
+					"correlation_id": msg.CorrelationID,
+				})
+				// sin conexion con el broker el evento no fallo: no se le cuenta
+				// el intento. En los dos casos no tiene sentido seguir con el lote
+				if errors.Is(err, bus.ErrUnavailable) || ctx.Err() != nil {
+					return err
+				}
+
+				parked, markErr := r.outbox.MarkFailed(ctx, msg.ID, err, r.maxAttempts)
+				if markErr != nil {
+					return markErr
+				}
+				if parked {
+					r.log.Error("outbox event parked after too many attempts", map[string]interface{}{
+						"outbox_id":      msg.ID,
+						"event_type":     msg.Type,
+						"attempts":       msg.Attempts + 1,
+						"correlation_id": msg.CorrelationID,
+					})
+				}
+				return nil
+			}
+
+			if err := r.outbox.MarkPublished(ctx, msg.ID); err != nil {
+				return err
+			}
+			published++
+		}
+
+		return nil
+	})
+
+	return published, err
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event interface{}) error
+}
+
+type TransactionManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type Logger interface {
+	Info(msg string, fields map[string]interface{})
+	Error(msg string, fields map[string]interface{})
+}
@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// recorder es un driver de database/sql que no habla con Postgres: anota los
+// BEGIN/COMMIT/ROLLBACK y cada Exec con sus argumentos, para ver que corre en
+// cada transaccion
+type recorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func newRecordingDB() (*sql.DB, *recorder) {
+	rec := &recorder{}
+	return sql.OpenDB(rec), rec
+}
+
+func (r *recorder) record(statement string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, statement)
+}
+
+func (r *recorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+func (r *recorder) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{rec: r}, nil
+}
+
+func (r *recorder) Driver() driver.Driver {
+	return recordingDriver{rec: r}
+}
+
+type recordingDriver struct {
+	rec *recorder
+}
+
+func (d recordingDriver) Open(name string) (driver.Conn, error) {
+	return &recordingConn{rec: d.rec}, nil
+}
+
+type recordingConn struct {
+	rec *recorder
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c *recordingConn) Close() error {
+	return nil
+}
+
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.rec.record("BEGIN")
+	return recordingTx{rec: c.rec}, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.rec.record(formatStatement(query, args))
+	return driver.RowsAffected(0), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.rec.record(formatStatement(query, args))
+	return emptyRows{}, nil
+}
+
+type recordingTx struct {
+	rec *recorder
+}
+
+func (t recordingTx) Commit() error {
+	t.rec.record("COMMIT")
+	return nil
+}
+
+func (t recordingTx) Rollback() error {
+	t.rec.record("ROLLBACK")
+	return nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return nil }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+func formatStatement(query string, args []driver.NamedValue) string {
+	if len(args) == 0 {
+		return query
+	}
+
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = fmt.Sprint(arg.Value)
+	}
+	return query + " [" + strings.Join(values, ", ") + "]"
+}
@@ -11,12 +11,7 @@ import (
 
 func NewPostgresConnection(cfg config.DatabaseConfig) (*sql.DB, error) {
 
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
-	)
-
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", postgresDSN(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -30,4 +25,11 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*sql.DB, error) {
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+func postgresDSN(cfg config.DatabaseConfig) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, cfg.SSLMode,
+	)
+}
@@ -0,0 +1,244 @@
+package persistence
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/lib/pq"
+
+	"backend-challenge-guinea/internal/shared/infrastructure/config"
+)
+
+// las migraciones de migrations/ crean las tablas compartidas en public; las
+// de esta carpeta, las tablas de datos de cada schema dedicado
+const tenantMigrationsSource = "file://migrations/tenant"
+
+type routeKey struct{}
+
+// SchemaResolver dice si el tenant tiene schema dedicado. Lo implementa el
+// contexto de tenants
+type SchemaResolver interface {
+	HasDedicatedSchema(ctx context.Context, tenantID string) (bool, error)
+}
+
+// TenantRouter elige la conexion de cada tenant: los compartidos usan la de
+// siempre y los dedicados un pool propio con search_path en su schema (y
+// public detras, para las tablas comunes como outbox o tenants). Los
+// repositorios no cambian: GetExecutor y las transacciones toman el pool del
+// context
+type TenantRouter struct {
+	cfg      config.DatabaseConfig
+	db       *sql.DB
+	resolver SchemaResolver
+	poolSize int
+	open     func(dsn string) (*sql.DB, error)
+
+	mu    sync.Mutex
+	pools map[string]*sql.DB
+}
+
+func NewTenantRouter(cfg config.DatabaseConfig, db *sql.DB, resolver SchemaResolver, poolSize int) *TenantRouter {
+	return &TenantRouter{
+		cfg:      cfg,
+		db:       db,
+		resolver: resolver,
+		poolSize: poolSize,
+		open:     openPostgres,
+		pools:    make(map[string]*sql.DB),
+	}
+}
+
+func openPostgres(dsn string) (*sql.DB, error) {
+	return sql.Open("postgres", dsn)
+}
+
+// TenantSchema es el nombre del schema dedicado del tenant. Los ids largos se
+// acortan con un hash para entrar en los 63 caracteres de Postgres
+func TenantSchema(tenantID string) string {
+	schema := "tenant_" + strings.ReplaceAll(tenantID, "-", "_")
+	if len(schema) <= 63 {
+		return schema
+	}
+
+	sum := sha256.Sum256([]byte(tenantID))
+	return schema[:46] + "_" + hex.EncodeToString(sum[:])[:16]
+}
+
+// Route deja en el context el tenant y, si tiene schema dedicado, su pool
+func (r *TenantRouter) Route(ctx context.Context, tenantID string) (context.Context, error) {
+	dedicated, err := r.resolver.HasDedicatedSchema(ctx, tenantID)
+	if err != nil {
+		return WithTenant(ctx, tenantID), err
+	}
+
+	return r.Scope(ctx, tenantID, dedicated)
+}
+
+// Scope es Route cuando ya se sabe la isolation del tenant, ej. durante el
+// alta, antes de que el resolver lo conozca
+func (r *TenantRouter) Scope(ctx context.Context, tenantID string, dedicated bool) (context.Context, error) {
+	ctx = WithTenant(ctx, tenantID)
+	if !dedicated {
+		return ctx, nil
+	}
+
+	pool, err := r.pool(tenantID)
+	if err != nil {
+		return ctx, err
+	}
+	return context.WithValue(ctx, routeKey{}, pool), nil
+}
+
+// ProvisionSchema crea el schema del tenant y le aplica las migraciones; se
+// puede repetir sin efecto
+func (r *TenantRouter) ProvisionSchema(ctx context.Context, tenantID string) error {
+	schema := TenantSchema(tenantID)
+
+	migrator, err := r.migrator(tenantID)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if _, err := migrator.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(schema)); err != nil {
+		return fmt.Errorf("failed to create schema %s: %w", schema, err)
+	}
+
+	return migrateSchema(ctx, migrator, schema)
+}
+
+// MigrateSchema aplica las migraciones pendientes al schema del tenant, con su
+// propia tabla schema_migrations
+func (r *TenantRouter) MigrateSchema(ctx context.Context, tenantID string) error {
+	migrator, err := r.migrator(tenantID)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	return migrateSchema(ctx, migrator, TenantSchema(tenantID))
+}
+
+func migrateSchema(ctx context.Context, migrator *sql.DB, schema string) error {
+	conn, err := migrator.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to schema %s: %w", schema, err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{SchemaName: schema})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("could not create migration driver for schema %s: %w", schema, err)
+	}
+
+	m, err := migrate.NewWithDatabaseInstance(tenantMigrationsSource, "postgres", driver)
+	if err != nil {
+		_ = driver.Close()
+		return fmt.Errorf("could not create migrate instance for schema %s: %w", schema, err)
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return fmt.Errorf("could not run migrations on schema %s: %w", schema, err)
+	}
+
+	return nil
+}
+
+// DropSchema cierra el pool del tenant y borra su schema con todo lo que
+// tenga; se puede repetir sin efecto
+func (r *TenantRouter) DropSchema(ctx context.Context, tenantID string) error {
+	schema := TenantSchema(tenantID)
+	r.Release(tenantID)
+
+	migrator, err := r.migrator(tenantID)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if _, err := migrator.ExecContext(ctx, "DROP SCHEMA IF EXISTS "+pq.QuoteIdentifier(schema)+" CASCADE"); err != nil {
+		return fmt.Errorf("failed to drop schema %s: %w", schema, err)
+	}
+	return nil
+}
+
+// Release cierra el pool del tenant si hay uno abierto, ej. al darlo de baja
+func (r *TenantRouter) Release(tenantID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if pool, ok := r.pools[tenantID]; ok {
+		_ = pool.Close()
+		delete(r.pools, tenantID)
+	}
+}
+
+func (r *TenantRouter) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for tenantID, pool := range r.pools {
+		_ = pool.Close()
+		delete(r.pools, tenantID)
+	}
+	return nil
+}
+
+// los pools se abren la primera vez que se usan y quedan abiertos hasta
+// Release
+func (r *TenantRouter) pool(tenantID string) (*sql.DB, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if pool, ok := r.pools[tenantID]; ok {
+		return pool, nil
+	}
+
+	pool, err := r.open(schemaDSN(r.cfg, tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	pool.SetMaxOpenConns(r.poolSize)
+	pool.SetMaxIdleConns(r.poolSize)
+	pool.SetConnMaxLifetime(time.Hour)
+	// un tenant que deja de usarse no retiene conexiones
+	pool.SetConnMaxIdleTime(10 * time.Minute)
+
+	r.pools[tenantID] = pool
+	return pool, nil
+}
+
+// el DDL de los schemas dedicados corre con el rol de migraciones, en una
+// conexion que se cierra al terminar; el rol de la app no puede crear objetos
+func (r *TenantRouter) migrator(tenantID string) (*sql.DB, error) {
+	migrator, err := r.open(schemaDSN(r.cfg.ForMigrations(), tenantID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	migrator.SetMaxOpenConns(1)
+	return migrator, nil
+}
+
+func schemaDSN(cfg config.DatabaseConfig, tenantID string) string {
+	return fmt.Sprintf("%s search_path=%s,public", postgresDSN(cfg), TenantSchema(tenantID))
+}
+
+// routedDB es el pool del tenant del context, o db si no tiene uno dedicado
+func routedDB(ctx context.Context, db *sql.DB) *sql.DB {
+	if pool, ok := ctx.Value(routeKey{}).(*sql.DB); ok {
+		return pool
+	}
+	return db
+}
@@ -0,0 +1,259 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"backend-challenge-guinea/internal/shared/infrastructure/config"
+)
+
+type stubResolver struct {
+	dedicated map[string]bool
+	err       error
+}
+
+func (s stubResolver) HasDedicatedSchema(ctx context.Context, tenantID string) (bool, error) {
+	return s.dedicated[tenantID], s.err
+}
+
+var routerConfig = config.DatabaseConfig{
+	Host:               "db",
+	Port:               "5432",
+	Name:               "backend_db",
+	User:               "backend_user",
+	Password:           "backend_pass",
+	SSLMode:            "disable",
+	MigrationsUser:     "backend_admin",
+	MigrationsPassword: "backend_admin_pass",
+}
+
+// newRecordingRouter abre todos los pools sobre el driver que graba y anota
+// con que DSN se abrio cada uno
+func newRecordingRouter(resolver SchemaResolver) (*TenantRouter, *recorder, *[]string) {
+	rec := &recorder{}
+	dsns := []string{}
+
+	router := NewTenantRouter(routerConfig, sql.OpenDB(rec), resolver, 5)
+	router.open = func(dsn string) (*sql.DB, error) {
+		dsns = append(dsns, dsn)
+		return sql.OpenDB(rec), nil
+	}
+	return router, rec, &dsns
+}
+
+func TestTenantSchema(t *testing.T) {
+	assert.Equal(t, "tenant_acme_corp", TenantSchema("acme-corp"))
+
+	long := strings.Repeat("a", 70)
+	schema := TenantSchema(long)
+	assert.Len(t, schema, 63)
+	assert.True(t, strings.HasPrefix(schema, "tenant_aaaa"))
+	assert.Equal(t, schema, TenantSchema(long))
+
+	// dos ids que solo difieren al final no comparten schema
+	assert.NotEqual(t, TenantSchema(long+"-1"), TenantSchema(long+"-2"))
+}
+
+func TestTenantRouter_Scope_Shared(t *testing.T) {
+	router, _, dsns := newRecordingRouter(stubResolver{})
+	defer router.Close()
+
+	ctx, err := router.Scope(context.Background(), "acme", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme", TenantFromContext(ctx))
+	assert.Same(t, router.db, routedDB(ctx, router.db))
+	assert.Empty(t, *dsns)
+}
+
+func TestTenantRouter_Scope_DedicatedReusesPool(t *testing.T) {
+	router, _, dsns := newRecordingRouter(stubResolver{})
+	defer router.Close()
+
+	first, err := router.Scope(context.Background(), "acme", true)
+	require.NoError(t, err)
+	second, err := router.Scope(context.Background(), "acme", true)
+	require.NoError(t, err)
+	other, err := router.Scope(context.Background(), "globex", true)
+	require.NoError(t, err)
+
+	pool := routedDB(first, router.db)
+	assert.NotSame(t, router.db, pool)
+	assert.Same(t, pool, routedDB(second, router.db))
+	assert.NotSame(t, pool, routedDB(other, router.db))
+
+	// el pool usa el rol de la app con su schema primero
+	require.Len(t, *dsns, 2)
+	assert.Contains(t, (*dsns)[0], "user=backend_user ")
+	assert.True(t, strings.HasSuffix((*dsns)[0], " search_path=tenant_acme,public"))
+
+	// despues de Release se abre uno nuevo
+	router.Release("acme")
+	_, err = router.Scope(context.Background(), "acme", true)
+	require.NoError(t, err)
+	assert.Len(t, *dsns, 3)
+}
+
+func TestTenantRouter_Route(t *testing.T) {
+	router, _, _ := newRecordingRouter(stubResolver{dedicated: map[string]bool{"umbrella": true}})
+	defer router.Close()
+
+	shared, err := router.Route(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Same(t, router.db, routedDB(shared, router.db))
+
+	dedicated, err := router.Route(context.Background(), "umbrella")
+	require.NoError(t, err)
+	assert.NotSame(t, router.db, routedDB(dedicated, router.db))
+	assert.Equal(t, "umbrella", TenantFromContext(dedicated))
+}
+
+func TestTenantRouter_Route_ResolverError(t *testing.T) {
+	failure := errors.New("database down")
+	router, _, _ := newRecordingRouter(stubResolver{err: failure})
+	defer router.Close()
+
+	ctx, err := router.Route(context.Background(), "acme")
+
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, "acme", TenantFromContext(ctx))
+	assert.Same(t, router.db, routedDB(ctx, router.db))
+}
+
+func TestTenantRouter_DropSchema(t *testing.T) {
+	router, rec, dsns := newRecordingRouter(stubResolver{})
+	defer router.Close()
+
+	_, err := router.Scope(context.Background(), "acme", true)
+	require.NoError(t, err)
+
+	require.NoError(t, router.DropSchema(context.Background(), "acme"))
+
+	// el DDL corre con el rol de migraciones y el pool de la app se cierra
+	assert.Equal(t, []string{`DROP SCHEMA IF EXISTS "tenant_acme" CASCADE`}, rec.Statements())
+	require.Len(t, *dsns, 2)
+	assert.Contains(t, (*dsns)[1], "user=backend_admin ")
+	assert.Empty(t, router.pools)
+}
+
+// Contra un Postgres real (TEST_MIGRATIONS_DATABASE_URL) ya migrado, con el rol
+// de migraciones: el schema dedicado se crea, se migra dos veces sin cambios y
+// sus tablas tienen las mismas columnas que las de public. Si alguien agrega
+// una migracion a migrations/ y no a migrations/tenant, falla aca
+func TestTenantRouter_ProvisionSchema_MatchesPublic(t *testing.T) {
+	raw := os.Getenv("TEST_MIGRATIONS_DATABASE_URL")
+	if raw == "" {
+		t.Skip("TEST_MIGRATIONS_DATABASE_URL not set")
+	}
+
+	cfg := configFromURL(t, raw)
+	db, err := NewPostgresConnection(cfg)
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	var migrated bool
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT to_regclass('public.users_write') IS NOT NULL`).Scan(&migrated))
+	if !migrated {
+		t.Skip("database has no migrations applied")
+	}
+
+	// las migraciones se leen de migrations/tenant, relativo a la raiz
+	t.Chdir("../../../..")
+
+	const tenantID = "schema-drift-check"
+	router := NewTenantRouter(cfg, db, stubResolver{}, 1)
+	defer router.Close()
+	defer router.DropSchema(ctx, tenantID)
+
+	require.NoError(t, router.ProvisionSchema(ctx, tenantID))
+	require.NoError(t, router.MigrateSchema(ctx, tenantID))
+
+	schema := TenantSchema(tenantID)
+	tenantColumns := schemaColumns(t, db, schema, nil)
+	require.NotEmpty(t, tenantColumns)
+
+	tables := make([]string, 0, len(tenantColumns))
+	for table := range tenantColumns {
+		tables = append(tables, table)
+	}
+	assert.Equal(t, tenantColumns, schemaColumns(t, db, "public", tables))
+
+	// toda tabla de public con tenant_id esta en el schema, salvo las que
+	// quedan compartidas a proposito (ADR 004)
+	shared := map[string]bool{"outbox": true, "notifications": true, "notification_templates": true, "notification_settings": true}
+	for table, columns := range schemaColumns(t, db, "public", nil) {
+		if shared[table] || !hasColumn(columns, "tenant_id") {
+			continue
+		}
+		assert.Contains(t, tenantColumns, table, "table %s is missing from migrations/tenant", table)
+	}
+}
+
+func hasColumn(columns []string, name string) bool {
+	for _, column := range columns {
+		if strings.HasPrefix(column, name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaColumns devuelve "columna tipo nullable" por tabla, sin la tabla de
+// control de golang-migrate
+func schemaColumns(t *testing.T, db *sql.DB, schema string, tables []string) map[string][]string {
+	query := `
+		SELECT table_name, column_name || ' ' || data_type || ' ' || is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name <> 'schema_migrations'
+			AND ($2::text[] IS NULL OR table_name = ANY($2))
+		ORDER BY table_name, column_name
+	`
+
+	var filter interface{}
+	if tables != nil {
+		filter = pq.Array(tables)
+	}
+
+	rows, err := db.QueryContext(context.Background(), query, schema, filter)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	columns := map[string][]string{}
+	for rows.Next() {
+		var table, column string
+		require.NoError(t, rows.Scan(&table, &column))
+		columns[table] = append(columns[table], column)
+	}
+	require.NoError(t, rows.Err())
+	return columns
+}
+
+func configFromURL(t *testing.T, raw string) config.DatabaseConfig {
+	parsed, err := url.Parse(raw)
+	require.NoError(t, err)
+
+	password, _ := parsed.User.Password()
+	sslMode := parsed.Query().Get("sslmode")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	return config.DatabaseConfig{
+		Host:     parsed.Hostname(),
+		Port:     parsed.Port(),
+		Name:     strings.TrimPrefix(parsed.Path, "/"),
+		User:     parsed.User.Username(),
+		Password: password,
+		SSLMode:  sslMode,
+	}
+}
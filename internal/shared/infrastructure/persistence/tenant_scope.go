@@ -0,0 +1,87 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type tenantKey struct{}
+type allTenantsKey struct{}
+
+// WithTenant deja en el context el tenant de la request o del evento. Las
+// transacciones abiertas con ese context lo pasan a Postgres (app.tenant_id)
+// y las politicas RLS solo muestran filas de ese tenant
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// WithAllTenants es para los procesos que trabajan sobre todos los tenants
+// (ej. la purga); saltea las politicas RLS, usarlo solo ahi
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsKey{}, true)
+}
+
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
+
+// WithinTenantScope corre fn sobre una transaccion con el tenant del context
+// aplicado: reusa la del context o abre una solo para fn. Los repositorios de
+// tablas con RLS lo usan en vez de GetExecutor, porque fuera de una
+// transaccion no hay donde setear el tenant
+func WithinTenantScope(ctx context.Context, db *sql.DB, fn func(executor Executor) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(tx)
+	}
+
+	tx, err := routedDB(ctx, db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := applyTenantScope(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// applyTenantScope setea las variables que leen las politicas RLS, locales a
+// la transaccion para que no queden en la conexion del pool. Sin tenant en el
+// context no se setea nada y las tablas con RLS no devuelven filas
+func applyTenantScope(ctx context.Context, tx *sql.Tx) error {
+	if all, _ := ctx.Value(allTenantsKey{}).(bool); all {
+		if _, err := tx.ExecContext(ctx, `SELECT set_config('app.all_tenants', 'on', true)`); err != nil {
+			return fmt.Errorf("failed to set tenant scope: %w", err)
+		}
+		return nil
+	}
+
+	if tenantID := TenantFromContext(ctx); tenantID != "" {
+		if _, err := tx.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, true)`, tenantID); err != nil {
+			return fmt.Errorf("failed to set tenant scope: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RowLevelSecurityBypassed dice si el rol con el que se conecta la app saltea
+// RLS (superusuario o BYPASSRLS); en ese caso las politicas no protegen nada
+func RowLevelSecurityBypassed(ctx context.Context, db *sql.DB) (bool, error) {
+	var bypassed bool
+	err := db.QueryRowContext(ctx, `SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user`).Scan(&bypassed)
+	return bypassed, err
+}
@@ -0,0 +1,153 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	setTenant     = "SELECT set_config('app.tenant_id', $1, true)"
+	setAllTenants = "SELECT set_config('app.all_tenants', 'on', true)"
+)
+
+func TestWithinTenantScope_SetsTenantInEachTransaction(t *testing.T) {
+	db, rec := newRecordingDB()
+	defer db.Close()
+
+	for _, tenantID := range []string{"acme", "globex"} {
+		err := WithinTenantScope(WithTenant(context.Background(), tenantID), db, func(executor Executor) error {
+			_, err := executor.ExecContext(context.Background(), "DELETE FROM idempotency_keys")
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{
+		"BEGIN", setTenant + " [acme]", "DELETE FROM idempotency_keys", "COMMIT",
+		"BEGIN", setTenant + " [globex]", "DELETE FROM idempotency_keys", "COMMIT",
+	}, rec.Statements())
+}
+
+func TestWithinTenantScope_ReusesContextTransaction(t *testing.T) {
+	db, rec := newRecordingDB()
+	defer db.Close()
+
+	ctx := WithTenant(context.Background(), "acme")
+	err := NewTxManager(db).WithinTransaction(ctx, func(txCtx context.Context) error {
+		for i := 0; i < 2; i++ {
+			if err := WithinTenantScope(txCtx, db, func(executor Executor) error {
+				_, err := executor.ExecContext(txCtx, "DELETE FROM idempotency_keys")
+				return err
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"BEGIN", setTenant + " [acme]", "DELETE FROM idempotency_keys", "DELETE FROM idempotency_keys", "COMMIT",
+	}, rec.Statements())
+}
+
+func TestWithinTenantScope_WithoutTenantSetsNothing(t *testing.T) {
+	db, rec := newRecordingDB()
+	defer db.Close()
+
+	// sin app.tenant_id las politicas comparan contra NULL y no devuelven filas
+	err := WithinTenantScope(context.Background(), db, func(executor Executor) error {
+		_, err := executor.ExecContext(context.Background(), "DELETE FROM idempotency_keys")
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"BEGIN", "DELETE FROM idempotency_keys", "COMMIT"}, rec.Statements())
+}
+
+func TestWithinTenantScope_AllTenants(t *testing.T) {
+	db, rec := newRecordingDB()
+	defer db.Close()
+
+	ctx := WithAllTenants(WithTenant(context.Background(), "acme"))
+	err := WithinTenantScope(ctx, db, func(executor Executor) error { return nil })
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"BEGIN", setAllTenants, "COMMIT"}, rec.Statements())
+}
+
+func TestWithinTenantScope_RollsBackOnError(t *testing.T) {
+	db, rec := newRecordingDB()
+	defer db.Close()
+
+	failure := errors.New("boom")
+	err := WithinTenantScope(WithTenant(context.Background(), "acme"), db, func(executor Executor) error {
+		return failure
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"BEGIN", setTenant + " [acme]", "ROLLBACK"}, rec.Statements())
+}
+
+// Contra un Postgres real (TEST_DATABASE_URL) con un rol que no saltea RLS:
+// sin tenant no se ve ninguna fila y con tenant solo las propias
+func TestWithinTenantScope_RowLevelSecurity(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// la tabla temporal vive en la conexion
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	bypassed, err := RowLevelSecurityBypassed(ctx, db)
+	require.NoError(t, err)
+	if bypassed {
+		t.Skip("database role bypasses row level security")
+	}
+
+	for _, statement := range []string{
+		`CREATE TEMP TABLE scoped_rows (tenant_id TEXT NOT NULL)`,
+		`ALTER TABLE scoped_rows ENABLE ROW LEVEL SECURITY`,
+		`ALTER TABLE scoped_rows FORCE ROW LEVEL SECURITY`,
+		`CREATE POLICY tenant_isolation ON scoped_rows
+			USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+			WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))`,
+	} {
+		_, err := db.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	for _, tenantID := range []string{"acme", "globex"} {
+		err := WithinTenantScope(WithTenant(ctx, tenantID), db, func(executor Executor) error {
+			_, err := executor.ExecContext(ctx, `INSERT INTO scoped_rows (tenant_id) VALUES ($1)`, tenantID)
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	count := func(ctx context.Context) int {
+		var n int
+		err := WithinTenantScope(ctx, db, func(executor Executor) error {
+			return executor.QueryRowContext(ctx, `SELECT COUNT(*) FROM scoped_rows`).Scan(&n)
+		})
+		require.NoError(t, err)
+		return n
+	}
+
+	assert.Equal(t, 0, count(ctx))
+	assert.Equal(t, 1, count(WithTenant(ctx, "acme")))
+	assert.Equal(t, 2, count(WithAllTenants(ctx)))
+}
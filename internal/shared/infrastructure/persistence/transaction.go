@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Executor es lo minimo que necesitan los repositorios para correr queries.
+// Lo implementan tanto *sql.DB como *sql.Tx
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+// TxManager abre transacciones y las deja en el context para que los
+// repositorios las tomen sin cambiar sus firmas
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction ejecuta fn dentro de una transaccion. Si fn devuelve error
+// se hace rollback, si no commit. Si ya hay una transaccion en el context se
+// reutiliza. La transaccion se abre en el pool del tenant (TenantRouter) y su
+// tenant (WithTenant) queda aplicado para RLS
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := routedDB(ctx, m.db).BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := applyTenantScope(ctx, tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetExecutor devuelve la transaccion del context si existe, si no la conexion
+// del tenant
+func GetExecutor(ctx context.Context, db *sql.DB) Executor {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return routedDB(ctx, db)
+}
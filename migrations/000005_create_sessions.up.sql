@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+
+    CONSTRAINT unique_session_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id, tenant_id);
+CREATE INDEX idx_sessions_expires_at ON sessions(expires_at);
@@ -0,0 +1,6 @@
+ALTER TABLE sessions
+    ADD COLUMN IF NOT EXISTS user_agent VARCHAR(512),
+    ADD COLUMN IF NOT EXISTS ip_address VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP;
+
+CREATE INDEX idx_sessions_active ON sessions(user_id, tenant_id, expires_at) WHERE revoked_at IS NULL;
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    replaced_by UUID,
+    revoked_at TIMESTAMP,
+
+    CONSTRAINT unique_refresh_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens(session_id, tenant_id);
+CREATE INDEX idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS login_attempts (
+    tenant_id VARCHAR(100) NOT NULL,
+    subject VARCHAR(320) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, subject)
+);
+
+CREATE INDEX idx_login_attempts_last_failure ON login_attempts(last_failure_at);
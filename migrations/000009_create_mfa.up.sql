@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS mfa_enrollments (
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    recovery_code_hashes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    enrollment_required BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+
+    CONSTRAINT unique_mfa_challenge_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
@@ -0,0 +1 @@
+ALTER TABLE users_read DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,2 @@
+-- para descartar eventos user.updated que llegan fuera de orden
+ALTER TABLE users_read ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP;
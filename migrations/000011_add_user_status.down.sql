@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_write_deleted_at;
+
+ALTER TABLE users_read DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users_read DROP COLUMN IF EXISTS status;
+
+ALTER TABLE users_write DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users_write DROP COLUMN IF EXISTS status;
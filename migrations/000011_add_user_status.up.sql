@@ -0,0 +1,8 @@
+ALTER TABLE users_write ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users_write ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE users_read ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users_read ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- para el job de purga
+CREATE INDEX IF NOT EXISTS idx_users_write_deleted_at ON users_write(deleted_at) WHERE deleted_at IS NOT NULL;
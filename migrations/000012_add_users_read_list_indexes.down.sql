@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_read_tenant_lower_name_prefix;
+DROP INDEX IF EXISTS idx_users_read_tenant_email_prefix;
+DROP INDEX IF EXISTS idx_users_read_tenant_name_id;
+DROP INDEX IF EXISTS idx_users_read_tenant_created_id;
@@ -0,0 +1,5 @@
+-- indices para el listado paginado (keyset sobre sort + id) y filtros por prefijo
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_created_id ON users_read(tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_name_id ON users_read(tenant_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_email_prefix ON users_read(tenant_id, email varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_lower_name_prefix ON users_read(tenant_id, lower(name) text_pattern_ops);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_users_read_email_trgm;
+DROP INDEX IF EXISTS idx_users_read_display_name_trgm;
+DROP INDEX IF EXISTS idx_users_read_name_trgm;
+DROP INDEX IF EXISTS idx_users_read_search_vector;
+
+ALTER TABLE users_read DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- diccionario simple: nombres y emails no se stemmean
+ALTER TABLE users_read ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(display_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(email, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_users_read_search_vector ON users_read USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_users_read_name_trgm ON users_read USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_display_name_trgm ON users_read USING GIN (display_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_email_trgm ON users_read USING GIN (email gin_trgm_ops);
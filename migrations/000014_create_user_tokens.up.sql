@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    new_email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+
+    CONSTRAINT unique_user_token UNIQUE (token_hash)
+);
+
+CREATE INDEX idx_user_tokens_user ON user_tokens(tenant_id, user_id, purpose);
+CREATE INDEX idx_user_tokens_expires_at ON user_tokens(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE users_read DROP COLUMN IF EXISTS email_verified_at;
+ALTER TABLE users_write DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,7 @@
+ALTER TABLE users_write ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+ALTER TABLE users_read ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+-- las cuentas anteriores a la verificacion se consideran verificadas, sino
+-- quedarian bloqueadas al activar el flag en su tenant
+UPDATE users_write SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+UPDATE users_read SET email_verified_at = created_at WHERE email_verified_at IS NULL;
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    event_id VARCHAR(100) NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    user_id UUID,
+    kind VARCHAR(50) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL DEFAULT '',
+    html_body TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT unique_notification_event UNIQUE (event_id, kind)
+);
+
+CREATE INDEX idx_notifications_due ON notifications(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notifications_user ON notifications(tenant_id, user_id);
+
+CREATE TABLE IF NOT EXISTS notification_templates (
+    tenant_id VARCHAR(100) NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, kind, locale)
+);
+
+CREATE TABLE IF NOT EXISTS notification_settings (
+    tenant_id VARCHAR(100) PRIMARY KEY,
+    locale VARCHAR(10),
+    from_address VARCHAR(255),
+    from_name VARCHAR(255),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
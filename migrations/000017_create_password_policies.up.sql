@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS password_policies (
+    tenant_id VARCHAR(100) PRIMARY KEY,
+    min_length INT NOT NULL,
+    max_length INT NOT NULL DEFAULT 0,
+    require_character_classes BOOLEAN NOT NULL DEFAULT TRUE,
+    deny_personal_info BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- max_length 0 = sin maximo
+    CONSTRAINT password_policies_min_length CHECK (min_length >= 1),
+    CONSTRAINT password_policies_max_length CHECK (max_length = 0 OR max_length >= min_length)
+);
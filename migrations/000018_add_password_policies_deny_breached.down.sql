@@ -0,0 +1 @@
+ALTER TABLE password_policies DROP COLUMN IF EXISTS deny_breached;
@@ -0,0 +1 @@
+ALTER TABLE password_policies ADD COLUMN IF NOT EXISTS deny_breached BOOLEAN NOT NULL DEFAULT TRUE;
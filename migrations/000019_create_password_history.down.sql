@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS password_history;
+ALTER TABLE password_policies DROP CONSTRAINT IF EXISTS password_policies_history_size;
+ALTER TABLE password_policies DROP COLUMN IF EXISTS history_size;
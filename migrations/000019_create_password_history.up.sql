@@ -0,0 +1,14 @@
+ALTER TABLE password_policies ADD COLUMN IF NOT EXISTS history_size INT NOT NULL DEFAULT 0;
+ALTER TABLE password_policies DROP CONSTRAINT IF EXISTS password_policies_history_size;
+ALTER TABLE password_policies ADD CONSTRAINT password_policies_history_size CHECK (history_size >= 0);
+
+-- passwords anteriores de cada usuario (no la actual); se purgan con el usuario
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users_write(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_history_user ON password_history(tenant_id, user_id, created_at DESC);
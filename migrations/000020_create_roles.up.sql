@@ -0,0 +1,31 @@
+-- roles custom por tenant; admin, member y read_only estan definidos en codigo
+CREATE TABLE IF NOT EXISTS roles (
+    tenant_id VARCHAR(100) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, name)
+);
+
+-- un rol por usuario; sin fila el usuario es member
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID PRIMARY KEY REFERENCES users_write(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    assigned_by VARCHAR(100) NOT NULL DEFAULT '',
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_roles_tenant_role ON user_roles(tenant_id, role);
+
+-- antes no habia autorizacion: para que ningun tenant quede sin quien asigne
+-- roles, el usuario activo mas antiguo de cada uno queda como admin y el
+-- resto como member (sin fila)
+INSERT INTO user_roles (user_id, tenant_id, role, assigned_by)
+SELECT DISTINCT ON (tenant_id) id, tenant_id, 'admin', 'migration'
+FROM users_write
+WHERE status = 'active'
+ORDER BY tenant_id, created_at, id
+ON CONFLICT (user_id) DO NOTHING;
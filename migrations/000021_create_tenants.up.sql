@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended')),
+    plan VARCHAR(20) NOT NULL DEFAULT 'free',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- hasta ahora los tenants se creaban solos con el primer X-Tenant-Id: se
+-- registran los que ya tienen usuarios para no dejarlos afuera
+INSERT INTO tenants (id, name)
+SELECT DISTINCT tenant_id, tenant_id FROM users_write
+ON CONFLICT (id) DO NOTHING;
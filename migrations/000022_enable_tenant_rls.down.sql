@@ -0,0 +1,11 @@
+DROP POLICY IF EXISTS tenant_isolation ON idempotency_keys;
+ALTER TABLE idempotency_keys NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON users_read;
+ALTER TABLE users_read NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users_read DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON users_write;
+ALTER TABLE users_write NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users_write DISABLE ROW LEVEL SECURITY;
@@ -0,0 +1,22 @@
+-- aislamiento por tenant en la base: aunque una query se olvide el filtro por
+-- tenant_id solo ve filas del tenant seteado en la transaccion (app.tenant_id).
+-- app.all_tenants = 'on' es para los procesos que recorren todos los tenants.
+-- FORCE hace que aplique tambien al dueño de las tablas; los superusuarios y
+-- los roles con BYPASSRLS las saltean igual
+ALTER TABLE users_write ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users_write FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users_write
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE users_read ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users_read FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users_read
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
@@ -0,0 +1,2 @@
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_isolation;
+ALTER TABLE tenants DROP COLUMN IF EXISTS isolation;
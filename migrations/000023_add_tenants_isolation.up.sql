@@ -0,0 +1,4 @@
+-- shared: datos en las tablas compartidas de public, filtrados por tenant_id.
+-- dedicated: datos en el schema propio del tenant (migrations/tenant)
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS isolation VARCHAR(20) NOT NULL DEFAULT 'shared';
+ALTER TABLE tenants ADD CONSTRAINT tenants_isolation CHECK (isolation IN ('shared', 'dedicated'));
@@ -0,0 +1,3 @@
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended'));
+ALTER TABLE tenants DROP COLUMN IF EXISTS settings;
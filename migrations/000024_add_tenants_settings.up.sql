@@ -0,0 +1,6 @@
+-- feature flags y rate limit del tenant, fijados al provisionarlo
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS settings JSONB NOT NULL DEFAULT '{}';
+
+-- offboarded: datos exportados y borrados, el registro queda para no reusar el id
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_status_check;
+ALTER TABLE tenants ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended', 'offboarded'));
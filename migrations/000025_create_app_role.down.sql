@@ -0,0 +1,8 @@
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE USAGE, SELECT ON SEQUENCES FROM backend_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM backend_app;
+
+REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public FROM backend_app;
+REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public FROM backend_app;
+REVOKE USAGE ON SCHEMA public FROM backend_app;
+
+-- el rol puede tener miembros o permisos en los schemas dedicados, se deja
@@ -0,0 +1,18 @@
+-- rol de la app: solo DML, sin SUPERUSER ni BYPASSRLS, para que las politicas
+-- RLS apliquen. Las migraciones corren con el dueño de las tablas; el usuario
+-- con el que se conecta la app (DB_USER) tiene que ser miembro de este rol
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'backend_app') THEN
+        CREATE ROLE backend_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+    END IF;
+END
+$$;
+
+GRANT USAGE ON SCHEMA public TO backend_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO backend_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO backend_app;
+
+-- las tablas de migraciones futuras
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO backend_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO backend_app;
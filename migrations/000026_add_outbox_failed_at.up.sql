@@ -0,0 +1,8 @@
+-- un evento que el broker rechaza OUTBOX_MAX_ATTEMPTS veces queda apartado
+-- con failed_at y el relay lo saltea. Para reintentarlo:
+-- UPDATE outbox SET failed_at = NULL, attempts = 0 WHERE id = ...
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP;
+
+DROP INDEX IF EXISTS idx_outbox_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(created_at) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_failed ON outbox(failed_at) WHERE failed_at IS NOT NULL;
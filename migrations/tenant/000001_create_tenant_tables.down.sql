@@ -0,0 +1,18 @@
+-- con el schema del tenant calificado: sin eso, una tabla que ya no esta en el
+-- schema se resolveria a la de public por el search_path
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    IF current_schema() NOT LIKE 'tenant\_%' THEN
+        RAISE EXCEPTION 'tenant migrations must run on a tenant schema, not %', current_schema();
+    END IF;
+
+    FOREACH t IN ARRAY ARRAY[
+        'user_roles', 'roles', 'password_history', 'password_policies', 'user_tokens',
+        'mfa_challenges', 'mfa_enrollments', 'login_attempts', 'refresh_tokens',
+        'sessions', 'idempotency_keys', 'users_read', 'users_write'
+    ] LOOP
+        EXECUTE format('DROP TABLE IF EXISTS %I.%I', current_schema(), t);
+    END LOOP;
+END $$;
@@ -0,0 +1,225 @@
+-- tablas de datos de un tenant con schema dedicado. Se corren con search_path
+-- en su schema; outbox, tenants y notificaciones siguen compartidas en public.
+-- Es el estado de las tablas de migrations/ al crear el modo dedicado: un
+-- cambio a una de estas tablas va en las dos carpetas
+CREATE TABLE IF NOT EXISTS users_write (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    password_hash TEXT NOT NULL,
+    display_name VARCHAR(255),
+    tenant_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    email_verified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+
+    CONSTRAINT unique_email_per_tenant UNIQUE (email, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_write_tenant ON users_write(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_write_email ON users_write(email);
+CREATE INDEX IF NOT EXISTS idx_users_write_deleted_at ON users_write(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS users_read (
+    id UUID NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    display_name VARCHAR(255),
+    tenant_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    email_verified_at TIMESTAMP,
+    created_at VARCHAR(50) NOT NULL,
+    updated_at TIMESTAMP,
+    deleted_at TIMESTAMP,
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(display_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(email, '')), 'B')
+    ) STORED,
+
+    PRIMARY KEY (id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant ON users_read(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_users_read_email ON users_read(email);
+CREATE INDEX IF NOT EXISTS idx_users_read_created_at ON users_read(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_created_id ON users_read(tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_name_id ON users_read(tenant_id, name, id);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_email_prefix ON users_read(tenant_id, email varchar_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_tenant_lower_name_prefix ON users_read(tenant_id, lower(name) text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_search_vector ON users_read USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_users_read_name_trgm ON users_read USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_display_name_trgm ON users_read USING GIN (display_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_read_email_trgm ON users_read USING GIN (email gin_trgm_ops);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    result TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (key, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_tenant ON idempotency_keys(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_idempotency_created_at ON idempotency_keys(created_at);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    user_agent VARCHAR(512),
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+
+    CONSTRAINT unique_session_token UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions(user_id, tenant_id);
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_active ON sessions(user_id, tenant_id, expires_at) WHERE revoked_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    replaced_by UUID,
+    revoked_at TIMESTAMP,
+
+    CONSTRAINT unique_refresh_token UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session ON refresh_tokens(session_id, tenant_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS login_attempts (
+    tenant_id VARCHAR(100) NOT NULL,
+    subject VARCHAR(320) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP,
+
+    PRIMARY KEY (tenant_id, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_last_failure ON login_attempts(last_failure_at);
+
+CREATE TABLE IF NOT EXISTS mfa_enrollments (
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    recovery_code_hashes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS mfa_challenges (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    enrollment_required BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+
+    CONSTRAINT unique_mfa_challenge_token UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_challenges_expires_at ON mfa_challenges(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    new_email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+
+    CONSTRAINT unique_user_token UNIQUE (token_hash)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user ON user_tokens(tenant_id, user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_user_tokens_expires_at ON user_tokens(expires_at);
+
+CREATE TABLE IF NOT EXISTS password_policies (
+    tenant_id VARCHAR(100) PRIMARY KEY,
+    min_length INT NOT NULL,
+    max_length INT NOT NULL DEFAULT 0,
+    require_character_classes BOOLEAN NOT NULL DEFAULT TRUE,
+    deny_personal_info BOOLEAN NOT NULL DEFAULT FALSE,
+    deny_breached BOOLEAN NOT NULL DEFAULT TRUE,
+    history_size INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT password_policies_min_length CHECK (min_length >= 1),
+    CONSTRAINT password_policies_max_length CHECK (max_length = 0 OR max_length >= min_length),
+    CONSTRAINT password_policies_history_size CHECK (history_size >= 0)
+);
+
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users_write(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_history_user ON password_history(tenant_id, user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS roles (
+    tenant_id VARCHAR(100) NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID PRIMARY KEY REFERENCES users_write(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    assigned_by VARCHAR(100) NOT NULL DEFAULT '',
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_tenant_role ON user_roles(tenant_id, role);
+
+-- mismas politicas que en public: el schema ya separa al tenant, pero los
+-- repositorios siguen seteando app.tenant_id y asi un schema no tiene reglas distintas
+ALTER TABLE users_write ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users_write FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users_write
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE users_read ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users_read FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users_read
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE idempotency_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE idempotency_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON idempotency_keys
+    USING (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true))
+    WITH CHECK (current_setting('app.all_tenants', true) = 'on' OR tenant_id = current_setting('app.tenant_id', true));
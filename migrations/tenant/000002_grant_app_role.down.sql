@@ -0,0 +1,9 @@
+DO $$
+BEGIN
+    EXECUTE format('ALTER DEFAULT PRIVILEGES IN SCHEMA %I REVOKE USAGE, SELECT ON SEQUENCES FROM backend_app', current_schema());
+    EXECUTE format('ALTER DEFAULT PRIVILEGES IN SCHEMA %I REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM backend_app', current_schema());
+    EXECUTE format('REVOKE USAGE, SELECT ON ALL SEQUENCES IN SCHEMA %I FROM backend_app', current_schema());
+    EXECUTE format('REVOKE SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %I FROM backend_app', current_schema());
+    EXECUTE format('REVOKE USAGE ON SCHEMA %I FROM backend_app', current_schema());
+END
+$$;
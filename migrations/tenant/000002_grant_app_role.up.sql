@@ -0,0 +1,11 @@
+-- el rol de la app (migrations/000025) solo tiene DML sobre el schema del
+-- tenant; current_schema() es el schema que se esta migrando
+DO $$
+BEGIN
+    EXECUTE format('GRANT USAGE ON SCHEMA %I TO backend_app', current_schema());
+    EXECUTE format('GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA %I TO backend_app', current_schema());
+    EXECUTE format('GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA %I TO backend_app', current_schema());
+    EXECUTE format('ALTER DEFAULT PRIVILEGES IN SCHEMA %I GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO backend_app', current_schema());
+    EXECUTE format('ALTER DEFAULT PRIVILEGES IN SCHEMA %I GRANT USAGE, SELECT ON SEQUENCES TO backend_app', current_schema());
+END
+$$;
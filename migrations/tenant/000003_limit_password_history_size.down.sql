@@ -0,0 +1,2 @@
+ALTER TABLE password_policies DROP CONSTRAINT IF EXISTS password_policies_history_size;
+ALTER TABLE password_policies ADD CONSTRAINT password_policies_history_size CHECK (history_size >= 0);
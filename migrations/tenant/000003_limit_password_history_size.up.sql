@@ -0,0 +1,6 @@
+-- cada password del historial es una comparacion argon2id/bcrypt por cambio,
+-- asi que history_size tiene tope (vo.MaxPasswordHistorySize)
+UPDATE password_policies SET history_size = 24 WHERE history_size > 24;
+
+ALTER TABLE password_policies DROP CONSTRAINT IF EXISTS password_policies_history_size;
+ALTER TABLE password_policies ADD CONSTRAINT password_policies_history_size CHECK (history_size BETWEEN 0 AND 24);
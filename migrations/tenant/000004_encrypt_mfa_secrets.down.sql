@@ -0,0 +1,2 @@
+-- los secretos ya cifrados no entran en 64: hay que borrar los enrolamientos antes de bajar
+ALTER TABLE mfa_enrollments ALTER COLUMN secret TYPE VARCHAR(64);
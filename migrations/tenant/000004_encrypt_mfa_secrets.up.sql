@@ -0,0 +1,2 @@
+-- el secreto TOTP se guarda cifrado (v1:<nonce+cifrado en base64>), no entra en 64
+ALTER TABLE mfa_enrollments ALTER COLUMN secret TYPE TEXT;